@@ -1,6 +1,6 @@
 import {useEffect, useMemo, useRef, useState} from "react"
 import "./App.css"
-import {loginUser} from "./api/auth"
+import {getMe, loginUser, logoutUser} from "./api/auth"
 import {
     createBook,
     searchBooksInternal,
@@ -30,7 +30,7 @@ import {
 } from "./api/reference"
 import {createRole, getPermissions, getRoles} from "./api/roles"
 import {API_URL, ApiError, setToken} from "./api/http"
-import {createUser, deleteUser, getUserByID, getUsers, updateUser} from "./api/users"
+import {createUser, deleteUser, getUsers, updateUser} from "./api/users"
 import {SafeImage} from "./components/SafeImage"
 import {uploadImage} from "./api/images"
 import type {
@@ -149,21 +149,6 @@ const emptyLocationDraft: LocationDraft = {
     lockType: false,
 }
 
-function parseJwt(token: string) {
-    try {
-        const base64 = token.split(".")[1]
-        const normalized = base64.replace(/-/g, "+").replace(/_/g, "/")
-        const padded = normalized.padEnd(
-            normalized.length + ((4 - (normalized.length % 4)) % 4),
-            "="
-        )
-        const json = atob(padded)
-        return JSON.parse(json) as {sub?: string}
-    } catch {
-        return null
-    }
-}
-
 function getAuthorName(author: AuthorSummary) {
     const last = author.last_name?.trim()
     const first = author.first_name?.trim()
@@ -332,9 +317,6 @@ export default function App() {
     const [token, setAuthToken] = useState<string | null>(
         localStorage.getItem("auth_token")
     )
-    const [loginName, setLoginName] = useState(
-        localStorage.getItem("login_name") ?? ""
-    )
     const [user, setUser] = useState<User | null>(null)
     const [isAdmin, setIsAdmin] = useState(false)
     const [authError, setAuthError] = useState<string | null>(null)
@@ -558,17 +540,9 @@ export default function App() {
             return
         }
 
-        const claims = parseJwt(token)
-        const userId = claims?.sub
-        if (!userId) {
-            setAuthToken(null)
-            setToken(null)
-            return
-        }
-
         ;(async () => {
             try {
-                const current = await getUserByID(userId)
+                const current = await getMe()
                 setUser(current)
                 setIsAdmin(current.roles.some((role) => role.code === "admin"))
                 setProfileDraft({
@@ -582,34 +556,15 @@ export default function App() {
                 })
             } catch (err) {
                 const apiErr = err as ApiError
-                if (apiErr.status === 403 || apiErr.status === 404) {
-                    const fallbackUser = {
-                        id: userId,
-                        login: loginName || "user",
-                        first_name: "",
-                        last_name: "",
-                        middle_name: "",
-                        email: "",
-                        is_active: true,
-                        roles: [],
-                    }
-                    setUser(fallbackUser)
-                    setIsAdmin(false)
-                    setProfileDraft({
-                        login: fallbackUser.login,
-                        first_name: "",
-                        last_name: "",
-                        middle_name: "",
-                        email: "",
-                        password: "",
-                        is_active: true,
-                    })
+                if (apiErr.status === 401) {
+                    setAuthToken(null)
+                    setToken(null)
                     return
                 }
                 setAuthError("Не удалось загрузить профиль")
             }
         })()
-    }, [token, loginName])
+    }, [token])
 
     useEffect(() => {
         if (!token) {
@@ -785,8 +740,6 @@ export default function App() {
             )
             setAuthToken(tokenValue)
             setToken(tokenValue)
-            localStorage.setItem("login_name", loginDraft.login.trim())
-            setLoginName(loginDraft.login.trim())
             setIsLoginOpen(false)
             setLoginDraft({login: "", password: ""})
         } catch {
@@ -798,12 +751,11 @@ export default function App() {
         if (!window.confirm("Вы действительно хотите выйти?")) {
             return
         }
+        void logoutUser().catch(() => undefined)
         setAuthToken(null)
         setToken(null)
         setUser(null)
         setIsAdmin(false)
-        localStorage.removeItem("login_name")
-        setLoginName("")
         setActiveTab("books")
         setBooks([])
     }
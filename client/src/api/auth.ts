@@ -1,7 +1,8 @@
-import {requestJson} from "./http"
+import type {User} from "../types/library"
+import {getRefreshToken, requestJson, setRefreshToken, setToken} from "./http"
 
 export async function loginUser(login: string, password: string) {
-    const data = await requestJson<{access_token: string}>(
+    const data = await requestJson<{access_token: string; refresh_token: string}>(
         "/auth/login",
         {
             method: "POST",
@@ -9,6 +10,54 @@ export async function loginUser(login: string, password: string) {
         },
         false
     )
+    setRefreshToken(data.refresh_token)
     return data.access_token
 }
 
+export async function logoutUser() {
+    const refreshToken = getRefreshToken()
+    setRefreshToken(null)
+    if (!refreshToken) {
+        return
+    }
+    await requestJson<void>(
+        "/auth/logout",
+        {
+            method: "POST",
+            body: JSON.stringify({refresh_token: refreshToken}),
+        },
+        false
+    )
+}
+
+export function getMe() {
+    return requestJson<User>("/auth/me")
+}
+
+export function updateMe(payload: {
+    first_name?: string
+    last_name?: string
+    middle_name?: string
+    email?: string
+}) {
+    return requestJson<User>("/auth/me", {
+        method: "PUT",
+        body: JSON.stringify(payload),
+    })
+}
+
+export async function changeMyPassword(currentPassword: string, newPassword: string) {
+    const data = await requestJson<{access_token: string; refresh_token: string}>(
+        "/auth/me/password",
+        {
+            method: "POST",
+            body: JSON.stringify({
+                current_password: currentPassword,
+                new_password: newPassword,
+            }),
+        }
+    )
+    setToken(data.access_token)
+    setRefreshToken(data.refresh_token)
+    return data.access_token
+}
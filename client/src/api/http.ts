@@ -22,6 +22,73 @@ export function setToken(token: string | null) {
     }
 }
 
+export function getRefreshToken() {
+    return localStorage.getItem("refresh_token")
+}
+
+export function setRefreshToken(token: string | null) {
+    if (token) {
+        localStorage.setItem("refresh_token", token)
+    } else {
+        localStorage.removeItem("refresh_token")
+    }
+}
+
+let refreshing: Promise<boolean> | null = null
+
+// refreshTokens exchanges the stored refresh token for a new token pair.
+// Concurrent callers share a single request because refresh tokens rotate.
+async function refreshTokens(): Promise<boolean> {
+    const refreshToken = getRefreshToken()
+    if (!refreshToken) {
+        return false
+    }
+    if (!refreshing) {
+        refreshing = (async () => {
+            try {
+                const res = await fetch(`${API_URL}/auth/refresh`, {
+                    method: "POST",
+                    headers: {"Content-Type": "application/json"},
+                    body: JSON.stringify({refresh_token: refreshToken}),
+                })
+                if (!res.ok) {
+                    setToken(null)
+                    setRefreshToken(null)
+                    return false
+                }
+                const data = (await res.json()) as {access_token: string; refresh_token: string}
+                setToken(data.access_token)
+                setRefreshToken(data.refresh_token)
+                return true
+            } catch {
+                return false
+            } finally {
+                refreshing = null
+            }
+        })()
+    }
+    return refreshing
+}
+
+async function fetchWithAuth(input: string, init: RequestInit, withAuth: boolean) {
+    const send = () => {
+        const headers = new Headers(init.headers)
+        if (withAuth) {
+            const token = getToken()
+            if (token) {
+                headers.set("Authorization", `Bearer ${token}`)
+            }
+        }
+        return fetch(input, {...init, headers})
+    }
+
+    const res = await send()
+    if (res.status === 401 && withAuth && (await refreshTokens())) {
+        return send()
+    }
+    return res
+}
+
 async function parseJsonSafe(res: Response) {
     const text = await res.text()
     if (!text) {
@@ -40,20 +107,18 @@ export async function requestJson<T>(
     withAuth = true
 ): Promise<T> {
     const headers = new Headers(options.headers)
-    if (withAuth) {
-        const token = getToken()
-        if (token) {
-            headers.set("Authorization", `Bearer ${token}`)
-        }
-    }
     if (options.body && !headers.has("Content-Type")) {
         headers.set("Content-Type", "application/json")
     }
 
-    const res = await fetch(`${API_URL}${path}`, {
-        ...options,
-        headers,
-    })
+    const res = await fetchWithAuth(
+        `${API_URL}${path}`,
+        {
+            ...options,
+            headers,
+        },
+        withAuth
+    )
 
     if (!res.ok) {
         const data = await parseJsonSafe(res)
@@ -74,20 +139,15 @@ export async function requestForm<T>(
     options: RequestInit = {},
     withAuth = true
 ): Promise<T> {
-    const headers = new Headers(options.headers)
-    if (withAuth) {
-        const token = getToken()
-        if (token) {
-            headers.set("Authorization", `Bearer ${token}`)
-        }
-    }
-
-    const res = await fetch(`${API_URL}${path}`, {
-        method: options.method ?? "POST",
-        ...options,
-        headers,
-        body: form,
-    })
+    const res = await fetchWithAuth(
+        `${API_URL}${path}`,
+        {
+            method: options.method ?? "POST",
+            ...options,
+            body: form,
+        },
+        withAuth
+    )
 
     if (!res.ok) {
         const data = await parseJsonSafe(res)
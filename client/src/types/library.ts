@@ -23,6 +23,7 @@ export type User = {
     email?: string
     is_active: boolean
     roles: Role[]
+    permissions?: string[]
 }
 
 export type AuthorSummary = {
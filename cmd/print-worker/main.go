@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"elibrary/internal/config"
+	"elibrary/internal/printer"
+	"elibrary/internal/repository/postgres"
+	"log"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func main() {
+	cfg := config.LoadPrintWorker()
+	if err := cfg.Validate(); err != nil {
+		log.Fatal(err)
+	}
+
+	worker, err := printer.NewWorker(cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	switch cfg.Transport {
+	case "postgres":
+		runTable(ctx, cfg, worker)
+	case "amqp":
+		runAMQP(ctx, cfg, worker)
+	default:
+		log.Fatalf("print transport %q runs inside the backend, not in print-worker", cfg.Transport)
+	}
+}
+
+// runTable prints jobs claimed from the print_jobs table.
+func runTable(ctx context.Context, cfg *config.PrintWorkerConfig, worker *printer.Worker) {
+	db, err := pgxpool.New(ctx, cfg.DBURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	log.Println("print worker polling print_jobs")
+	consumer := printer.NewTableConsumer(postgres.NewPrintJobRepository(db), worker, cfg.PollInterval)
+	consumer.ClaimTimeout, consumer.MaxAttempts = cfg.ClaimTimeout, cfg.MaxAttempts
+	consumer.Run(ctx)
+	log.Println("print worker stopped")
+}
+
+func runAMQP(ctx context.Context, cfg *config.PrintWorkerConfig, worker *printer.Worker) {
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := worker.Run(ctx, cfg.RabbitURL, cfg.RabbitQueue)
+		if ctx.Err() != nil {
+			log.Println("print worker stopped")
+			return
+		}
+		if time.Since(started) > time.Minute {
+			backoff = time.Second
+		}
+		log.Printf("print worker disconnected: %v; reconnecting in %s", err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
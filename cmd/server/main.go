@@ -4,6 +4,9 @@ import (
 	"context"
 	"elibrary/internal/config"
 	httpTransport "elibrary/internal/http"
+	"elibrary/internal/printer"
+	"elibrary/internal/repository/postgres"
+	"elibrary/internal/service"
 	"log"
 	"net/http"
 
@@ -22,8 +25,50 @@ func main() {
 	}
 	defer db.Close()
 
-	router := httpTransport.NewRouter(db, cfg)
+	ctx := context.Background()
+
+	transport, closeTransport, err := newPrintTransport(cfg, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeTransport()
+
+	jobs := service.NewPrintJobService(postgres.NewPrintJobRepository(db), transport)
+	go transport.Run(ctx, jobs.ApplyStatus)
+
+	router := httpTransport.NewRouter(db, cfg, transport)
 
 	log.Println("starting server on", cfg.HTTPAddr)
 	log.Fatal(http.ListenAndServe(cfg.HTTPAddr, router))
 }
+
+// newPrintTransport builds the transport selected by PRINT_TRANSPORT. The
+// returned func releases its connections.
+func newPrintTransport(cfg *config.Config, db *pgxpool.Pool) (service.PrintTransport, func(), error) {
+	switch cfg.PrintTransport {
+	case "local":
+		workerCfg := config.LoadPrintWorker()
+		workerCfg.Transport = "local"
+		if err := workerCfg.Validate(); err != nil {
+			return nil, nil, err
+		}
+		worker, err := printer.NewWorker(workerCfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		consumer := printer.NewTableConsumer(postgres.NewPrintJobRepository(db), worker, workerCfg.PollInterval)
+		consumer.ClaimTimeout, consumer.MaxAttempts = workerCfg.ClaimTimeout, workerCfg.MaxAttempts
+		log.Println("printing labels in-process")
+		return printer.NewLocalTransport(consumer), func() {}, nil
+
+	case "postgres":
+		log.Println("print jobs are queued in print_jobs for print-worker")
+		return service.TableTransport{}, func() {}, nil
+
+	default:
+		publisher := service.NewAMQPPublisher(cfg.RabbitURL, cfg.RabbitConfirmTimeout, cfg.RabbitChannelPool)
+		queue := service.NewPrintQueue(cfg.RabbitURL, cfg.RabbitQueue, cfg.RabbitStatusQueue)
+		relay := service.NewOutboxRelay(postgres.NewOutboxRepository(db), publisher, cfg.OutboxBatchSize, cfg.OutboxPollInterval)
+		return service.NewAMQPTransport(queue, relay), func() { _ = publisher.Close() }, nil
+	}
+}
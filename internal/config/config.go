@@ -1,11 +1,14 @@
 package config
 
 import (
+	"elibrary/internal/domain"
 	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
@@ -13,13 +16,64 @@ type Config struct {
 	DBURL              string
 	CORSAllowedOrigins []string
 
-	JWTSecret string
+	PublicCatalogEnabled     bool
+	PublicCatalogRateLimit   int
+	PublicCatalogCORSOrigins []string
+	PublicCatalogTrustProxy  bool
+
+	JWTSecret       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+
+	PasswordMinLength     int
+	PasswordRequireUpper  bool
+	PasswordRequireLower  bool
+	PasswordRequireDigit  bool
+	PasswordRequireSymbol bool
 
 	ImagesPath string
 	ImagesURL  string
 
-	RabbitURL   string
-	RabbitQueue string
+	// PrintTransport is "amqp", "postgres" or "local".
+	PrintTransport string
+
+	RabbitURL         string
+	RabbitQueue       string
+	RabbitStatusQueue string
+
+	RabbitConfirmTimeout time.Duration
+	RabbitChannelPool    int
+
+	OutboxPollInterval time.Duration
+	OutboxBatchSize    int
+
+	// LabelSheetTemplate is the sheet used for PDF labels when a request
+	// does not name one.
+	LabelSheetTemplate string
+	// LabelTranslit is the transliteration scheme of label text: "simple",
+	// "gost", "iso9", "bgn", "icao" or "none".
+	LabelTranslit string
+
+	// Symbologies of printed labels: "ean13", "code128", "qr" or
+	// "datamatrix".
+	BarcodeSymbologyBook     string
+	BarcodeSymbologyLocation string
+	// LocationLinkURL is encoded in 2D symbols on location labels, with
+	// {id} and {barcode} substituted.
+	LocationLinkURL string
+
+	// BarcodeSequenceLowWatermark is how many codes may remain in a barcode
+	// sequence before warnings are logged.
+	BarcodeSequenceLowWatermark int64
+
+	// MetadataProviders are the external catalogs asked, in this order, when
+	// a book is looked up by ISBN: "openlibrary" and "googlebooks", or
+	// "none".
+	MetadataProviders []string
+	OpenLibraryURL    string
+	GoogleBooksURL    string
+	GoogleBooksAPIKey string
+	MetadataTimeout   time.Duration
 }
 
 func Load() *Config {
@@ -28,16 +82,54 @@ func Load() *Config {
 		DBURL:     os.Getenv("DB_URL"),
 		JWTSecret: os.Getenv("JWT_SECRET"),
 
+		AccessTokenTTL:  getDurationEnv("JWT_ACCESS_TTL", 15*time.Minute),
+		RefreshTokenTTL: getDurationEnv("JWT_REFRESH_TTL", 30*24*time.Hour),
+
+		PasswordMinLength:     getIntEnv("PASSWORD_MIN_LENGTH", 8),
+		PasswordRequireUpper:  getBoolEnv("PASSWORD_REQUIRE_UPPER", false),
+		PasswordRequireLower:  getBoolEnv("PASSWORD_REQUIRE_LOWER", false),
+		PasswordRequireDigit:  getBoolEnv("PASSWORD_REQUIRE_DIGIT", true),
+		PasswordRequireSymbol: getBoolEnv("PASSWORD_REQUIRE_SYMBOL", false),
+
 		CORSAllowedOrigins: parseCSVEnv(
 			"CORS_ALLOWED_ORIGINS",
 			[]string{"http://localhost:5173", "http://localhost:3000"},
 		),
 
+		PublicCatalogEnabled:     getBoolEnv("PUBLIC_CATALOG_ENABLED", false),
+		PublicCatalogRateLimit:   getIntEnv("PUBLIC_CATALOG_RATE_LIMIT", 60),
+		PublicCatalogCORSOrigins: parseCSVEnv("PUBLIC_CATALOG_CORS_ORIGINS", []string{"*"}),
+		PublicCatalogTrustProxy:  getBoolEnv("PUBLIC_CATALOG_TRUST_PROXY", false),
+
 		ImagesPath: getEnv("IMAGES_PATH", "./data/images"),
 		ImagesURL:  getEnv("IMAGES_URL", "/static/images"),
 
-		RabbitURL:   os.Getenv("RABBIT_URL"),
-		RabbitQueue: getEnv("RABBIT_QUEUE", "print_queue"),
+		PrintTransport: strings.ToLower(getEnv("PRINT_TRANSPORT", "amqp")),
+
+		RabbitURL:         os.Getenv("RABBIT_URL"),
+		RabbitQueue:       getEnv("RABBIT_QUEUE", "print_queue"),
+		RabbitStatusQueue: getEnv("RABBIT_STATUS_QUEUE", "print_status"),
+
+		RabbitConfirmTimeout: getDurationEnv("RABBIT_CONFIRM_TIMEOUT", 5*time.Second),
+		RabbitChannelPool:    getIntEnv("RABBIT_CHANNEL_POOL", 4),
+
+		OutboxPollInterval: getDurationEnv("OUTBOX_POLL_INTERVAL", time.Second),
+		OutboxBatchSize:    getIntEnv("OUTBOX_BATCH_SIZE", 50),
+
+		LabelSheetTemplate: getEnv("LABEL_SHEET_TEMPLATE", "a4-3x8"),
+		LabelTranslit:      strings.ToLower(getEnv("LABEL_TRANSLIT", "simple")),
+
+		BarcodeSymbologyBook:     strings.ToLower(getEnv("BARCODE_SYMBOLOGY_BOOK", "ean13")),
+		BarcodeSymbologyLocation: strings.ToLower(getEnv("BARCODE_SYMBOLOGY_LOCATION", "ean13")),
+		LocationLinkURL:          os.Getenv("LOCATION_LINK_URL"),
+
+		BarcodeSequenceLowWatermark: int64(getIntEnv("BARCODE_SEQUENCE_LOW_WATERMARK", 10000)),
+
+		MetadataProviders: parseCSVEnv("METADATA_PROVIDERS", nil),
+		OpenLibraryURL:    getEnv("OPENLIBRARY_URL", "https://openlibrary.org"),
+		GoogleBooksURL:    getEnv("GOOGLE_BOOKS_URL", "https://www.googleapis.com/books/v1"),
+		GoogleBooksAPIKey: os.Getenv("GOOGLE_BOOKS_API_KEY"),
+		MetadataTimeout:   getDurationEnv("METADATA_TIMEOUT", 5*time.Second),
 	}
 
 	log.Println("config loaded:", cfg.HTTPAddr)
@@ -53,15 +145,56 @@ func (c *Config) Validate() error {
 	if strings.TrimSpace(c.JWTSecret) == "" {
 		missing = append(missing, "JWT_SECRET")
 	}
-	if strings.TrimSpace(c.RabbitURL) == "" {
+	if c.PrintTransport == "amqp" && strings.TrimSpace(c.RabbitURL) == "" {
 		missing = append(missing, "RABBIT_URL")
 	}
 
-	if len(missing) == 0 {
-		return nil
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", errors.New("missing required environment variables"), strings.Join(missing, ", "))
+	}
+
+	if c.PublicCatalogEnabled && c.PublicCatalogRateLimit < 1 {
+		return fmt.Errorf("PUBLIC_CATALOG_RATE_LIMIT must be at least 1, got %d", c.PublicCatalogRateLimit)
+	}
+
+	if !validPrintTransport(c.PrintTransport) {
+		return fmt.Errorf("PRINT_TRANSPORT must be amqp, postgres or local, got %q", c.PrintTransport)
 	}
 
-	return fmt.Errorf("%w: %s", errors.New("missing required environment variables"), strings.Join(missing, ", "))
+	for key, v := range map[string]string{
+		"BARCODE_SYMBOLOGY_BOOK":     c.BarcodeSymbologyBook,
+		"BARCODE_SYMBOLOGY_LOCATION": c.BarcodeSymbologyLocation,
+	} {
+		if v != "" && !domain.Symbology(v).Valid() {
+			return fmt.Errorf("%s must be ean13, code128, qr or datamatrix, got %q", key, v)
+		}
+	}
+	if c.LabelTranslit != "" && !domain.TranslitScheme(c.LabelTranslit).Valid() {
+		return fmt.Errorf("LABEL_TRANSLIT must be simple, gost, iso9, bgn, icao or none, got %q", c.LabelTranslit)
+	}
+	for _, p := range c.MetadataProviders {
+		if !validMetadataProvider(strings.ToLower(p)) {
+			return fmt.Errorf("METADATA_PROVIDERS must list openlibrary and googlebooks or be none, got %q", p)
+		}
+	}
+
+	return nil
+}
+
+func validPrintTransport(v string) bool {
+	switch v {
+	case "amqp", "postgres", "local":
+		return true
+	}
+	return false
+}
+
+func validMetadataProvider(v string) bool {
+	switch v {
+	case "openlibrary", "googlebooks", "none":
+		return true
+	}
+	return false
 }
 
 func getEnv(key, def string) string {
@@ -71,6 +204,45 @@ func getEnv(key, def string) string {
 	return def
 }
 
+func getDurationEnv(key string, def time.Duration) time.Duration {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return def
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		log.Printf("invalid duration in %s=%q, using default %s", key, raw, def)
+		return def
+	}
+	return d
+}
+
+func getIntEnv(key string, def int) int {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return def
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		log.Printf("invalid integer in %s=%q, using default %d", key, raw, def)
+		return def
+	}
+	return n
+}
+
+func getBoolEnv(key string, def bool) bool {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		log.Printf("invalid boolean in %s=%q, using default %t", key, raw, def)
+		return def
+	}
+	return b
+}
+
 func parseCSVEnv(key string, def []string) []string {
 	raw := strings.TrimSpace(os.Getenv(key))
 	if raw == "" {
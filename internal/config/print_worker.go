@@ -0,0 +1,95 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type PrintWorkerConfig struct {
+	// Transport is "amqp" or "postgres"; the backend also loads this config
+	// for its built-in worker when PRINT_TRANSPORT is "local".
+	Transport string
+
+	RabbitURL   string
+	RabbitQueue string
+
+	DBURL        string
+	PollInterval time.Duration
+	// ClaimTimeout and MaxAttempts govern the recovery of print_jobs left
+	// in printing by a worker that stopped mid-print.
+	ClaimTimeout time.Duration
+	MaxAttempts  int
+
+	// PrinterFormat is "zpl" or "escpos".
+	PrinterFormat string
+	// PrinterAddr is host:port of a raw TCP printer. When empty, labels are
+	// written to SpoolDir instead.
+	PrinterAddr    string
+	PrinterTimeout time.Duration
+	SpoolDir       string
+
+	RetryDelay time.Duration
+}
+
+func LoadPrintWorker() *PrintWorkerConfig {
+	return &PrintWorkerConfig{
+		Transport: strings.ToLower(getEnv("PRINT_TRANSPORT", "amqp")),
+
+		RabbitURL:   getEnv("RABBIT_URL", ""),
+		RabbitQueue: getEnv("RABBIT_QUEUE", "print_queue"),
+
+		DBURL:        getEnv("DB_URL", ""),
+		PollInterval: getDurationEnv("PRINT_POLL_INTERVAL", 2*time.Second),
+		ClaimTimeout: getDurationEnv("PRINT_CLAIM_TIMEOUT", 5*time.Minute),
+		MaxAttempts:  getIntEnv("PRINT_MAX_ATTEMPTS", 3),
+
+		PrinterFormat:  strings.ToLower(getEnv("PRINTER_FORMAT", "zpl")),
+		PrinterAddr:    withDefaultPort(strings.TrimSpace(getEnv("PRINTER_ADDR", "")), "9100"),
+		PrinterTimeout: getDurationEnv("PRINTER_TIMEOUT", 10*time.Second),
+		SpoolDir:       getEnv("PRINT_SPOOL_DIR", ""),
+
+		RetryDelay: getDurationEnv("PRINT_RETRY_DELAY", 5*time.Second),
+	}
+}
+
+func (c *PrintWorkerConfig) Validate() error {
+	var problems []string
+
+	switch c.Transport {
+	case "amqp":
+		if strings.TrimSpace(c.RabbitURL) == "" {
+			problems = append(problems, "RABBIT_URL is required")
+		}
+	case "postgres":
+		if strings.TrimSpace(c.DBURL) == "" {
+			problems = append(problems, "DB_URL is required")
+		}
+	case "local":
+	default:
+		problems = append(problems, "PRINT_TRANSPORT must be amqp, postgres or local")
+	}
+	if c.PrinterFormat != "zpl" && c.PrinterFormat != "escpos" {
+		problems = append(problems, "PRINTER_FORMAT must be zpl or escpos")
+	}
+	if c.MaxAttempts < 1 {
+		problems = append(problems, "PRINT_MAX_ATTEMPTS must be at least 1")
+	}
+	if c.PrinterAddr == "" && strings.TrimSpace(c.SpoolDir) == "" {
+		problems = append(problems, "either PRINTER_ADDR or PRINT_SPOOL_DIR is required")
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", errors.New("invalid print worker configuration"), strings.Join(problems, "; "))
+}
+
+// withDefaultPort appends port to addr when it has none.
+func withDefaultPort(addr, port string) string {
+	if addr == "" || strings.Contains(addr, ":") {
+		return addr
+	}
+	return addr + ":" + port
+}
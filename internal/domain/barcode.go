@@ -1,8 +1,92 @@
 package domain
 
+import (
+	"strings"
+	"time"
+)
+
 type BarcodeType string
 
 const (
 	BarcodeTypeBook     BarcodeType = "book"
 	BarcodeTypeLocation BarcodeType = "location"
 )
+
+func (t BarcodeType) Valid() bool {
+	return t == BarcodeTypeBook || t == BarcodeTypeLocation
+}
+
+// Prefix ranges of in-house EAN-13 codes, as enforced by barcode_sequences.
+const (
+	BookPrefixMin     = 200
+	BookPrefixMax     = 299
+	LocationPrefixMin = 300
+	LocationPrefixMax = 399
+)
+
+// BarcodeTypeOfPrefix tells which kind of object an in-house prefix is
+// reserved for.
+func BarcodeTypeOfPrefix(prefix int) (BarcodeType, bool) {
+	switch {
+	case prefix >= BookPrefixMin && prefix <= BookPrefixMax:
+		return BarcodeTypeBook, true
+	case prefix >= LocationPrefixMin && prefix <= LocationPrefixMax:
+		return BarcodeTypeLocation, true
+	default:
+		return "", false
+	}
+}
+
+// MaxBarcodeSequence is the largest serial that fits the nine digits
+// between the prefix and the check digit of an EAN-13.
+const MaxBarcodeSequence = 999999999
+
+// BarcodeSequence is one prefix range. Each type has one active sequence
+// that new barcodes are taken from.
+type BarcodeSequence struct {
+	Prefix      int         `json:"prefix"`
+	Type        BarcodeType `json:"type"`
+	LastValue   int64       `json:"last_value"`
+	Description *string     `json:"description,omitempty"`
+	Active      bool        `json:"active"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// Remaining is how many barcodes the sequence can still produce.
+func (s BarcodeSequence) Remaining() int64 {
+	return max(0, MaxBarcodeSequence-s.LastValue)
+}
+
+// Symbology is the kind of symbol a barcode is printed as. Library codes
+// are always EAN-13 numbers; the symbology only changes how they are drawn.
+type Symbology string
+
+const (
+	SymbologyEAN13      Symbology = "ean13"
+	SymbologyCode128    Symbology = "code128"
+	SymbologyQR         Symbology = "qr"
+	SymbologyDataMatrix Symbology = "datamatrix"
+)
+
+func ParseSymbology(s string) (Symbology, error) {
+	sym := Symbology(strings.ToLower(strings.TrimSpace(s)))
+	if !sym.Valid() {
+		return "", ErrInvalidSymbology
+	}
+	return sym, nil
+}
+
+func (s Symbology) Valid() bool {
+	switch s {
+	case SymbologyEAN13, SymbologyCode128, SymbologyQR, SymbologyDataMatrix:
+		return true
+	default:
+		return false
+	}
+}
+
+// TwoDimensional reports whether the symbol is a matrix rather than bars.
+func (s Symbology) TwoDimensional() bool {
+	return s == SymbologyQR || s == SymbologyDataMatrix
+}
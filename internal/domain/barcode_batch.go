@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BarcodeBatch is a block of book barcodes reserved for labels printed in
+// advance. Bound counts the codes already given to books.
+type BarcodeBatch struct {
+	ID        uuid.UUID  `json:"id"`
+	Count     int        `json:"count"`
+	Bound     int        `json:"bound"`
+	Note      *string    `json:"note,omitempty"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type ReservedBarcode struct {
+	Barcode string     `json:"barcode"`
+	BatchID uuid.UUID  `json:"batch_id"`
+	BookID  *uuid.UUID `json:"book_id,omitempty"`
+	BoundAt *time.Time `json:"bound_at,omitempty"`
+}
+
+func (b ReservedBarcode) IsBound() bool {
+	return b.BoundAt != nil
+}
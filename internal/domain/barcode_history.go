@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReplacedBarcode is a code taken off a book or a location when its label
+// was reissued. Exactly one of BookID and LocationID is set.
+type ReplacedBarcode struct {
+	Barcode    string     `json:"barcode"`
+	BookID     *uuid.UUID `json:"book_id,omitempty"`
+	LocationID *uuid.UUID `json:"location_id,omitempty"`
+	ReplacedBy string     `json:"replaced_by"`
+	Reason     *string    `json:"reason,omitempty"`
+	CreatedBy  *uuid.UUID `json:"created_by,omitempty"`
+	ReplacedAt time.Time  `json:"replaced_at"`
+}
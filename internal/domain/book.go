@@ -11,6 +11,10 @@ type Book struct {
 	Title          string    `json:"title"`
 	Barcode        string    `json:"barcode"`
 	FactoryBarcode *string   `json:"factory_barcode,omitempty"`
+	// ISBN holds the 13 digits of the ISBN-13 and ISSN the eight characters
+	// of the ISSN, see ParseISBN and ParseISSN.
+	ISBN *string `json:"isbn,omitempty"`
+	ISSN *string `json:"issn,omitempty"`
 
 	PublisherID *uuid.UUID `json:"publisher_id,omitempty"`
 	LocationID  *uuid.UUID `json:"location_id,omitempty"`
@@ -19,6 +23,9 @@ type Book struct {
 
 	Extra map[string]any `json:"extra,omitempty"`
 
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy *uuid.UUID `json:"deleted_by,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
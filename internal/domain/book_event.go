@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BookEventType string
+
+const (
+	BookEventCreated  BookEventType = "created"
+	BookEventUpdated  BookEventType = "updated"
+	BookEventDeleted  BookEventType = "deleted"
+	BookEventRestored BookEventType = "restored"
+)
+
+// BookSnapshot is the full state of a book, including its works, as stored
+// in the book_events journal.
+type BookSnapshot struct {
+	Book  Book               `json:"book"`
+	Works []BookSnapshotWork `json:"works"`
+}
+
+type BookSnapshotWork struct {
+	WorkID   uuid.UUID `json:"work_id"`
+	Position *int      `json:"position,omitempty"`
+}
+
+type BookEventSnapshot struct {
+	Before *BookSnapshot `json:"before"`
+	After  *BookSnapshot `json:"after"`
+}
+
+type BookEvent struct {
+	ID          uuid.UUID         `json:"id"`
+	BookID      uuid.UUID         `json:"book_id"`
+	Type        BookEventType     `json:"event_type"`
+	OccurredAt  time.Time         `json:"occurred_at"`
+	ActorUserID *uuid.UUID        `json:"actor_user_id,omitempty"`
+	Snapshot    BookEventSnapshot `json:"snapshot"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
@@ -3,11 +3,27 @@ package domain
 import "errors"
 
 var (
-	ErrNotFound       = errors.New("not found")
-	ErrInvalidInput   = errors.New("invalid input")
-	ErrForbidden      = errors.New("forbidden")
-	ErrInvalidBarcode = errors.New("invalid barcode")
-	ErrBarcodeExists  = errors.New("barcode already exists")
-	ErrLoginExists    = errors.New("login already exists")
-	ErrRoleExists     = errors.New("role already exists")
+	ErrNotFound          = errors.New("not found")
+	ErrInvalidInput      = errors.New("invalid input")
+	ErrForbidden         = errors.New("forbidden")
+	ErrInvalidBarcode    = errors.New("invalid barcode")
+	ErrBarcodeExists     = errors.New("barcode already exists")
+	ErrInvalidSymbology  = errors.New("invalid symbology")
+	ErrSequenceExists    = errors.New("barcode sequence already exists")
+	ErrLoginExists       = errors.New("login already exists")
+	ErrRoleExists        = errors.New("role already exists")
+	ErrRoleProtected     = errors.New("role is protected")
+	ErrUnknownPermission = errors.New("unknown permission")
+	ErrBookOnLoan        = errors.New("book is already on loan")
+	ErrLoanReturned      = errors.New("loan already returned")
+	ErrLocationNotFound  = errors.New("location not found")
+	ErrBookNotDeleted    = errors.New("book is not in the trash")
+
+	ErrBarcodeNotReserved = errors.New("barcode is not reserved")
+	ErrBarcodeBound       = errors.New("barcode is already bound to a book")
+
+	ErrInvalidTranslit = errors.New("invalid transliteration scheme")
+
+	ErrInvalidISBN = errors.New("invalid isbn")
+	ErrInvalidISSN = errors.New("invalid issn")
 )
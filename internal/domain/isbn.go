@@ -0,0 +1,164 @@
+package domain
+
+import "strings"
+
+// isbnGroup is a block of registration groups sharing one group length.
+// from and to are the seven digits that follow the EAN prefix.
+type isbnGroup struct {
+	prefix   string
+	from, to string
+	length   int
+}
+
+// isbnGroups are the registration group ranges of the International ISBN
+// Agency. Registrant ranges differ per group and change often, so the number
+// is hyphenated by group only.
+var isbnGroups = []isbnGroup{
+	{"978", "0000000", "5999999", 1},
+	{"978", "6000000", "6499999", 3},
+	{"978", "6500000", "6599999", 2},
+	{"978", "7000000", "7999999", 1},
+	{"978", "8000000", "9499999", 2},
+	{"978", "9500000", "9899999", 3},
+	{"978", "9900000", "9989999", 4},
+	{"978", "9990000", "9999999", 5},
+	{"979", "1000000", "1299999", 2},
+	{"979", "8000000", "8999999", 1},
+}
+
+// ParseISBN reads an ISBN-10 or ISBN-13 written with or without hyphens,
+// spaces or an "ISBN" label and returns it as the 13 digits of the ISBN-13.
+// The check digit must match.
+func ParseISBN(s string) (string, error) {
+	code := compactStandardNumber(s, "ISBN")
+
+	switch {
+	case len(code) == 13 && isDigits(code):
+		if !strings.HasPrefix(code, "978") && !strings.HasPrefix(code, "979") {
+			return "", ErrInvalidISBN
+		}
+		if eanCheckDigit(code[:12]) != code[12] {
+			return "", ErrInvalidISBN
+		}
+		return code, nil
+	case len(code) == 10 && isDigits(code[:9]):
+		if isbn10CheckDigit(code[:9]) != code[9] {
+			return "", ErrInvalidISBN
+		}
+		base := "978" + code[:9]
+		return base + string(eanCheckDigit(base)), nil
+	}
+	return "", ErrInvalidISBN
+}
+
+// ISBN10 returns the ISBN-10 form of an ISBN-13. Only the 978 prefix has one.
+func ISBN10(isbn13 string) (string, bool) {
+	if len(isbn13) != 13 || !isDigits(isbn13) || !strings.HasPrefix(isbn13, "978") {
+		return "", false
+	}
+	return isbn13[3:12] + string(isbn10CheckDigit(isbn13[3:12])), true
+}
+
+// HyphenateISBN writes an ISBN-13 as prefix, registration group, the rest
+// and the check digit, e.g. 978-5-17098885-3. Numbers outside the known
+// groups get no group element.
+func HyphenateISBN(isbn13 string) string {
+	if len(isbn13) != 13 || !isDigits(isbn13) {
+		return isbn13
+	}
+
+	prefix, body, check := isbn13[:3], isbn13[3:12], isbn13[12:]
+	for _, g := range isbnGroups {
+		if g.prefix == prefix && body[:7] >= g.from && body[:7] <= g.to {
+			return prefix + "-" + body[:g.length] + "-" + body[g.length:] + "-" + check
+		}
+	}
+	return prefix + "-" + body + "-" + check
+}
+
+// ParseISSN reads an ISSN written with or without its hyphen or an "ISSN"
+// label, or the EAN-13 of a serial (prefix 977), and returns the eight
+// characters of the ISSN. The check digit must match.
+func ParseISSN(s string) (string, error) {
+	code := compactStandardNumber(s, "ISSN")
+
+	switch {
+	case len(code) == 8 && isDigits(code[:7]):
+		if issnCheckDigit(code[:7]) != code[7] {
+			return "", ErrInvalidISSN
+		}
+		return code, nil
+	case len(code) == 13 && isDigits(code) && strings.HasPrefix(code, "977"):
+		if eanCheckDigit(code[:12]) != code[12] {
+			return "", ErrInvalidISSN
+		}
+		return code[3:10] + string(issnCheckDigit(code[3:10])), nil
+	}
+	return "", ErrInvalidISSN
+}
+
+// FormatISSN writes an ISSN as two groups of four, e.g. 0317-8471.
+func FormatISSN(issn string) string {
+	if len(issn) != 8 {
+		return issn
+	}
+	return issn[:4] + "-" + issn[4:]
+}
+
+// compactStandardNumber drops the label ("ISBN-13:", "ISSN " and the like),
+// hyphens and spaces, and upper-cases a trailing x.
+func compactStandardNumber(s, label string) string {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	if strings.HasPrefix(s, label) {
+		s = s[len(label):]
+		if i := strings.IndexByte(s, ':'); i >= 0 {
+			s = s[i+1:]
+		} else if strings.HasPrefix(s, "-10 ") || strings.HasPrefix(s, "-13 ") {
+			s = s[4:]
+		}
+	}
+	return strings.NewReplacer("-", "", " ", "").Replace(s)
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func eanCheckDigit(first12 string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := int(first12[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isbn10CheckDigit(first9 string) byte {
+	return mod11CheckDigit(first9, 10)
+}
+
+func issnCheckDigit(first7 string) byte {
+	return mod11CheckDigit(first7, 8)
+}
+
+// mod11CheckDigit weighs the digits from weight down to 2 and writes a check
+// value of 10 as X.
+func mod11CheckDigit(digits string, weight int) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		sum += int(digits[i]-'0') * (weight - i)
+	}
+	check := (11 - sum%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return byte('0' + check)
+}
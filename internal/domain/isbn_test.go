@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseISBN(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want string
+		err  error
+	}{
+		{in: "9785170988853", want: "9785170988853"},
+		{in: "978-5-17-098885-3", want: "9785170988853"},
+		{in: "5-17-098885-0", want: "9785170988853"},
+		{in: "ISBN 5 17 098885 0", want: "9785170988853"},
+		{in: "ISBN-13: 978-5-17-098885-3", want: "9785170988853"},
+		{in: "080442957x", want: "9780804429573"},
+		{in: "979-10-90636-07-1", want: "9791090636071"},
+		{in: "9785170988854", err: ErrInvalidISBN},
+		{in: "5-17-098885-1", err: ErrInvalidISBN},
+		{in: "4607001234562", err: ErrInvalidISBN},
+		{in: "97851709888", err: ErrInvalidISBN},
+		{in: "", err: ErrInvalidISBN},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseISBN(tt.in)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("ParseISBN(%q) error = %v, want %v", tt.in, err, tt.err)
+		}
+		if got != tt.want {
+			t.Fatalf("ParseISBN(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestISBN10(t *testing.T) {
+	t.Parallel()
+
+	if got, ok := ISBN10("9780804429573"); !ok || got != "080442957X" {
+		t.Fatalf("ISBN10() = %q, %v, want 080442957X, true", got, ok)
+	}
+	if _, ok := ISBN10("9791090636071"); ok {
+		t.Fatal("ISBN10() ok = true for a 979 ISBN, want false")
+	}
+}
+
+func TestHyphenateISBN(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"9785170988853", "978-5-17098885-3"},
+		{"9786010000003", "978-601-000000-3"},
+		{"9788535902778", "978-85-3590277-8"},
+		{"9789995000001", "978-99950-0000-1"},
+		{"9791090636071", "979-10-9063607-1"},
+		{"9790000000001", "979-000000000-1"},
+		{"123", "123"},
+	}
+
+	for _, tt := range tests {
+		if got := HyphenateISBN(tt.in); got != tt.want {
+			t.Fatalf("HyphenateISBN(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseISSN(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want string
+		err  error
+	}{
+		{in: "0317-8471", want: "03178471"},
+		{in: "ISSN 2049-3630", want: "20493630"},
+		{in: "2434-561x", want: "2434561X"},
+		{in: "9770317847001", want: "03178471"},
+		{in: "0317-8472", err: ErrInvalidISSN},
+		{in: "9770317847002", err: ErrInvalidISSN},
+		{in: "9785170988853", err: ErrInvalidISSN},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseISSN(tt.in)
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("ParseISSN(%q) error = %v, want %v", tt.in, err, tt.err)
+		}
+		if got != tt.want {
+			t.Fatalf("ParseISSN(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	if got := FormatISSN("2434561X"); got != "2434-561X" {
+		t.Fatalf("FormatISSN() = %q, want 2434-561X", got)
+	}
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Loan struct {
+	ID     uuid.UUID `json:"id"`
+	BookID uuid.UUID `json:"book_id"`
+	UserID uuid.UUID `json:"user_id"`
+
+	IssuedAt   time.Time  `json:"issued_at"`
+	DueAt      *time.Time `json:"due_at,omitempty"`
+	ReturnedAt *time.Time `json:"returned_at,omitempty"`
+
+	IssuedBy   *uuid.UUID `json:"issued_by,omitempty"`
+	ReturnedBy *uuid.UUID `json:"returned_by,omitempty"`
+
+	Note *string `json:"note,omitempty"`
+}
+
+func (l Loan) IsActive() bool {
+	return l.ReturnedAt == nil
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BookMovement struct {
+	ID     uuid.UUID `json:"id"`
+	BookID uuid.UUID `json:"book_id"`
+
+	FromLocationID *uuid.UUID `json:"from_location_id,omitempty"`
+	ToLocationID   *uuid.UUID `json:"to_location_id,omitempty"`
+
+	MovedAt     time.Time  `json:"moved_at"`
+	ActorUserID *uuid.UUID `json:"actor_user_id,omitempty"`
+	Reason      *string    `json:"reason,omitempty"`
+}
@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxMessage is a message stored in Postgres together with the change
+// that produced it and published to the broker later by the outbox relay.
+// Currently every message is a print task.
+type OutboxMessage struct {
+	ID            int64
+	Queue         string
+	CorrelationID string
+	ReplyTo       string
+	Payload       json.RawMessage
+	Attempts      int
+	LastError     *string
+	AvailableAt   time.Time
+	CreatedAt     time.Time
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PrintJobStatus string
+
+const (
+	PrintJobQueued   PrintJobStatus = "queued"
+	PrintJobPrinting PrintJobStatus = "printing"
+	PrintJobDone     PrintJobStatus = "done"
+	PrintJobFailed   PrintJobStatus = "failed"
+)
+
+func (s PrintJobStatus) Valid() bool {
+	switch s {
+	case PrintJobQueued, PrintJobPrinting, PrintJobDone, PrintJobFailed:
+		return true
+	}
+	return false
+}
+
+type PrintJob struct {
+	ID          uuid.UUID       `json:"id"`
+	RequestedBy *uuid.UUID      `json:"requested_by,omitempty"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      PrintJobStatus  `json:"status"`
+	Error       *string         `json:"error,omitempty"`
+	Attempts    int             `json:"attempts"`
+	ClaimedAt   *time.Time      `json:"claimed_at,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
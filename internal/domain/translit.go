@@ -0,0 +1,46 @@
+package domain
+
+import "strings"
+
+// TranslitScheme names how Cyrillic label text is turned into Latin for
+// printers whose fonts lack Cyrillic.
+type TranslitScheme string
+
+const (
+	// TranslitSimple is the original ad-hoc table, kept as the default so
+	// existing labels do not change.
+	TranslitSimple TranslitScheme = "simple"
+	// TranslitGOST is GOST 7.79-2000 System B, ASCII only.
+	TranslitGOST TranslitScheme = "gost"
+	// TranslitISO9 is ISO 9:1995, one Latin letter with diacritics per
+	// Cyrillic letter.
+	TranslitISO9 TranslitScheme = "iso9"
+	// TranslitBGN is BGN/PCGN romanization.
+	TranslitBGN TranslitScheme = "bgn"
+	// TranslitICAO is the ICAO Doc 9303 scheme used in passports.
+	TranslitICAO TranslitScheme = "icao"
+	// TranslitNone leaves text as is, for printer fonts with Cyrillic.
+	TranslitNone TranslitScheme = "none"
+)
+
+// TranslitSchemes lists every scheme, for messages and docs.
+var TranslitSchemes = []TranslitScheme{
+	TranslitSimple, TranslitGOST, TranslitISO9, TranslitBGN, TranslitICAO, TranslitNone,
+}
+
+func ParseTranslitScheme(s string) (TranslitScheme, error) {
+	scheme := TranslitScheme(strings.ToLower(strings.TrimSpace(s)))
+	if !scheme.Valid() {
+		return "", ErrInvalidTranslit
+	}
+	return scheme, nil
+}
+
+func (s TranslitScheme) Valid() bool {
+	for _, v := range TranslitSchemes {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
@@ -22,9 +22,20 @@ type User struct {
 
 	PasswordHash string `json:"-"`
 	IsActive     bool   `json:"is_active"`
+	TokenVersion int    `json:"-"`
 
-	Roles []Role `json:"roles"`
+	Roles       []Role   `json:"roles"`
+	Permissions []string `json:"permissions,omitempty"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
+
+func (u *User) HasPermission(code string) bool {
+	for _, p := range u.Permissions {
+		if p == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package auth
+
+// Permission codes seeded by the migrations. Keep in sync with the
+// permissions table.
+const (
+	PermBooksView    = "books.view"
+	PermBooksCreate  = "books.create"
+	PermBooksUpdate  = "books.update"
+	PermBooksDelete  = "books.delete"
+	PermBooksRestore = "books.restore"
+	PermBooksPurge   = "books.purge"
+	PermBooksHistory = "books.history"
+
+	PermLoansView   = "loans.view"
+	PermLoansManage = "loans.manage"
+
+	PermWorksCreate = "works.create"
+	PermWorksUpdate = "works.update"
+	PermWorksDelete = "works.delete"
+
+	PermAuthorsCreate = "authors.create"
+	PermAuthorsUpdate = "authors.update"
+	PermAuthorsDelete = "authors.delete"
+
+	PermPublishersCreate = "publishers.create"
+	PermPublishersUpdate = "publishers.update"
+	PermPublishersDelete = "publishers.delete"
+
+	PermLocationsCreate = "locations.create"
+	PermLocationsUpdate = "locations.update"
+	PermLocationsDelete = "locations.delete"
+
+	PermImagesUpload = "images.upload"
+	PermPrintSend    = "print.send"
+
+	PermBarcodesManage = "barcodes.manage"
+
+	PermUsersView   = "users.view"
+	PermUsersCreate = "users.create"
+	PermUsersUpdate = "users.update"
+	PermUsersDelete = "users.delete"
+
+	PermRolesView   = "roles.view"
+	PermRolesManage = "roles.manage"
+	PermRolesAssign = "roles.assign"
+)
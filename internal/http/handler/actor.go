@@ -0,0 +1,25 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+
+	httpMiddleware "elibrary/internal/http/middleware"
+)
+
+// actorCan reports whether the authenticated user has the permission, for
+// parts of a request that need more than the route itself requires.
+func actorCan(r *http.Request, code string) bool {
+	user, ok := httpMiddleware.UserFromContext(r.Context())
+	return ok && user.HasPermission(code)
+}
+
+func actorID(r *http.Request) *uuid.UUID {
+	user, ok := httpMiddleware.UserFromContext(r.Context())
+	if !ok {
+		return nil
+	}
+	id := user.ID
+	return &id
+}
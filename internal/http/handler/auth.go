@@ -1,9 +1,13 @@
 package handler
 
 import (
+	"elibrary/internal/domain"
 	"elibrary/internal/service"
 	"encoding/json"
+	"errors"
+	"log"
 	"net/http"
+	"strings"
 )
 
 type AuthHandler struct {
@@ -26,13 +30,158 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.Service.Login(r.Context(), req.Login, req.Password)
+	tokens, err := h.Service.Login(r.Context(), req.Login, req.Password)
 	if err != nil {
+		if !errors.Is(err, service.ErrInvalidCredentials) {
+			log.Printf("error logging in %q: %v", req.Login, err)
+		}
 		http.Error(w, "invalid credentials", http.StatusUnauthorized)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]string{
-		"access_token": token,
-	})
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req refreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.RefreshToken) == "" {
+		http.Error(w, "refresh_token is required", http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := h.Service.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) {
+			http.Error(w, "invalid refresh token", http.StatusUnauthorized)
+			return
+		}
+		log.Printf("error refreshing token: %v", err)
+		http.Error(w, "error refreshing token", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokens)
+}
+
+type logoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+	All          bool   `json:"all"`
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var req logoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(req.RefreshToken) == "" {
+		http.Error(w, "refresh_token is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.Logout(r.Context(), req.RefreshToken, req.All); err != nil {
+		log.Printf("error logging out: %v", err)
+		http.Error(w, "error logging out", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AuthHandler) Me(w http.ResponseWriter, r *http.Request) {
+	userID := actorID(r)
+	if userID == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	user, err := h.Service.Me(r.Context(), *userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		log.Printf("error getting current user %s: %v", userID, err)
+		http.Error(w, "error getting current user", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+type updateProfileRequest = service.UpdateProfileRequest
+
+func (h *AuthHandler) UpdateMe(w http.ResponseWriter, r *http.Request) {
+	userID := actorID(r)
+	if userID == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req updateProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Service.UpdateMe(r.Context(), *userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		case errors.Is(err, domain.ErrInvalidInput):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			log.Printf("error updating current user %s: %v", userID, err)
+			http.Error(w, "error updating profile", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, user)
+}
+
+type changePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+func (h *AuthHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	userID := actorID(r)
+	if userID == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req changePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := h.Service.ChangePassword(r.Context(), *userID, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		case errors.Is(err, service.ErrInvalidCredentials):
+			// 403 rather than 401: the session itself is valid.
+			http.Error(w, "current password is incorrect", http.StatusForbidden)
+		case errors.Is(err, service.ErrWeakPassword):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			log.Printf("error changing password for %s: %v", userID, err)
+			http.Error(w, "error changing password", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, tokens)
 }
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"elibrary/internal/domain"
+	"elibrary/internal/service"
+)
+
+// parseBarcodeImageOptions reads format, size, dpi and quiet from the query.
+// Range checks are left to the service.
+func parseBarcodeImageOptions(r *http.Request) (service.BarcodeImageOptions, error) {
+	qp := r.URL.Query()
+	opts := service.BarcodeImageOptions{
+		Format: service.BarcodeFormat(strings.TrimSpace(qp.Get("format"))),
+	}
+
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{
+		{"size", &opts.Size},
+		{"dpi", &opts.DPI},
+	} {
+		if s := strings.TrimSpace(qp.Get(p.name)); s != "" {
+			v, err := strconv.Atoi(s)
+			if err != nil {
+				return opts, errors.New("invalid " + p.name)
+			}
+			*p.dst = v
+		}
+	}
+	if s := strings.TrimSpace(qp.Get("quiet")); s != "" {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			return opts, errors.New("invalid quiet")
+		}
+		opts.QuietZone = &v
+	}
+
+	return opts, nil
+}
+
+// writeBarcodeImage sends a rendered barcode. The code behind an ID changes
+// when it is relabeled, so clients revalidate their copy with If-None-Match
+// on every use.
+func writeBarcodeImage(w http.ResponseWriter, r *http.Request, img *service.BarcodeImage, err error, what string) {
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			http.Error(w, what+" not found", http.StatusNotFound)
+		case errors.Is(err, service.ErrInvalidBarcodeOptions):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			log.Printf("error rendering %s barcode: %v", what, err)
+			http.Error(w, "failed to render barcode", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", img.ContentType)
+	w.Header().Set("ETag", img.ETag)
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(img.Data))
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"elibrary/internal/domain"
+	"elibrary/internal/service"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type BarcodeBatchHandler struct {
+	Service *service.BarcodeBatchService
+}
+
+func NewBarcodeBatchHandler(service *service.BarcodeBatchService) *BarcodeBatchHandler {
+	return &BarcodeBatchHandler{Service: service}
+}
+
+type reserveBarcodesRequest = service.ReserveBarcodesRequest
+
+type printBarcodeBatchRequest struct {
+	Symbology domain.Symbology `json:"symbology,omitempty"`
+}
+
+func (h *BarcodeBatchHandler) Reserve(w http.ResponseWriter, r *http.Request) {
+	var req reserveBarcodesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Failed to decode reserve barcodes request body: %v", err)
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	batch, err := h.Service.Reserve(r.Context(), req, actorID(r))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidBatchSize) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("failed to reserve barcodes: %v", err)
+		http.Error(w, "failed to reserve barcodes", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, batch)
+}
+
+func (h *BarcodeBatchHandler) List(w http.ResponseWriter, r *http.Request) {
+	qp := r.URL.Query()
+	batches, err := h.Service.List(r.Context(), parseIntDefault(qp.Get("limit"), 50), parseIntDefault(qp.Get("offset"), 0))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			writeJSON(w, http.StatusOK, []any{})
+			return
+		}
+		log.Printf("failed to list barcode batches: %v", err)
+		http.Error(w, "failed to list barcode batches", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, batches)
+}
+
+func (h *BarcodeBatchHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	batch, err := h.Service.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "barcode batch not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("failed to get barcode batch %s: %v", idStr, err)
+		http.Error(w, "failed to get barcode batch", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, batch)
+}
+
+// Print sends the labels of the batch that are still unbound to the label
+// printer. The body is optional.
+func (h *BarcodeBatchHandler) Print(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	var req printBarcodeBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		log.Printf("Failed to decode batch print request body: %v", err)
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.Service.Print(r.Context(), id, req.Symbology, actorID(r))
+	if err != nil {
+		writeBatchPrintError(w, err, "no unbound barcodes in the batch")
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, res)
+}
@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"elibrary/internal/domain"
+	"elibrary/internal/service"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type BarcodeSequenceHandler struct {
+	Service *service.BarcodeService
+}
+
+func NewBarcodeSequenceHandler(service *service.BarcodeService) *BarcodeSequenceHandler {
+	return &BarcodeSequenceHandler{Service: service}
+}
+
+type createSequenceRequest = service.CreateSequenceRequest
+
+func (h *BarcodeSequenceHandler) List(w http.ResponseWriter, r *http.Request) {
+	seqs, err := h.Service.ListSequences(r.Context())
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			writeJSON(w, http.StatusOK, []any{})
+			return
+		}
+		log.Printf("error listing barcode sequences: %v", err)
+		http.Error(w, "failed to list barcode sequences", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, seqs)
+}
+
+func (h *BarcodeSequenceHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req createSequenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("error decoding create sequence request: %v", err)
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	seq, err := h.Service.CreateSequence(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidInput):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, domain.ErrSequenceExists):
+			http.Error(w, "prefix already exists", http.StatusConflict)
+		default:
+			log.Printf("error creating barcode sequence: %v", err)
+			http.Error(w, "failed to create barcode sequence", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, seq)
+}
+
+func (h *BarcodeSequenceHandler) Activate(w http.ResponseWriter, r *http.Request) {
+	prefixStr := chi.URLParam(r, "prefix")
+	prefix, err := strconv.Atoi(prefixStr)
+	if err != nil {
+		http.Error(w, "invalid prefix", http.StatusBadRequest)
+		return
+	}
+
+	seq, err := h.Service.ActivateSequence(r.Context(), prefix)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "barcode sequence not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("error activating barcode sequence %s: %v", prefixStr, err)
+		http.Error(w, "failed to activate barcode sequence", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, seq)
+}
@@ -6,6 +6,7 @@ import (
 	"elibrary/internal/service"
 	"encoding/json"
 	"errors"
+	"io"
 	"log"
 	"net/http"
 	"strings"
@@ -23,8 +24,12 @@ func NewBookAdminHandler(service *service.BookService) *BookAdminHandler {
 }
 
 type createBookRequest struct {
-	Book  domain.Book                `json:"book"`
-	Works []repository.BookWorkInput `json:"works,omitempty"`
+	Book       domain.Book                `json:"book"`
+	Works      []repository.BookWorkInput `json:"works,omitempty"`
+	PrintLabel bool                       `json:"print_label,omitempty"`
+	// ReservedBarcode is a scanned pre-printed label to use instead of a
+	// newly generated code.
+	ReservedBarcode string `json:"reserved_barcode,omitempty"`
 }
 
 func (h *BookAdminHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -40,13 +45,22 @@ func (h *BookAdminHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	created, err := h.Service.Create(r.Context(), req.Book, req.Works)
+	req.Book.Barcode = strings.TrimSpace(req.ReservedBarcode)
+
+	created, err := h.Service.Create(r.Context(), req.Book, req.Works, req.PrintLabel, actorID(r))
 	if err != nil {
 		if errors.Is(err, domain.ErrBarcodeExists) {
 			log.Printf("book barcode already exists: %v", err)
 			http.Error(w, "barcode already exists", http.StatusConflict)
 			return
 		}
+		if errors.Is(err, domain.ErrLocationNotFound) {
+			http.Error(w, "location not found", http.StatusBadRequest)
+			return
+		}
+		if writeReservedBarcodeError(w, err) || writeStandardNumberError(w, err) {
+			return
+		}
 		log.Printf("failed to create book: %v", err)
 		http.Error(w, "failed to create book", http.StatusInternalServerError)
 		return
@@ -73,18 +87,287 @@ func (h *BookAdminHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.Service.Update(r.Context(), id, req); err != nil {
+	if err := h.Service.Update(r.Context(), id, req, actorID(r)); err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			http.Error(w, "book not found", http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, domain.ErrLocationNotFound) {
+			http.Error(w, "location not found", http.StatusBadRequest)
+			return
+		}
 		if errors.Is(err, domain.ErrBarcodeExists) {
 			http.Error(w, "barcode already exists", http.StatusConflict)
 			return
 		}
+		if writeStandardNumberError(w, err) {
+			return
+		}
 		http.Error(w, "failed to update book", http.StatusInternalServerError)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+type relabelBookRequest = service.RelabelRequest
+
+// Relabel reissues the barcode of a book, either with a new code or with a
+// scanned pre-printed label.
+func (h *BookAdminHandler) Relabel(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	var req relabelBookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		log.Printf("Failed to decode request body: %v", err)
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	req.Barcode = strings.TrimSpace(req.Barcode)
+
+	book, err := h.Service.Relabel(r.Context(), id, req, actorID(r))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "book not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrBarcodeExists) {
+			http.Error(w, "barcode already exists", http.StatusConflict)
+			return
+		}
+		if writeReservedBarcodeError(w, err) {
+			return
+		}
+		log.Printf("failed to relabel book %s: %v", idStr, err)
+		http.Error(w, "failed to relabel book", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, book)
+}
+
+func writeReservedBarcodeError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, domain.ErrInvalidBarcode):
+		http.Error(w, "invalid barcode", http.StatusBadRequest)
+	case errors.Is(err, domain.ErrBarcodeNotReserved):
+		http.Error(w, "barcode is not reserved", http.StatusBadRequest)
+	case errors.Is(err, domain.ErrBarcodeBound):
+		http.Error(w, "barcode is already bound to a book", http.StatusConflict)
+	default:
+		return false
+	}
+	return true
+}
+
+func writeStandardNumberError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, domain.ErrInvalidISBN):
+		http.Error(w, "invalid isbn", http.StatusBadRequest)
+	case errors.Is(err, domain.ErrInvalidISSN):
+		http.Error(w, "invalid issn", http.StatusBadRequest)
+	default:
+		return false
+	}
+	return true
+}
+
+type moveBooksRequest = service.MoveBooksRequest
+
+func (h *BookAdminHandler) Move(w http.ResponseWriter, r *http.Request) {
+	var req moveBooksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Failed to decode request body: %v", err)
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.BookIDs) == 0 {
+		http.Error(w, "book_ids are required", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.Move(r.Context(), req, actorID(r)); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "book not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrLocationNotFound) {
+			http.Error(w, "location not found", http.StatusBadRequest)
+			return
+		}
+		log.Printf("failed to move books: %v", err)
+		http.Error(w, "failed to move books", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *BookAdminHandler) History(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	events, err := h.Service.GetHistory(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			writeJSON(w, http.StatusOK, []any{})
+			return
+		}
+		log.Printf("failed to get history for book %s: %v", idStr, err)
+		http.Error(w, "failed to get book history", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, events)
+}
+
+func (h *BookAdminHandler) Diff(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	fromID, err := uuid.Parse(r.URL.Query().Get("from"))
+	if err != nil {
+		http.Error(w, "invalid from", http.StatusBadRequest)
+		return
+	}
+
+	var toID *uuid.UUID
+	if s := strings.TrimSpace(r.URL.Query().Get("to")); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			http.Error(w, "invalid to", http.StatusBadRequest)
+			return
+		}
+		toID = &id
+	}
+
+	changes, err := h.Service.Diff(r.Context(), id, fromID, toID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "event not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrEventBookMismatch) {
+			http.Error(w, "event belongs to another book", http.StatusBadRequest)
+			return
+		}
+		log.Printf("failed to diff book %s history: %v", idStr, err)
+		http.Error(w, "failed to diff book history", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, changes)
+}
+
+func (h *BookAdminHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.Delete(r.Context(), id, actorID(r)); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "book not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrBookOnLoan) {
+			http.Error(w, "cannot delete a book that is on loan", http.StatusConflict)
+			return
+		}
+		log.Printf("failed to delete book %s: %v", idStr, err)
+		http.Error(w, "failed to delete book", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *BookAdminHandler) Trash(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseBookFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	books, err := h.Service.GetTrash(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			writeJSON(w, http.StatusOK, map[string]any{"items": []any{}, "count": 0})
+			return
+		}
+		log.Printf("failed to get deleted books: %v", err)
+		http.Error(w, "failed to get books", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"items": books,
+		"count": len(books),
+	})
+}
+
+func (h *BookAdminHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.Restore(r.Context(), id, actorID(r)); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "book not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrBookNotDeleted) {
+			http.Error(w, "book is not in the trash", http.StatusConflict)
+			return
+		}
+		log.Printf("failed to restore book %s: %v", idStr, err)
+		http.Error(w, "failed to restore book", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *BookAdminHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.Purge(r.Context(), id); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "book not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrBookNotDeleted) {
+			http.Error(w, "book must be deleted before purge", http.StatusConflict)
+			return
+		}
+		log.Printf("failed to purge book %s: %v", idStr, err)
+		http.Error(w, "failed to purge book", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -4,6 +4,7 @@ import (
 	"elibrary/internal/domain"
 	"elibrary/internal/service"
 	"errors"
+	"log"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -61,3 +62,65 @@ func (h *BookInternalHandler) List(w http.ResponseWriter, r *http.Request) {
 		"count": len(books),
 	})
 }
+
+func (h *BookInternalHandler) Movements(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	movements, err := h.Service.GetMovements(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			writeJSON(w, http.StatusOK, []any{})
+			return
+		}
+		log.Printf("error getting movements for book %s: %v", idStr, err)
+		http.Error(w, "failed to get movements", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, movements)
+}
+
+func (h *BookInternalHandler) BarcodeHistory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.Service.BarcodeHistory(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			writeJSON(w, http.StatusOK, []any{})
+			return
+		}
+		log.Printf("error getting barcode history for book %s: %v", idStr, err)
+		http.Error(w, "failed to get barcode history", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, history)
+}
+
+func (h *BookInternalHandler) Barcode(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	opts, err := parseBarcodeImageOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	img, err := h.Service.BarcodeImage(r.Context(), id, opts)
+	writeBarcodeImage(w, r, img, err, "book")
+}
@@ -9,6 +9,7 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -16,6 +17,10 @@ import (
 	"github.com/google/uuid"
 )
 
+// maxPublicLimit bounds the page size of the catalog listing so that a
+// single anonymous request stays cheap regardless of the rate limit.
+const maxPublicLimit = 100
+
 type BookPublicHandler struct {
 	Service *service.BookService
 }
@@ -47,7 +52,7 @@ func (h *BookPublicHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *BookPublicHandler) List(w http.ResponseWriter, r *http.Request) {
-	filter, err := parseBookFilter(r)
+	filter, err := parsePublicBookFilter(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
@@ -98,9 +103,53 @@ func (h *BookPublicHandler) Search(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// parseBookFilter parses the filter for authenticated book listings,
+// which may also narrow the result to a location subtree.
 func parseBookFilter(r *http.Request) (repository.BookFilter, error) {
 	qp := r.URL.Query()
 
+	f, err := parseCatalogFilter(qp)
+	if err != nil {
+		return f, err
+	}
+	if s := strings.TrimSpace(qp.Get("location_id")); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return f, errors.New("invalid location_id")
+		}
+		f.LocationID = &id
+	}
+
+	return f, nil
+}
+
+// parsePublicBookFilter parses the filter for the catalog listing, which
+// may be served anonymously and therefore must not reveal where books are.
+func parsePublicBookFilter(r *http.Request) (repository.BookFilter, error) {
+	qp := r.URL.Query()
+
+	if qp.Has("location_id") {
+		return repository.BookFilter{}, errors.New("location_id is not allowed")
+	}
+
+	f, err := parseCatalogFilter(qp)
+	if err != nil {
+		return f, err
+	}
+	if *f.Limit < 0 {
+		return f, errors.New("invalid limit")
+	}
+	if *f.Offset < 0 {
+		return f, errors.New("invalid offset")
+	}
+	if *f.Limit > maxPublicLimit {
+		f.Limit = intPtr(maxPublicLimit)
+	}
+
+	return f, nil
+}
+
+func parseCatalogFilter(qp url.Values) (repository.BookFilter, error) {
 	var f repository.BookFilter
 
 	if s := strings.TrimSpace(qp.Get("id")); s != "" {
@@ -116,6 +165,15 @@ func parseBookFilter(r *http.Request) (repository.BookFilter, error) {
 	if s := strings.TrimSpace(qp.Get("factory_barcode")); s != "" {
 		f.FactoryBarcode = &s
 	}
+	if s := strings.TrimSpace(qp.Get("isbn")); s != "" {
+		if isbn, err := domain.ParseISBN(s); err == nil {
+			f.ISBN = &isbn
+		} else if issn, err := domain.ParseISSN(s); err == nil {
+			f.ISSN = &issn
+		} else {
+			return f, errors.New("invalid isbn")
+		}
+	}
 	if s := strings.TrimSpace(qp.Get("q")); s != "" {
 		f.Query = &s
 	}
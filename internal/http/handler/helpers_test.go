@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"elibrary/internal/repository"
+	"elibrary/internal/service"
 )
 
 func TestHealth(t *testing.T) {
@@ -36,6 +37,7 @@ func TestParseBookFilter(t *testing.T) {
 		"factory_barcode": []string{"factory"},
 		"q":               []string{" history "},
 		"publisher_id":    []string{"550e8400-e29b-41d4-a716-446655440001"},
+		"location_id":     []string{"550e8400-e29b-41d4-a716-446655440002"},
 		"year_from":       []string{"1990"},
 		"year_to":         []string{"2000"},
 		"limit":           []string{"50"},
@@ -48,7 +50,7 @@ func TestParseBookFilter(t *testing.T) {
 		t.Fatalf("parseBookFilter() error = %v", err)
 	}
 
-	if got.ID == nil || got.Barcode == nil || got.FactoryBarcode == nil || got.Query == nil || got.PublisherID == nil {
+	if got.ID == nil || got.Barcode == nil || got.FactoryBarcode == nil || got.Query == nil || got.PublisherID == nil || got.LocationID == nil {
 		t.Fatalf("parseBookFilter() missing expected pointers: %+v", got)
 	}
 	if *got.Query != "history" {
@@ -72,6 +74,7 @@ func TestParseBookFilterInvalidValues(t *testing.T) {
 	}{
 		{name: "bad id", query: "id=bad", want: "invalid id"},
 		{name: "bad publisher", query: "publisher_id=bad", want: "invalid publisher_id"},
+		{name: "bad location", query: "location_id=bad", want: "invalid location_id"},
 		{name: "bad year_from", query: "year_from=nope", want: "invalid year_from"},
 		{name: "bad year_to", query: "year_to=nope", want: "invalid year_to"},
 	}
@@ -107,6 +110,50 @@ func TestParseBookFilterDefaults(t *testing.T) {
 	}
 }
 
+func TestPublicBookListRejectsLocation(t *testing.T) {
+	t.Parallel()
+
+	h := NewBookPublicHandler(nil)
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/books/public?location_id=550e8400-e29b-41d4-a716-446655440002", nil)
+
+	h.List(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	got, err := parsePublicBookFilter(httptest.NewRequest(http.MethodGet, "/books/public?q=history", nil))
+	if err != nil {
+		t.Fatalf("parsePublicBookFilter() error = %v", err)
+	}
+	if got.LocationID != nil {
+		t.Fatalf("LocationID = %v, want nil", got.LocationID)
+	}
+}
+
+func TestParsePublicBookFilterPagination(t *testing.T) {
+	t.Parallel()
+
+	got, err := parsePublicBookFilter(httptest.NewRequest(http.MethodGet, "/books/public?limit=100000&offset=40", nil))
+	if err != nil {
+		t.Fatalf("parsePublicBookFilter() error = %v", err)
+	}
+	if *got.Limit != maxPublicLimit || *got.Offset != 40 {
+		t.Fatalf("pagination = limit %d offset %d, want %d and 40", *got.Limit, *got.Offset, maxPublicLimit)
+	}
+
+	for query, want := range map[string]string{
+		"offset=-1": "invalid offset",
+		"limit=-5":  "invalid limit",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/books/public?"+query, nil)
+		if _, err := parsePublicBookFilter(req); err == nil || err.Error() != want {
+			t.Errorf("parsePublicBookFilter(%s) error = %v, want %q", query, err, want)
+		}
+	}
+}
+
 func TestWriteJSON(t *testing.T) {
 	t.Parallel()
 
@@ -148,3 +195,44 @@ func TestParseIntDefaultAndIntPtr(t *testing.T) {
 
 	var _ repository.BookFilter
 }
+
+func TestParseBarcodeImageOptions(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/books/internal/x/barcode?format=svg&size=150&dpi=600&quiet=4", nil)
+	opts, err := parseBarcodeImageOptions(req)
+	if err != nil {
+		t.Fatalf("parseBarcodeImageOptions() error = %v", err)
+	}
+	if opts.Format != "svg" || opts.Size != 150 || opts.DPI != 600 || opts.QuietZone == nil || *opts.QuietZone != 4 {
+		t.Fatalf("parseBarcodeImageOptions() = %+v", opts)
+	}
+
+	for _, q := range []string{"size=big", "dpi=1.5", "quiet=x"} {
+		req := httptest.NewRequest(http.MethodGet, "/locations/x/barcode?"+q, nil)
+		if _, err := parseBarcodeImageOptions(req); err == nil {
+			t.Errorf("parseBarcodeImageOptions(%s) error = nil, want error", q)
+		}
+	}
+}
+
+func TestWriteBarcodeImageNotModified(t *testing.T) {
+	t.Parallel()
+
+	img, err := service.NewBarcodeService(nil).RenderBarcode("9780000001238", service.BarcodeImageOptions{})
+	if err != nil {
+		t.Fatalf("RenderBarcode() error = %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/books/internal/x/barcode", nil)
+	req.Header.Set("If-None-Match", img.ETag)
+	rec := httptest.NewRecorder()
+	writeBarcodeImage(rec, req, img, nil, "book")
+
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotModified)
+	}
+	if rec.Header().Get("ETag") != img.ETag {
+		t.Fatalf("ETag = %q, want %q", rec.Header().Get("ETag"), img.ETag)
+	}
+}
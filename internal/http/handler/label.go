@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"elibrary/internal/domain"
+	"elibrary/internal/printer"
+	"elibrary/internal/service"
+
+	"github.com/google/uuid"
+)
+
+type LabelHandler struct {
+	Labels          *service.LabelService
+	Batches         *service.BarcodeBatchService
+	DefaultTemplate string
+	// DefaultTranslit spells label text when a request does not pick a
+	// scheme.
+	DefaultTranslit domain.TranslitScheme
+}
+
+func NewLabelHandler(labels *service.LabelService, batches *service.BarcodeBatchService, defaultTemplate string) *LabelHandler {
+	return &LabelHandler{Labels: labels, Batches: batches, DefaultTemplate: defaultTemplate}
+}
+
+// batchLabelSelection picks the unbound codes of a reserved barcode batch.
+type batchLabelSelection struct {
+	ID        uuid.UUID        `json:"id"`
+	Symbology domain.Symbology `json:"symbology,omitempty"`
+}
+
+type labelSheetRequest struct {
+	Books          *service.BookSelection     `json:"books,omitempty"`
+	Locations      *service.LocationSelection `json:"locations,omitempty"`
+	Batch          *batchLabelSelection       `json:"batch,omitempty"`
+	Template       string                     `json:"template,omitempty"`
+	CustomTemplate *printer.SheetTemplate     `json:"custom_template,omitempty"`
+	Start          int                        `json:"start,omitempty"`
+	Translit       string                     `json:"translit,omitempty"`
+}
+
+// SheetPDF renders labels for books, locations or a reserved barcode batch
+// onto sticker sheets and returns them as a PDF for printing on an office
+// printer.
+func (h *LabelHandler) SheetPDF(w http.ResponseWriter, r *http.Request) {
+	var req labelSheetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Failed to decode label sheet request body: %v", err)
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	tmpl, err := h.template(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	scheme := h.DefaultTranslit
+	if req.Translit != "" {
+		scheme, err = domain.ParseTranslitScheme(req.Translit)
+		if err != nil {
+			http.Error(w, "invalid transliteration scheme", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var tasks []service.PrintTask
+	switch {
+	case req.selections() > 1:
+		http.Error(w, "only one of books, locations or batch may be set", http.StatusBadRequest)
+		return
+	case req.Books != nil:
+		tasks, err = h.Labels.BookTasks(r.Context(), *req.Books)
+		if err != nil {
+			writeBatchPrintError(w, err, "no books match the selection")
+			return
+		}
+	case req.Locations != nil:
+		if req.Locations.LocationID == uuid.Nil {
+			http.Error(w, "locations.location_id is required", http.StatusBadRequest)
+			return
+		}
+		tasks, err = h.Labels.LocationTasks(r.Context(), *req.Locations)
+		if err != nil {
+			writeBatchPrintError(w, err, "location not found")
+			return
+		}
+	case req.Batch != nil:
+		tasks, err = h.Batches.Tasks(r.Context(), req.Batch.ID, req.Batch.Symbology)
+		if err != nil {
+			writeBatchPrintError(w, err, "no unbound barcodes in the batch")
+			return
+		}
+	default:
+		http.Error(w, "books, locations or batch is required", http.StatusBadRequest)
+		return
+	}
+
+	pdf, err := printer.RenderSheet(tmpl, printer.SheetLabels(tasks, scheme), req.Start)
+	if err != nil {
+		if errors.Is(err, printer.ErrInvalidTemplate) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Printf("Failed to render label sheet: %v", err)
+		http.Error(w, "failed to render label sheet", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="labels.pdf"`)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(pdf)
+}
+
+func (req labelSheetRequest) selections() int {
+	n := 0
+	if req.Books != nil {
+		n++
+	}
+	if req.Locations != nil {
+		n++
+	}
+	if req.Batch != nil {
+		n++
+	}
+	return n
+}
+
+func (h *LabelHandler) template(req labelSheetRequest) (printer.SheetTemplate, error) {
+	if req.CustomTemplate != nil {
+		if req.Template != "" {
+			return printer.SheetTemplate{}, errors.New("either template or custom_template must be set, not both")
+		}
+		return *req.CustomTemplate, nil
+	}
+
+	name := strings.ToLower(strings.TrimSpace(req.Template))
+	if name == "" {
+		name = h.DefaultTemplate
+	}
+	tmpl, ok := printer.SheetTemplates[name]
+	if !ok {
+		return printer.SheetTemplate{}, fmt.Errorf("unknown template %q, expected one of: %s",
+			name, strings.Join(printer.SheetTemplateNames(), ", "))
+	}
+	return tmpl, nil
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"elibrary/internal/domain"
+	"elibrary/internal/service"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type LoanHandler struct {
+	Service *service.LoanService
+}
+
+func NewLoanHandler(service *service.LoanService) *LoanHandler {
+	return &LoanHandler{Service: service}
+}
+
+type checkoutRequest = service.CheckoutRequest
+
+func (h *LoanHandler) Checkout(w http.ResponseWriter, r *http.Request) {
+	var req checkoutRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("error decoding checkout request: %v", err)
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	loan, err := h.Service.Checkout(r.Context(), req, actorID(r))
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			http.Error(w, "book_id and user_id are required", http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, service.ErrInvalidDueDate) {
+			http.Error(w, "due_at must be in the future", http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, service.ErrBorrowerNotFound) {
+			http.Error(w, "user not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "book not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrBookOnLoan) {
+			http.Error(w, "book is already on loan", http.StatusConflict)
+			return
+		}
+		log.Printf("error checking out book %s: %v", req.BookID, err)
+		http.Error(w, "error checking out book", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, loan)
+}
+
+func (h *LoanHandler) Return(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		log.Printf("error parsing loan id %s: %v", idStr, err)
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	loan, err := h.Service.Return(r.Context(), id, actorID(r))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "loan not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrLoanReturned) {
+			http.Error(w, "loan already returned", http.StatusConflict)
+			return
+		}
+		log.Printf("error returning loan %s: %v", idStr, err)
+		http.Error(w, "error returning loan", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, loan)
+}
+
+func (h *LoanHandler) GetActiveByUser(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		log.Printf("error parsing user id %s: %v", idStr, err)
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	loans, err := h.Service.GetActiveByUser(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			writeJSON(w, http.StatusOK, []any{})
+			return
+		}
+		log.Printf("error getting loans for user %s: %v", idStr, err)
+		http.Error(w, "error getting loans", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, loans)
+}
+
+func (h *LoanHandler) GetActiveByBook(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		log.Printf("error parsing book id %s: %v", idStr, err)
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	loans, err := h.Service.GetActiveByBook(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			writeJSON(w, http.StatusOK, []any{})
+			return
+		}
+		log.Printf("error getting loans for book %s: %v", idStr, err)
+		http.Error(w, "error getting loans", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, loans)
+}
@@ -5,6 +5,7 @@ import (
 	"elibrary/internal/service"
 	"encoding/json"
 	"errors"
+	"io"
 	"log"
 	"net/http"
 
@@ -234,3 +235,78 @@ func (h *LocationHandler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *LocationHandler) Barcode(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	opts, err := parseBarcodeImageOptions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	img, err := h.Service.BarcodeImage(r.Context(), id, opts)
+	writeBarcodeImage(w, r, img, err, "location")
+}
+
+type relabelLocationRequest = service.RelabelLocationRequest
+
+func (h *LocationHandler) Relabel(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	var req relabelLocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		log.Printf("error decoding relabel location request: %v", err)
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	location, err := h.Service.Relabel(r.Context(), id, req, actorID(r))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "location not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, domain.ErrBarcodeExists) {
+			http.Error(w, "barcode already exists", http.StatusConflict)
+			return
+		}
+		log.Printf("error relabeling location %s: %v", idStr, err)
+		http.Error(w, "error relabeling location", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, location)
+}
+
+func (h *LocationHandler) BarcodeHistory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.Service.BarcodeHistory(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			writeJSON(w, http.StatusOK, []any{})
+			return
+		}
+		log.Printf("error getting barcode history for location %s: %v", idStr, err)
+		http.Error(w, "error getting barcode history", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, history)
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"elibrary/internal/domain"
+	"elibrary/internal/service"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+type MetadataHandler struct {
+	Service *service.MetadataService
+}
+
+func NewMetadataHandler(service *service.MetadataService) *MetadataHandler {
+	return &MetadataHandler{Service: service}
+}
+
+type lookupBookRequest struct {
+	ISBN string `json:"isbn"`
+}
+
+// Lookup returns a book draft prefilled from the external catalogs.
+func (h *MetadataHandler) Lookup(w http.ResponseWriter, r *http.Request) {
+	var req lookupBookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Failed to decode lookup request body: %v", err)
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	draft, err := h.Service.Lookup(r.Context(), req.ISBN)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidISBN):
+			http.Error(w, "invalid isbn", http.StatusBadRequest)
+		case errors.Is(err, domain.ErrNotFound):
+			http.Error(w, "book not found in external catalogs", http.StatusNotFound)
+		case errors.Is(err, service.ErrMetadataDisabled):
+			http.Error(w, "book lookup is disabled", http.StatusNotImplemented)
+		case errors.Is(err, service.ErrMetadataUnavailable):
+			http.Error(w, "external catalogs unavailable", http.StatusBadGateway)
+		default:
+			log.Printf("failed to look up isbn %q: %v", req.ISBN, err)
+			http.Error(w, "failed to look up book", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, draft)
+}
@@ -2,25 +2,36 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
 
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
 	"elibrary/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
 type PrintHandler struct {
-	Queue *service.PrintQueue
+	Jobs   *service.PrintJobService
+	Labels *service.LabelService
 }
 
-func NewPrintHandler(queue *service.PrintQueue) *PrintHandler {
-	return &PrintHandler{Queue: queue}
+func NewPrintHandler(jobs *service.PrintJobService, labels *service.LabelService) *PrintHandler {
+	return &PrintHandler{Jobs: jobs, Labels: labels}
 }
 
 type printRequest struct {
-	Str1    string `json:"str1"`
-	Str2    string `json:"str2"`
-	Barcode string `json:"barcode"`
+	Str1      string `json:"str1"`
+	Str2      string `json:"str2"`
+	Barcode   string `json:"barcode"`
+	Symbology string `json:"symbology,omitempty"`
+	Data      string `json:"data,omitempty"`
+	Translit  string `json:"translit,omitempty"`
 }
 
 func (h *PrintHandler) Send(w http.ResponseWriter, r *http.Request) {
@@ -40,20 +51,204 @@ func (h *PrintHandler) Send(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	req.Str1 = service.TransliterateRuToEn(req.Str1)
-	req.Str2 = service.TransliterateRuToEn(req.Str2)
-
 	task := service.PrintTask{
 		Str1:    req.Str1,
 		Str2:    req.Str2,
 		Barcode: req.Barcode,
+		Data:    strings.TrimSpace(req.Data),
+	}
+	if req.Symbology != "" {
+		sym, err := domain.ParseSymbology(req.Symbology)
+		if err != nil {
+			http.Error(w, "invalid symbology", http.StatusBadRequest)
+			return
+		}
+		task.Symbology = sym
+	}
+	// Text is stored as typed and transliterated by the worker, so that a
+	// printer with a Cyrillic font can be served with TranslitNone.
+	if req.Translit != "" {
+		scheme, err := domain.ParseTranslitScheme(req.Translit)
+		if err != nil {
+			http.Error(w, "invalid transliteration scheme", http.StatusBadRequest)
+			return
+		}
+		task.Translit = scheme
+	}
+	// Catch codes the worker could never print before a job is created.
+	if _, err := service.EncodeSymbol(task.Symbology, task.SymbolData()); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
-	if err := h.Queue.Send(r.Context(), task); err != nil {
+	job, err := h.Jobs.Submit(r.Context(), task, actorID(r))
+	if err != nil {
 		log.Printf("Failed to enqueue print task: %v", err)
 		http.Error(w, "failed to enqueue print task", http.StatusInternalServerError)
 		return
 	}
 
-	w.WriteHeader(http.StatusAccepted)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+type printBooksRequest = service.BookSelection
+
+func (h *PrintHandler) PrintBooks(w http.ResponseWriter, r *http.Request) {
+	var req printBooksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Failed to decode batch print request body: %v", err)
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.Labels.PrintBooks(r.Context(), req, actorID(r))
+	if err != nil {
+		writeBatchPrintError(w, err, "no books match the selection")
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, res)
+}
+
+type printLocationsRequest = service.LocationSelection
+
+func (h *PrintHandler) PrintLocations(w http.ResponseWriter, r *http.Request) {
+	var req printLocationsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Failed to decode location print request body: %v", err)
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+	if req.LocationID == uuid.Nil {
+		http.Error(w, "location_id is required", http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.Labels.PrintLocations(r.Context(), req, actorID(r))
+	if err != nil {
+		writeBatchPrintError(w, err, "location not found")
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, res)
+}
+
+func writeBatchPrintError(w http.ResponseWriter, err error, notFound string) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		http.Error(w, notFound, http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidSelection):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrInvalidLocationType):
+		http.Error(w, "invalid location type", http.StatusBadRequest)
+	case errors.Is(err, domain.ErrInvalidSymbology):
+		http.Error(w, "invalid symbology", http.StatusBadRequest)
+	case errors.Is(err, domain.ErrInvalidTranslit):
+		http.Error(w, "invalid transliteration scheme", http.StatusBadRequest)
+	case errors.Is(err, service.ErrBatchTooLarge):
+		http.Error(w, fmt.Sprintf("too many labels, at most %d per request", service.MaxLabelBatch), http.StatusBadRequest)
+	default:
+		log.Printf("Failed to enqueue print batch: %v", err)
+		http.Error(w, "failed to enqueue print batch", http.StatusInternalServerError)
+	}
+}
+
+func (h *PrintHandler) ListJobs(w http.ResponseWriter, r *http.Request) {
+	filter, err := parsePrintJobFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	jobs, err := h.Jobs.List(r.Context(), filter)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			writeJSON(w, http.StatusOK, []any{})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidPrintJob) {
+			http.Error(w, "invalid status", http.StatusBadRequest)
+			return
+		}
+		log.Printf("error listing print jobs: %v", err)
+		http.Error(w, "error listing print jobs", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+func (h *PrintHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		log.Printf("error parsing print job id %s: %v", idStr, err)
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.Jobs.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "print job not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("error getting print job %s: %v", idStr, err)
+		http.Error(w, "error getting print job", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, job)
+}
+
+func (h *PrintHandler) RetryJob(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		log.Printf("error parsing print job id %s: %v", idStr, err)
+		http.Error(w, "invalid id", http.StatusBadRequest)
+		return
+	}
+
+	job, err := h.Jobs.Retry(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "print job not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrPrintJobNotFailed) {
+			http.Error(w, "only failed print jobs can be retried", http.StatusConflict)
+			return
+		}
+		log.Printf("error retrying print job %s: %v", idStr, err)
+		http.Error(w, "failed to enqueue print task", http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func parsePrintJobFilter(r *http.Request) (repository.PrintJobFilter, error) {
+	qp := r.URL.Query()
+	var f repository.PrintJobFilter
+
+	if s := strings.TrimSpace(qp.Get("status")); s != "" {
+		status := domain.PrintJobStatus(s)
+		if !status.Valid() {
+			return f, errors.New("invalid status")
+		}
+		f.Status = &status
+	}
+	if s := strings.TrimSpace(qp.Get("requested_by")); s != "" {
+		id, err := uuid.Parse(s)
+		if err != nil {
+			return f, errors.New("invalid requested_by")
+		}
+		f.RequestedBy = &id
+	}
+
+	f.Limit = parseIntDefault(qp.Get("limit"), 50)
+	f.Offset = parseIntDefault(qp.Get("offset"), 0)
+
+	return f, nil
 }
@@ -7,6 +7,10 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
 type RoleHandler struct {
@@ -62,9 +66,149 @@ func (h *RoleHandler) Create(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "role already exists", http.StatusConflict)
 			return
 		}
+		if errors.Is(err, domain.ErrUnknownPermission) {
+			http.Error(w, "unknown permission code", http.StatusBadRequest)
+			return
+		}
+		log.Printf("error creating role: %v", err)
 		http.Error(w, "failed to create role", http.StatusInternalServerError)
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
 }
 
+func (h *RoleHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseRoleID(w, r)
+	if !ok {
+		return
+	}
+
+	role, err := h.Service.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "role not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("error getting role %d: %v", id, err)
+		http.Error(w, "error getting role", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, role)
+}
+
+type updateRoleRequest = service.UpdateRoleRequest
+
+func (h *RoleHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseRoleID(w, r)
+	if !ok {
+		return
+	}
+
+	var req updateRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("error decoding update role request: %v", err)
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.Update(r.Context(), id, req); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			http.Error(w, "role not found", http.StatusNotFound)
+		case errors.Is(err, domain.ErrInvalidInput):
+			http.Error(w, "role name is required", http.StatusBadRequest)
+		case errors.Is(err, domain.ErrRoleProtected):
+			http.Error(w, "built-in role cannot lose critical permissions", http.StatusConflict)
+		case errors.Is(err, domain.ErrUnknownPermission):
+			http.Error(w, "unknown permission code", http.StatusBadRequest)
+		default:
+			log.Printf("error updating role %d: %v", id, err)
+			http.Error(w, "error updating role", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RoleHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseRoleID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.Service.Delete(r.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			http.Error(w, "role not found", http.StatusNotFound)
+		case errors.Is(err, domain.ErrRoleProtected):
+			http.Error(w, "built-in role cannot be deleted", http.StatusConflict)
+		default:
+			log.Printf("error deleting role %d: %v", id, err)
+			http.Error(w, "error deleting role", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RoleHandler) AssignToUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+	code := chi.URLParam(r, "role")
+
+	if err := h.Service.AssignToUser(r.Context(), userID, code); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			http.Error(w, "user or role not found", http.StatusNotFound)
+			return
+		}
+		log.Printf("error assigning role %s to user %s: %v", code, userID, err)
+		http.Error(w, "error assigning role", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *RoleHandler) RevokeFromUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := parseUserID(w, r)
+	if !ok {
+		return
+	}
+	code := chi.URLParam(r, "role")
+
+	if err := h.Service.RevokeFromUser(r.Context(), userID, code); err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			http.Error(w, "user does not have this role", http.StatusNotFound)
+		case errors.Is(err, service.ErrLastRoleHolder):
+			http.Error(w, "cannot revoke role from its last active holder", http.StatusConflict)
+		default:
+			log.Printf("error revoking role %s from user %s: %v", code, userID, err)
+			http.Error(w, "error revoking role", http.StatusInternalServerError)
+		}
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func parseRoleID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil || id <= 0 {
+		http.Error(w, "invalid role id", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+func parseUserID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	idStr := chi.URLParam(r, "id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		log.Printf("error parsing user id %s: %v", idStr, err)
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return uuid.Nil, false
+	}
+	return id, true
+}
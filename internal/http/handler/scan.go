@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"elibrary/internal/domain"
+	"elibrary/internal/service"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type ScanHandler struct {
+	Service *service.ScanService
+}
+
+func NewScanHandler(service *service.ScanService) *ScanHandler {
+	return &ScanHandler{Service: service}
+}
+
+func (h *ScanHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+	code := chi.URLParam(r, "code")
+
+	res, err := h.Service.Resolve(r.Context(), code)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidBarcode):
+			http.Error(w, "invalid code", http.StatusBadRequest)
+		case errors.Is(err, domain.ErrNotFound):
+			http.Error(w, "nothing found for code", http.StatusNotFound)
+		default:
+			log.Printf("error resolving scanned code %q: %v", code, err)
+			http.Error(w, "failed to resolve code", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, res)
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"elibrary/internal/domain"
+	"elibrary/internal/http/auth"
 	"elibrary/internal/service"
 	"encoding/json"
 	"errors"
@@ -40,6 +41,10 @@ func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Roles != nil && !actorCan(r, auth.PermRolesAssign) {
+		http.Error(w, "roles.assign is required to set roles", http.StatusForbidden)
+		return
+	}
 	if strings.TrimSpace(req.Login) == "" {
 		http.Error(w, "login is empty", http.StatusBadRequest)
 		return
@@ -101,6 +106,11 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.Roles != nil && !actorCan(r, auth.PermRolesAssign) {
+		http.Error(w, "roles.assign is required to change roles", http.StatusForbidden)
+		return
+	}
+
 	if err := h.Service.Update(r.Context(), id, req); err != nil {
 		if errors.Is(err, domain.ErrNotFound) {
 			log.Printf("user %s not found: %v", id, err)
@@ -112,6 +122,10 @@ func (h *UserHandler) Update(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "login already exists", http.StatusConflict)
 			return
 		}
+		if errors.Is(err, service.ErrLastRoleHolder) {
+			http.Error(w, "cannot revoke role from its last active holder", http.StatusConflict)
+			return
+		}
 		log.Printf("error updating user %s: %v", id, err)
 		http.Error(w, "error updating user", http.StatusInternalServerError)
 		return
@@ -134,6 +148,10 @@ func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "user not found", http.StatusNotFound)
 			return
 		}
+		if errors.Is(err, service.ErrLastRoleHolder) {
+			http.Error(w, "cannot delete the last active holder of a role", http.StatusConflict)
+			return
+		}
 		log.Printf("error deleting user %s: %v", id, err)
 		http.Error(w, "error deleting user", http.StatusInternalServerError)
 		return
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"elibrary/internal/domain"
+	"elibrary/internal/http/auth"
+	httpMiddleware "elibrary/internal/http/middleware"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestUserHandlerRolesRequireAssignPermission(t *testing.T) {
+	t.Parallel()
+
+	// Service is nil: a request that reaches it would panic.
+	h := NewUserHandler(nil)
+	editor := &domain.User{Permissions: []string{auth.PermUsersCreate, auth.PermUsersUpdate}}
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		serve  http.HandlerFunc
+	}{
+		{
+			name:   "update",
+			method: http.MethodPut,
+			body:   `{"roles":[{"id":1,"code":"admin"}]}`,
+			serve:  h.Update,
+		},
+		{
+			name:   "create",
+			method: http.MethodPost,
+			body:   `{"login":"reader","first_name":"Петр","password":"secret","roles":[{"id":1,"code":"admin"}]}`,
+			serve:  h.Create,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", "550e8400-e29b-41d4-a716-446655440000")
+			ctx := context.WithValue(httpMiddleware.WithUser(context.Background(), editor), chi.RouteCtxKey, rctx)
+			req := httptest.NewRequest(tt.method, "/admin/users", strings.NewReader(tt.body)).WithContext(ctx)
+			rec := httptest.NewRecorder()
+
+			tt.serve(rec, req)
+
+			if rec.Code != http.StatusForbidden {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
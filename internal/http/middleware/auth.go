@@ -24,20 +24,19 @@ func Auth(jwt *service.JWTManager, users repository.UserRepository) func(http.Ha
 				return
 			}
 
-			userID, err := jwt.Parse(strings.TrimPrefix(h, "Bearer "))
+			userID, version, err := jwt.Parse(strings.TrimPrefix(h, "Bearer "))
 			if err != nil {
 				http.Error(w, "unauthorized", http.StatusUnauthorized)
 				return
 			}
 
 			user, err := users.GetByIDWithRoles(r.Context(), userID)
-			if err != nil || !user.IsActive {
+			if err != nil || !user.IsActive || user.TokenVersion != version {
 				http.Error(w, "unauthorized", http.StatusUnauthorized)
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), userKey, user)
-			next.ServeHTTP(w, r.WithContext(ctx))
+			next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
 		})
 	}
 }
@@ -65,6 +64,32 @@ func RequireRole(code string) func(http.Handler) http.Handler {
 	}
 }
 
+func RequirePermission(code string) func(http.Handler) http.Handler {
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			user, ok := UserFromContext(r.Context())
+			if !ok {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			if !user.HasPermission(code) {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WithUser returns a context carrying the authenticated user.
+func WithUser(ctx context.Context, user *domain.User) context.Context {
+	return context.WithValue(ctx, userKey, user)
+}
+
 func UserFromContext(ctx context.Context) (*domain.User, bool) {
 	user, ok := ctx.Value(userKey).(*domain.User)
 	return user, ok
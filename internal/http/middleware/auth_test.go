@@ -20,7 +20,13 @@ type stubUserRepo struct {
 
 func (s stubUserRepo) Create(ctx context.Context, user domain.User) error { return nil }
 func (s stubUserRepo) Update(ctx context.Context, user domain.User) error { return nil }
-func (s stubUserRepo) Delete(ctx context.Context, id uuid.UUID) error     { return nil }
+func (s stubUserRepo) UpdateProfile(ctx context.Context, id uuid.UUID, profile repository.UserProfile) error {
+	return nil
+}
+func (s stubUserRepo) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	return nil
+}
+func (s stubUserRepo) Delete(ctx context.Context, id uuid.UUID) error { return nil }
 func (s stubUserRepo) GetByLogin(ctx context.Context, login string) (*domain.User, error) {
 	return nil, nil
 }
@@ -74,7 +80,7 @@ func TestAuthRejectsInactiveUser(t *testing.T) {
 
 	jwt := &service.JWTManager{Secret: []byte("secret"), TTL: time.Minute}
 	userID := uuid.New()
-	token, err := jwt.Generate(userID)
+	token, err := jwt.Generate(userID, 0)
 	if err != nil {
 		t.Fatalf("Generate() error = %v", err)
 	}
@@ -100,12 +106,40 @@ func TestAuthRejectsInactiveUser(t *testing.T) {
 	}
 }
 
+func TestAuthRejectsRevokedTokenVersion(t *testing.T) {
+	t.Parallel()
+
+	jwt := &service.JWTManager{Secret: []byte("secret"), TTL: time.Minute}
+	userID := uuid.New()
+	token, err := jwt.Generate(userID, 1)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	handler := Auth(jwt, stubUserRepo{
+		getByIDWithRoles: func(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+			return &domain.User{ID: id, IsActive: true, TokenVersion: 2}, nil
+		},
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("next handler should not be called")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
 func TestAuthAddsUserToContext(t *testing.T) {
 	t.Parallel()
 
 	jwt := &service.JWTManager{Secret: []byte("secret"), TTL: time.Minute}
 	userID := uuid.New()
-	token, err := jwt.Generate(userID)
+	token, err := jwt.Generate(userID, 0)
 	if err != nil {
 		t.Fatalf("Generate() error = %v", err)
 	}
@@ -175,3 +209,39 @@ func TestRequireRole(t *testing.T) {
 		t.Fatalf("status with role = %d, want %d", rec.Code, http.StatusNoContent)
 	}
 }
+
+func TestRequirePermission(t *testing.T) {
+	t.Parallel()
+
+	makeHandler := func(ctx context.Context) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+		handler := RequirePermission("books.create")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := makeHandler(context.Background())
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("status without user = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	forbiddenCtx := context.WithValue(context.Background(), userKey, &domain.User{
+		Roles:       []domain.Role{{Code: "admin"}},
+		Permissions: []string{"books.view"},
+	})
+	rec = makeHandler(forbiddenCtx)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("status without permission = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+
+	allowedCtx := context.WithValue(context.Background(), userKey, &domain.User{
+		Permissions: []string{"books.view", "books.create"},
+	})
+	rec = makeHandler(allowedCtx)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status with permission = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimit allows up to limit requests per window from a single client IP,
+// refilling continuously (token bucket with burst equal to limit). Requests
+// over the limit get 429 with a Retry-After header.
+func RateLimit(limit int, window time.Duration) func(http.Handler) http.Handler {
+	l := newIPLimiter(limit, window, time.Now)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+			ok, retryAfter := l.allow(clientIP(r))
+			if !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				http.Error(w, "too many requests", http.StatusTooManyRequests)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type ipLimiter struct {
+	mu        sync.Mutex
+	burst     float64
+	perSecond float64
+	window    time.Duration
+	clients   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newIPLimiter(limit int, window time.Duration, now func() time.Time) *ipLimiter {
+	return &ipLimiter{
+		burst:     float64(limit),
+		perSecond: float64(limit) / window.Seconds(),
+		window:    window,
+		clients:   make(map[string]*bucket),
+		lastSweep: now(),
+		now:       now,
+	}
+}
+
+func (l *ipLimiter) allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.clients[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.clients[key] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.perSecond)
+	b.last = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.perSecond * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep drops clients whose buckets have been full for a whole window, so the
+// map does not grow with every address ever seen.
+func (l *ipLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < l.window {
+		return
+	}
+	for key, b := range l.clients {
+		if now.Sub(b.last) >= l.window {
+			delete(l.clients, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
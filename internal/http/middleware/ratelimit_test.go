@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIPLimiterAllow(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	l := newIPLimiter(2, time.Minute, func() time.Time { return now })
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.allow("10.0.0.1"); !ok {
+			t.Fatalf("allow() request %d = false, want true", i+1)
+		}
+	}
+	ok, retry := l.allow("10.0.0.1")
+	if ok {
+		t.Fatal("allow() over limit = true, want false")
+	}
+	if retry != 30*time.Second {
+		t.Fatalf("allow() retry = %v, want %v", retry, 30*time.Second)
+	}
+
+	if ok, _ := l.allow("10.0.0.2"); !ok {
+		t.Fatal("allow() for another client = false, want true")
+	}
+
+	now = now.Add(30 * time.Second)
+	if ok, _ := l.allow("10.0.0.1"); !ok {
+		t.Fatal("allow() after refill = false, want true")
+	}
+}
+
+func TestIPLimiterSweepsIdleClients(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(0, 0)
+	l := newIPLimiter(1, time.Minute, func() time.Time { return now })
+	l.allow("10.0.0.1")
+
+	now = now.Add(2 * time.Minute)
+	l.allow("10.0.0.2")
+
+	if _, ok := l.clients["10.0.0.1"]; ok {
+		t.Fatal("sweep() kept an idle client")
+	}
+}
+
+func TestRateLimitRespondsTooManyRequests(t *testing.T) {
+	t.Parallel()
+
+	handler := RateLimit(1, time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "192.0.2.10:5555"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve(); rec.Code != http.StatusNoContent {
+		t.Fatalf("first status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	rec := serve()
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("second status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if rec.Header().Get("Retry-After") != "60" {
+		t.Fatalf("Retry-After = %q, want %q", rec.Header().Get("Retry-After"), "60")
+	}
+}
@@ -2,12 +2,14 @@ package http
 
 import (
 	"elibrary/internal/config"
+	"elibrary/internal/domain"
 	"elibrary/internal/http/auth"
 	"elibrary/internal/http/handler"
 	"elibrary/internal/repository/postgres"
 	"elibrary/internal/service"
 	"elibrary/internal/storage/local"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
@@ -19,7 +21,7 @@ import (
 	httpMiddleware "elibrary/internal/http/middleware"
 )
 
-func NewRouter(db *pgxpool.Pool, cfg *config.Config) http.Handler {
+func NewRouter(db *pgxpool.Pool, cfg *config.Config, printTransport service.PrintTransport) http.Handler {
 	r := chi.NewRouter()
 
 	// ---------- CORS ----------
@@ -31,14 +33,29 @@ func NewRouter(db *pgxpool.Pool, cfg *config.Config) http.Handler {
 		}
 	}
 
-	r.Use(cors.Handler(cors.Options{
+	appCORS := cors.Handler(cors.Options{
 		AllowedOrigins:   cfg.CORSAllowedOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: allowCredentials,
 		MaxAge:           300,
-	}))
+	})
+
+	if cfg.PublicCatalogEnabled {
+		// The anonymous catalog is meant to be embedded anywhere, so it gets
+		// its own read-only policy without credentials.
+		publicCORS := cors.Handler(cors.Options{
+			AllowedOrigins: cfg.PublicCatalogCORSOrigins,
+			AllowedMethods: []string{"GET", "OPTIONS"},
+			AllowedHeaders: []string{"Accept", "Authorization", "Content-Type"},
+			ExposedHeaders: []string{"Retry-After"},
+			MaxAge:         300,
+		})
+		r.Use(corsByPath(publicCatalogPrefix, publicCORS, appCORS))
+	} else {
+		r.Use(appCORS)
+	}
 
 	// ---------- Base middleware ----------
 	r.Use(middleware.RequestID)
@@ -62,27 +79,52 @@ func NewRouter(db *pgxpool.Pool, cfg *config.Config) http.Handler {
 	locationRepo := postgres.NewLocationRepository(db)
 	sequenceRepo := postgres.NewSequenceRepository(db)
 	roleRepo := postgres.NewRoleRepository(db)
+	loanRepo := postgres.NewLoanRepository(db)
+	refreshTokenRepo := postgres.NewRefreshTokenRepository(db)
+	printJobRepo := postgres.NewPrintJobRepository(db)
+	barcodeBatchRepo := postgres.NewBarcodeBatchRepository(db)
 
 	imageStorage := local.NewImageStorage(cfg.ImagesPath, cfg.ImagesURL)
 
 	// ---------- Services ----------
 	jwtManager := &service.JWTManager{
 		Secret: []byte(cfg.JWTSecret),
-		TTL:    24 * time.Hour,
+		TTL:    cfg.AccessTokenTTL,
 	}
 
-	authService := service.NewAuthService(userRepo, jwtManager)
+	passwordPolicy := service.PasswordPolicy{
+		MinLength:     cfg.PasswordMinLength,
+		RequireUpper:  cfg.PasswordRequireUpper,
+		RequireLower:  cfg.PasswordRequireLower,
+		RequireDigit:  cfg.PasswordRequireDigit,
+		RequireSymbol: cfg.PasswordRequireSymbol,
+	}
+
+	authService := service.NewAuthService(userRepo, refreshTokenRepo, jwtManager, cfg.RefreshTokenTTL, passwordPolicy)
 	barcodeService := service.NewBarcodeService(sequenceRepo)
+	barcodeService.Symbologies = service.Symbologies{
+		Book:         domain.Symbology(cfg.BarcodeSymbologyBook),
+		Location:     domain.Symbology(cfg.BarcodeSymbologyLocation),
+		LocationLink: cfg.LocationLinkURL,
+	}
+	barcodeService.LowWatermark = cfg.BarcodeSequenceLowWatermark
+
+	printJobService := service.NewPrintJobService(printJobRepo, printTransport)
+	printJobService.Translit = domain.TranslitScheme(cfg.LabelTranslit)
 
-	bookService := service.NewBookService(bookRepo, bookWorksRepo, workRepo, workAuthorsRepo, barcodeService)
+	bookService := service.NewBookService(bookRepo, bookWorksRepo, workRepo, workAuthorsRepo, barcodeService, printJobService)
 	authorService := service.NewAuthorService(authorRepo)
 	workService := service.NewWorkService(workRepo)
 	publisherService := service.NewPublisherService(publisherRepo)
 	locationService := service.NewLocationService(locationRepo, barcodeService)
-	userService := service.NewUserService(userRepo)
+	scanService := service.NewScanService(bookRepo, locationRepo, barcodeService)
+	labelService := service.NewLabelService(bookRepo, locationRepo, printJobService, barcodeService)
+	barcodeBatchService := service.NewBarcodeBatchService(barcodeBatchRepo, barcodeService, printJobService)
+	userService := service.NewUserService(userRepo, roleRepo, refreshTokenRepo)
 	roleService := service.NewRoleService(roleRepo)
+	loanService := service.NewLoanService(loanRepo, userRepo)
 	imageService := service.NewImageService(imageStorage)
-	printQueue := service.NewPrintQueue(cfg.RabbitURL, cfg.RabbitQueue)
+	metadataService := service.NewMetadataService(metadataProviders(cfg), bookService, authorRepo, publisherRepo)
 
 	// ---------- Handlers ----------
 	authHandler := handler.NewAuthHandler(authService)
@@ -96,31 +138,71 @@ func NewRouter(db *pgxpool.Pool, cfg *config.Config) http.Handler {
 	locationHandler := handler.NewLocationHandler(locationService)
 	userHandler := handler.NewUserHandler(userService)
 	roleHandler := handler.NewRoleHandler(roleService)
+	loanHandler := handler.NewLoanHandler(loanService)
 	imageHandler := handler.NewImageHandler(imageService)
-	printHandler := handler.NewPrintHandler(printQueue)
+	printHandler := handler.NewPrintHandler(printJobService, labelService)
+	scanHandler := handler.NewScanHandler(scanService)
+	barcodeSequenceHandler := handler.NewBarcodeSequenceHandler(barcodeService)
+	barcodeBatchHandler := handler.NewBarcodeBatchHandler(barcodeBatchService)
+	metadataHandler := handler.NewMetadataHandler(metadataService)
+	labelHandler := handler.NewLabelHandler(labelService, barcodeBatchService, cfg.LabelSheetTemplate)
+	labelHandler.DefaultTranslit = domain.TranslitScheme(cfg.LabelTranslit)
 
 	// ---------- Public routes ----------
 	r.Get("/health", handler.Health)
 	r.Post("/auth/login", authHandler.Login)
+	r.Post("/auth/refresh", authHandler.Refresh)
+	r.Post("/auth/logout", authHandler.Logout)
+
+	// ---------- Anonymous catalog ----------
+	if cfg.PublicCatalogEnabled {
+		r.Route(publicCatalogPrefix, func(r chi.Router) {
+			if cfg.PublicCatalogTrustProxy {
+				r.Use(middleware.RealIP)
+			}
+			r.Use(httpMiddleware.RateLimit(cfg.PublicCatalogRateLimit, time.Minute))
+
+			r.Get("/", bookPublicHandler.List)
+			r.Get("/{id}", bookPublicHandler.GetByID)
+		})
+	}
 
 	// ---------- Protected routes ----------
+	can := httpMiddleware.RequirePermission
+
 	r.Route("/", func(r chi.Router) {
 		r.Use(httpMiddleware.Auth(jwtManager, userRepo))
 
+		// ---------- current user ----------
+		r.Route("/auth/me", func(r chi.Router) {
+			r.Get("/", authHandler.Me)
+			r.Put("/", authHandler.UpdateMe)
+			r.Post("/password", authHandler.ChangePassword)
+		})
+
 		// ---------- books ----------
 		r.Route("/books", func(r chi.Router) {
-			r.Route("/public", func(r chi.Router) {
-				r.Get("/", bookPublicHandler.List)
-				r.Get("/{id}", bookPublicHandler.GetByID)
-			})
+			if !cfg.PublicCatalogEnabled {
+				r.Route("/public", func(r chi.Router) {
+					r.Get("/", bookPublicHandler.List)
+					r.Get("/{id}", bookPublicHandler.GetByID)
+				})
+			}
 
 			r.Route("/internal", func(r chi.Router) {
+				r.Use(can(auth.PermBooksView))
+
 				r.Get("/", bookInternalHandler.List)
 				r.Get("/{id}", bookInternalHandler.GetByID)
-				//r.Get("/{id}/barcode", booksInternalHandler.Barcode)
+				r.Get("/{id}/movements", bookInternalHandler.Movements)
+				r.Get("/{id}/barcode", bookInternalHandler.Barcode)
+				r.Get("/{id}/barcodes", bookInternalHandler.BarcodeHistory)
 			})
 		})
 
+		// ---------- scan ----------
+		r.With(can(auth.PermBooksView)).Get("/scan/{code}", scanHandler.Resolve)
+
 		// ---------- works ----------
 		r.Route("/works", func(r chi.Router) {
 			r.Get("/{id}", workHandler.GetByID)
@@ -140,60 +222,107 @@ func NewRouter(db *pgxpool.Pool, cfg *config.Config) http.Handler {
 		r.Route("/locations", func(r chi.Router) {
 			r.Get("/type/{type}", locationHandler.GetByType)
 			r.Get("/{id}", locationHandler.GetByID)
+			r.Get("/{id}/barcode", locationHandler.Barcode)
+			r.Get("/{id}/barcodes", locationHandler.BarcodeHistory)
 			r.Get("/child/{id}/{type}", locationHandler.GetByParentID)
 		})
 
+		// ---------- loans ----------
+		r.Route("/loans", func(r chi.Router) {
+			r.With(can(auth.PermLoansManage)).Post("/", loanHandler.Checkout)
+			r.With(can(auth.PermLoansManage)).Post("/{id}/return", loanHandler.Return)
+			r.With(can(auth.PermLoansView)).Get("/user/{id}", loanHandler.GetActiveByUser)
+			r.With(can(auth.PermLoansView)).Get("/book/{id}", loanHandler.GetActiveByBook)
+		})
+
 		// ---------- admin ----------
 		r.Route("/admin", func(r chi.Router) {
-			r.Use(httpMiddleware.RequireRole(auth.RoleAdmin))
+			r.With(can(auth.PermImagesUpload)).Post("/{entity}/{id}/image", imageHandler.Upload)
+			r.With(can(auth.PermPrintSend)).Post("/print", printHandler.Send)
+			r.With(can(auth.PermPrintSend)).Post("/print/batch", printHandler.PrintBooks)
+			r.With(can(auth.PermPrintSend)).Post("/print/batch/locations", printHandler.PrintLocations)
+			r.With(can(auth.PermPrintSend)).Post("/labels/pdf", labelHandler.SheetPDF)
+
+			r.Route("/barcodes/sequences", func(r chi.Router) {
+				r.Use(can(auth.PermBarcodesManage))
+
+				r.Get("/", barcodeSequenceHandler.List)
+				r.Post("/", barcodeSequenceHandler.Create)
+				r.Post("/{prefix}/activate", barcodeSequenceHandler.Activate)
+			})
 
-			r.Post("/{entity}/{id}/image", imageHandler.Upload)
-			r.Post("/print", printHandler.Send)
+			r.Route("/barcodes/batches", func(r chi.Router) {
+				r.With(can(auth.PermBooksCreate)).Get("/", barcodeBatchHandler.List)
+				r.With(can(auth.PermBooksCreate)).Post("/", barcodeBatchHandler.Reserve)
+				r.With(can(auth.PermBooksCreate)).Get("/{id}", barcodeBatchHandler.GetByID)
+				r.With(can(auth.PermPrintSend)).Post("/{id}/print", barcodeBatchHandler.Print)
+			})
+
+			r.Route("/print/jobs", func(r chi.Router) {
+				r.With(can(auth.PermPrintSend)).Get("/", printHandler.ListJobs)
+				r.With(can(auth.PermPrintSend)).Get("/{id}", printHandler.GetJob)
+				r.With(can(auth.PermPrintSend)).Post("/{id}/retry", printHandler.RetryJob)
+			})
 
 			r.Route("/users", func(r chi.Router) {
-				r.Get("/", userHandler.GetAll)
-				r.Get("/{id}", userHandler.GetByID)
-				r.Post("/", userHandler.Create)
-				r.Put("/{id}", userHandler.Update)
-				r.Delete("/{id}", userHandler.Delete)
+				r.With(can(auth.PermUsersView)).Get("/", userHandler.GetAll)
+				r.With(can(auth.PermUsersView)).Get("/{id}", userHandler.GetByID)
+				r.With(can(auth.PermUsersCreate)).Post("/", userHandler.Create)
+				r.With(can(auth.PermUsersUpdate)).Put("/{id}", userHandler.Update)
+				r.With(can(auth.PermUsersDelete)).Delete("/{id}", userHandler.Delete)
+				r.With(can(auth.PermRolesAssign)).Put("/{id}/roles/{role}", roleHandler.AssignToUser)
+				r.With(can(auth.PermRolesAssign)).Delete("/{id}/roles/{role}", roleHandler.RevokeFromUser)
 			})
 
 			r.Route("/roles", func(r chi.Router) {
-				r.Get("/", roleHandler.GetAll)
-				r.Post("/", roleHandler.Create)
+				r.With(can(auth.PermRolesView)).Get("/", roleHandler.GetAll)
+				r.With(can(auth.PermRolesView)).Get("/{id}", roleHandler.GetByID)
+				r.With(can(auth.PermRolesManage)).Post("/", roleHandler.Create)
+				r.With(can(auth.PermRolesManage)).Put("/{id}", roleHandler.Update)
+				r.With(can(auth.PermRolesManage)).Delete("/{id}", roleHandler.Delete)
 			})
 
 			r.Route("/permissions", func(r chi.Router) {
-				r.Get("/", roleHandler.GetPermissions)
+				r.With(can(auth.PermRolesView)).Get("/", roleHandler.GetPermissions)
 			})
 
 			r.Route("/books", func(r chi.Router) {
-				r.Post("/", bookAdminHandler.Create)
-				r.Put("/{id}", bookAdminHandler.Update)
+				r.With(can(auth.PermBooksCreate)).Post("/", bookAdminHandler.Create)
+				r.With(can(auth.PermBooksCreate)).Post("/lookup", metadataHandler.Lookup)
+				r.With(can(auth.PermBooksUpdate)).Post("/move", bookAdminHandler.Move)
+				r.With(can(auth.PermBooksRestore)).Get("/trash", bookAdminHandler.Trash)
+				r.With(can(auth.PermBooksUpdate)).Put("/{id}", bookAdminHandler.Update)
+				r.With(can(auth.PermBooksUpdate)).Post("/{id}/barcode", bookAdminHandler.Relabel)
+				r.With(can(auth.PermBooksDelete)).Delete("/{id}", bookAdminHandler.Delete)
+				r.With(can(auth.PermBooksRestore)).Post("/{id}/restore", bookAdminHandler.Restore)
+				r.With(can(auth.PermBooksPurge)).Delete("/{id}/purge", bookAdminHandler.Purge)
+				r.With(can(auth.PermBooksHistory)).Get("/{id}/history", bookAdminHandler.History)
+				r.With(can(auth.PermBooksHistory)).Get("/{id}/history/diff", bookAdminHandler.Diff)
 			})
 
 			r.Route("/works", func(r chi.Router) {
-				r.Post("/", workHandler.Create)
-				r.Put("/{id}", workHandler.Update)
-				r.Delete("/{id}", workHandler.Delete)
+				r.With(can(auth.PermWorksCreate)).Post("/", workHandler.Create)
+				r.With(can(auth.PermWorksUpdate)).Put("/{id}", workHandler.Update)
+				r.With(can(auth.PermWorksDelete)).Delete("/{id}", workHandler.Delete)
 			})
 
 			r.Route("/authors", func(r chi.Router) {
-				r.Post("/", authorHandler.Create)
-				r.Put("/{id}", authorHandler.Update)
-				r.Delete("/{id}", authorHandler.Delete)
+				r.With(can(auth.PermAuthorsCreate)).Post("/", authorHandler.Create)
+				r.With(can(auth.PermAuthorsUpdate)).Put("/{id}", authorHandler.Update)
+				r.With(can(auth.PermAuthorsDelete)).Delete("/{id}", authorHandler.Delete)
 			})
 
 			r.Route("/publishers", func(r chi.Router) {
-				r.Post("/", publisherHandler.Create)
-				r.Put("/{id}", publisherHandler.Update)
-				r.Delete("/{id}", publisherHandler.Delete)
+				r.With(can(auth.PermPublishersCreate)).Post("/", publisherHandler.Create)
+				r.With(can(auth.PermPublishersUpdate)).Put("/{id}", publisherHandler.Update)
+				r.With(can(auth.PermPublishersDelete)).Delete("/{id}", publisherHandler.Delete)
 			})
 
 			r.Route("/locations", func(r chi.Router) {
-				r.Post("/", locationHandler.Create)
-				r.Put("/{id}", locationHandler.Update)
-				r.Delete("/{id}", locationHandler.Delete)
+				r.With(can(auth.PermLocationsCreate)).Post("/", locationHandler.Create)
+				r.With(can(auth.PermLocationsUpdate)).Put("/{id}", locationHandler.Update)
+				r.With(can(auth.PermLocationsUpdate)).Post("/{id}/barcode", locationHandler.Relabel)
+				r.With(can(auth.PermLocationsDelete)).Delete("/{id}", locationHandler.Delete)
 			})
 		})
 
@@ -207,3 +336,37 @@ func NewRouter(db *pgxpool.Pool, cfg *config.Config) http.Handler {
 
 	return r
 }
+
+const publicCatalogPrefix = "/books/public"
+
+// corsByPath applies the public CORS policy to requests under prefix and the
+// application policy to everything else.
+func corsByPath(prefix string, public, app func(http.Handler) http.Handler) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		publicNext := public(next)
+		appNext := app(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == prefix || strings.HasPrefix(r.URL.Path, prefix+"/") {
+				publicNext.ServeHTTP(w, r)
+				return
+			}
+			appNext.ServeHTTP(w, r)
+		})
+	}
+}
+
+// metadataProviders builds the external catalogs in the configured order.
+func metadataProviders(cfg *config.Config) []service.MetadataProvider {
+	client := &http.Client{Timeout: cfg.MetadataTimeout}
+
+	var providers []service.MetadataProvider
+	for _, name := range cfg.MetadataProviders {
+		switch strings.ToLower(name) {
+		case "openlibrary":
+			providers = append(providers, service.NewOpenLibraryProvider(cfg.OpenLibraryURL, client))
+		case "googlebooks":
+			providers = append(providers, service.NewGoogleBooksProvider(cfg.GoogleBooksURL, cfg.GoogleBooksAPIKey, client))
+		}
+	}
+	return providers
+}
@@ -0,0 +1,92 @@
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"unicode"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/unicode/norm"
+)
+
+// ESCPOS renders labels for ESC/POS receipt and label printers using the
+// GS v 0 raster command for the barcode image. Text is sent in code page
+// 866, so Cyrillic prints as is with TranslitNone.
+type ESCPOS struct{}
+
+// escposCP866 is the ESC t number of PC866 (Cyrillic #2) in the Epson
+// command set.
+const escposCP866 = 17
+
+func (ESCPOS) Ext() string { return "bin" }
+
+func (ESCPOS) Encode(label Label) ([]byte, error) {
+	if label.Image == nil {
+		return nil, fmt.Errorf("label %s has no barcode image", label.Barcode)
+	}
+	bm := newBitmap(label.Image)
+	if bm.rowBytes > 0xFFFF || bm.height > 0xFFFF {
+		return nil, fmt.Errorf("barcode image %dx%d is too large", bm.rowBytes*8, bm.height)
+	}
+
+	var buf bytes.Buffer
+	buf.Write([]byte{0x1B, 0x40})              // ESC @: initialize
+	buf.Write([]byte{0x1B, 0x74, escposCP866}) // ESC t n: code page
+	buf.Write([]byte{0x1B, 0x61, 0x01})        // ESC a 1: center
+	buf.Write(escposText(label.Str1))
+	buf.WriteByte('\n')
+	buf.Write(escposText(label.Str2))
+	buf.WriteByte('\n')
+
+	// GS v 0 m xL xH yL yH d1...dk
+	buf.Write([]byte{0x1D, 0x76, 0x30, 0x00,
+		byte(bm.rowBytes), byte(bm.rowBytes >> 8),
+		byte(bm.height), byte(bm.height >> 8),
+	})
+	buf.Write(bm.data)
+
+	buf.WriteString("\n\n\n")
+	buf.Write([]byte{0x1D, 0x56, 0x42, 0x00}) // GS V 66 0: feed and partial cut
+	return buf.Bytes(), nil
+}
+
+// escposText drops control characters so that text cannot inject commands
+// and encodes the rest in CP866. Letters the code page lacks, such as the
+// ISO 9 "ž", are printed without their diacritics; anything else that does
+// not fit becomes "?".
+func escposText(s string) []byte {
+	out := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r < 0x20 || r == 0x7F {
+			continue
+		}
+		if b, ok := charmap.CodePage866.EncodeRune(r); ok {
+			out = append(out, b)
+			continue
+		}
+		out = append(out, escposFallback(r)...)
+	}
+	return out
+}
+
+func escposFallback(r rune) []byte {
+	switch r {
+	case 'ʹ':
+		return []byte{'\''}
+	case 'ʺ':
+		return []byte{'"'}
+	}
+
+	var out []byte
+	for _, d := range norm.NFD.String(string(r)) {
+		if unicode.Is(unicode.Mn, d) {
+			continue
+		}
+		b, ok := charmap.CodePage866.EncodeRune(d)
+		if !ok {
+			return []byte{'?'}
+		}
+		out = append(out, b)
+	}
+	return out
+}
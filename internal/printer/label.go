@@ -0,0 +1,80 @@
+// Package printer turns print tasks into printer-native label data and
+// delivers it to a physical printer or a spool directory.
+package printer
+
+import (
+	"bytes"
+	"elibrary/internal/domain"
+	"fmt"
+	"image"
+	"image/png"
+)
+
+// Label is a rendered print task: two text lines and a barcode picture.
+type Label struct {
+	Str1    string
+	Str2    string
+	Barcode string
+	Image   image.Image
+	// Symbology and Data describe the symbol for renderers that draw it
+	// themselves instead of using Image, see service.PrintTask.
+	Symbology domain.Symbology
+	Data      string
+}
+
+// Encoder converts a label into the byte stream understood by a printer.
+type Encoder interface {
+	Encode(label Label) ([]byte, error)
+	// Ext is the file extension used when the output is spooled to disk.
+	Ext() string
+}
+
+// NewEncoder returns the encoder for a format name: "zpl" or "escpos".
+func NewEncoder(format string) (Encoder, error) {
+	switch format {
+	case "zpl":
+		return ZPL{}, nil
+	case "escpos":
+		return ESCPOS{}, nil
+	default:
+		return nil, fmt.Errorf("unknown printer format %q", format)
+	}
+}
+
+// DecodePNG decodes the barcode picture produced by BarcodeService.
+func DecodePNG(data []byte) (image.Image, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode barcode image: %w", err)
+	}
+	return img, nil
+}
+
+// bitmap is a 1-bit packed raster, most significant bit first, 1 = black.
+type bitmap struct {
+	rowBytes int
+	height   int
+	data     []byte
+}
+
+func newBitmap(img image.Image) bitmap {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	rowBytes := (width + 7) / 8
+
+	bm := bitmap{rowBytes: rowBytes, height: height, data: make([]byte, rowBytes*height)}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, g, b, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			if a < 0x8000 {
+				continue
+			}
+			// ITU-R BT.601 luma on 16-bit channels.
+			luma := (299*r + 587*g + 114*b) / 1000
+			if luma < 0x8000 {
+				bm.data[y*rowBytes+x/8] |= 0x80 >> uint(x%8)
+			}
+		}
+	}
+	return bm
+}
@@ -0,0 +1,132 @@
+package printer
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// mmToPt converts millimetres to PDF points.
+const mmToPt = 72 / 25.4
+
+// pdfDocument is a minimal PDF 1.4 writer: equally sized pages whose content
+// streams may use one font, Helvetica, as /F1. Text is encoded as WinAnsi,
+// so it must be transliterated to Latin first.
+type pdfDocument struct {
+	width  float64
+	height float64
+	pages  []*bytes.Buffer
+}
+
+func newPDFDocument(widthPt, heightPt float64) *pdfDocument {
+	return &pdfDocument{width: widthPt, height: heightPt}
+}
+
+func (d *pdfDocument) addPage() *pdfPage {
+	buf := &bytes.Buffer{}
+	d.pages = append(d.pages, buf)
+	return &pdfPage{buf: buf}
+}
+
+func (d *pdfDocument) bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+
+	obj := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// Objects 1-3 are fixed; every page then takes a page object and a
+	// content stream object.
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	obj("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		obj(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfNum(d.width), pdfNum(d.height), 5+2*i,
+		))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", page.Len(), page.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes()
+}
+
+// pdfPage collects the drawing operators of one page. Coordinates are in
+// points from the bottom-left corner.
+type pdfPage struct {
+	buf *bytes.Buffer
+}
+
+func (p *pdfPage) rect(x, y, w, h float64) {
+	fmt.Fprintf(p.buf, "%s %s %s %s re f\n", pdfNum(x), pdfNum(y), pdfNum(w), pdfNum(h))
+}
+
+func (p *pdfPage) text(x, y, size float64, s string) {
+	fmt.Fprintf(p.buf, "BT /F1 %s Tf %s %s Td (%s) Tj ET\n", pdfNum(size), pdfNum(x), pdfNum(y), pdfString(s))
+}
+
+// clip restricts drawing to a rectangle until restore is called.
+func (p *pdfPage) clip(x, y, w, h float64) {
+	fmt.Fprintf(p.buf, "q %s %s %s %s re W n\n", pdfNum(x), pdfNum(y), pdfNum(w), pdfNum(h))
+}
+
+func (p *pdfPage) restore() {
+	p.buf.WriteString("Q\n")
+}
+
+func pdfNum(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// pdfFallback spells transliterated letters missing from WinAnsi without
+// their diacritics.
+var pdfFallback = map[rune]byte{
+	'č': 'c', 'Č': 'C', 'š': 's', 'Š': 'S', 'ž': 'z', 'Ž': 'Z',
+	'ŝ': 's', 'Ŝ': 'S', 'ŭ': 'u', 'Ŭ': 'U', 'ġ': 'g', 'Ġ': 'G',
+	'ķ': 'k', 'Ķ': 'K', 'ṇ': 'n', 'Ṇ': 'N', 'ḥ': 'h', 'Ḥ': 'H',
+	'ū': 'u', 'Ū': 'U', 'ʹ': '\'', 'ʺ': '"',
+}
+
+// pdfString escapes s for a literal string. Characters outside Latin-1
+// cannot be shown by the standard font and become '?' unless pdfFallback
+// has a plain spelling for them.
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20:
+			b.WriteByte(' ')
+		case r >= 0x300 && r <= 0x36f:
+			// Combining marks of ISO 9 letters such as g̀ are dropped.
+		case r > 0xff:
+			if v, ok := pdfFallback[r]; ok {
+				b.WriteByte(v)
+			} else {
+				b.WriteByte('?')
+			}
+		default:
+			b.WriteByte(byte(r))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,564 @@
+package printer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+	"elibrary/internal/service"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func checkerImage() image.Image {
+	img := image.NewGray(image.Rect(0, 0, 10, 2))
+	for x := 0; x < 10; x++ {
+		c := color.Gray{Y: 255}
+		if x%2 == 0 {
+			c = color.Gray{Y: 0}
+		}
+		img.SetGray(x, 0, c)
+		img.SetGray(x, 1, color.Gray{Y: 255})
+	}
+	return img
+}
+
+func TestNewBitmap(t *testing.T) {
+	t.Parallel()
+
+	bm := newBitmap(checkerImage())
+	if bm.rowBytes != 2 || bm.height != 2 {
+		t.Fatalf("bitmap size = %dx%d, want 2x2 bytes", bm.rowBytes, bm.height)
+	}
+	want := []byte{0xAA, 0x80, 0x00, 0x00}
+	if !bytes.Equal(bm.data, want) {
+		t.Fatalf("bitmap data = % X, want % X", bm.data, want)
+	}
+}
+
+func TestZPLEncode(t *testing.T) {
+	t.Parallel()
+
+	data, err := ZPL{}.Encode(Label{Str1: "Voyna^i mir", Str2: "Shelf 1", Barcode: "x", Image: checkerImage()})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	out := string(data)
+	for _, want := range []string{"^XA", "^FDVoyna i mir^FS", "^FDShelf 1^FS", "^GFA,4,4,2,AA800000^FS", "^XZ"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("Encode() output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestESCPOSEncode(t *testing.T) {
+	t.Parallel()
+
+	data, err := ESCPOS{}.Encode(Label{Str1: "Title\x1b", Str2: "Shelf", Image: checkerImage()})
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	if !bytes.HasPrefix(data, []byte{0x1B, 0x40}) {
+		t.Fatalf("Encode() does not start with ESC @: % X", data[:2])
+	}
+	if !bytes.Contains(data, []byte("Title\nShelf\n")) {
+		t.Fatalf("Encode() text lines missing or not sanitized: %q", data)
+	}
+	raster := []byte{0x1D, 0x76, 0x30, 0x00, 2, 0, 2, 0, 0xAA, 0x80, 0x00, 0x00}
+	if !bytes.Contains(data, raster) {
+		t.Fatalf("Encode() raster block missing: % X", data)
+	}
+}
+
+func TestESCPOSEncodeText(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want []byte
+	}{
+		{name: "ascii", in: "Tolstoj", want: []byte("Tolstoj")},
+		{name: "cyrillic", in: "Толстой Ёж", want: []byte{0x92, 0xAE, 0xAB, 0xE1, 0xE2, 0xAE, 0xA9, ' ', 0xF0, 0xA6}},
+		{name: "iso9 diacritics", in: "Žukovskij Čërnyj", want: []byte("Zukovskij Cernyj")},
+		{name: "bgn", in: "Ob·yëm Sъ", want: []byte{'O', 'b', 0xFA, 'y', 'e', 'm', ' ', 'S', 0xEA}},
+		{name: "primes", in: "Obʺëm sʹ", want: []byte("Ob\"em s'")},
+		{name: "unsupported", in: "A€B", want: []byte("A?B")},
+	}
+
+	for _, tt := range tests {
+		data, err := ESCPOS{}.Encode(Label{Str1: tt.in, Image: checkerImage()})
+		if err != nil {
+			t.Fatalf("%s: Encode() error = %v", tt.name, err)
+		}
+		if !bytes.Contains(data, []byte{0x1B, 0x74, escposCP866}) {
+			t.Fatalf("%s: Encode() does not select CP866: % X", tt.name, data)
+		}
+		if !bytes.Contains(data, append(tt.want, '\n')) {
+			t.Fatalf("%s: Encode() text = % X, want % X", tt.name, data, tt.want)
+		}
+	}
+}
+
+func TestSpoolSinkSend(t *testing.T) {
+	t.Parallel()
+
+	dir := filepath.Join(t.TempDir(), "spool")
+	if err := (SpoolSink{Dir: dir}).Send(context.Background(), "label.zpl", []byte("^XA^XZ")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "label.zpl" {
+		t.Fatalf("spool entries = %v, want only label.zpl", entries)
+	}
+}
+
+func TestTCPSinkSend(t *testing.T) {
+	t.Parallel()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer ln.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		received <- data
+	}()
+
+	sink := TCPSink{Addr: ln.Addr().String(), Timeout: time.Second}
+	if err := sink.Send(context.Background(), "label", []byte("^XA^XZ")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got := <-received; string(got) != "^XA^XZ" {
+		t.Fatalf("printer received %q, want %q", got, "^XA^XZ")
+	}
+}
+
+type fakeAcknowledger struct {
+	acked   bool
+	nacked  bool
+	requeue bool
+}
+
+func (a *fakeAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.acked = true
+	return nil
+}
+func (a *fakeAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	a.nacked, a.requeue = true, requeue
+	return nil
+}
+func (a *fakeAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+type stubSink struct {
+	err  error
+	sent []string
+}
+
+func (s *stubSink) Send(ctx context.Context, name string, data []byte) error {
+	s.sent = append(s.sent, name)
+	return s.err
+}
+
+type stubReporter struct {
+	replyTo  string
+	statuses []service.PrintStatus
+}
+
+func (r *stubReporter) Report(ctx context.Context, replyTo string, status service.PrintStatus) error {
+	r.replyTo = replyTo
+	r.statuses = append(r.statuses, status)
+	return nil
+}
+
+func TestWorkerHandle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		body        string
+		sinkErr     error
+		wantAck     bool
+		wantRequeue bool
+		wantStatus  domain.PrintJobStatus
+	}{
+		{name: "printed", body: `{"str1":"Книга","str2":"Полка","barcode":"4006381333931"}`, wantAck: true, wantStatus: domain.PrintJobDone},
+		{name: "malformed json", body: `{`, wantStatus: domain.PrintJobFailed},
+		{name: "invalid barcode", body: `{"barcode":"123"}`, wantStatus: domain.PrintJobFailed},
+		{name: "qr link", body: `{"str1":"Shelf","barcode":"4006381333931","symbology":"qr","data":"https://library.example/l/1"}`, wantAck: true, wantStatus: domain.PrintJobDone},
+		{name: "unknown symbology", body: `{"barcode":"4006381333931","symbology":"pdf417"}`, wantStatus: domain.PrintJobFailed},
+		{name: "printer offline", body: `{"barcode":"4006381333931"}`, sinkErr: errors.New("connection refused"), wantRequeue: true, wantStatus: domain.PrintJobQueued},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sink := &stubSink{err: tt.sinkErr}
+			w := &Worker{
+				Barcodes: service.NewBarcodeService(nil),
+				Encoder:  ZPL{},
+				Sink:     sink,
+			}
+			ack := &fakeAcknowledger{}
+			reporter := &stubReporter{}
+			jobID := uuid.New()
+			w.Handle(context.Background(), amqp.Delivery{
+				Acknowledger:  ack,
+				Body:          []byte(tt.body),
+				ReplyTo:       "print_status",
+				CorrelationId: jobID.String(),
+			}, reporter)
+
+			if ack.acked != tt.wantAck {
+				t.Fatalf("acked = %v, want %v", ack.acked, tt.wantAck)
+			}
+			if !tt.wantAck && (!ack.nacked || ack.requeue != tt.wantRequeue) {
+				t.Fatalf("nack = %v requeue = %v, want requeue %v", ack.nacked, ack.requeue, tt.wantRequeue)
+			}
+			if reporter.replyTo != "print_status" || len(reporter.statuses) != 2 {
+				t.Fatalf("reported %+v to %q, want printing and final status", reporter.statuses, reporter.replyTo)
+			}
+			if first, last := reporter.statuses[0], reporter.statuses[1]; first.Status != domain.PrintJobPrinting || last.Status != tt.wantStatus || last.JobID != jobID {
+				t.Fatalf("statuses = %+v, want printing then %s for %v", reporter.statuses, tt.wantStatus, jobID)
+			}
+			if tt.wantAck && !strings.HasSuffix(sink.sent[0], "-4006381333931.zpl") {
+				t.Fatalf("spool name = %q, want barcode and extension", sink.sent[0])
+			}
+		})
+	}
+}
+
+type memJobs struct {
+	queue   []*domain.PrintJob
+	status  map[uuid.UUID]domain.PrintJobStatus
+	errMsgs map[uuid.UUID]string
+	retryAt map[uuid.UUID]time.Time
+	claimed []*domain.PrintJob
+}
+
+func (m *memJobs) GetByID(ctx context.Context, id uuid.UUID) (*domain.PrintJob, error) {
+	return nil, repository.ErrNotFound
+}
+func (m *memJobs) List(ctx context.Context, filter repository.PrintJobFilter) ([]*domain.PrintJob, error) {
+	return nil, repository.ErrNotFound
+}
+func (m *memJobs) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.PrintJobStatus, errMsg *string) error {
+	m.status[id] = status
+	if errMsg != nil {
+		m.errMsgs[id] = *errMsg
+	}
+	return nil
+}
+func (m *memJobs) ClaimNext(ctx context.Context) (*domain.PrintJob, error) {
+	now := time.Now()
+	for i, job := range m.queue {
+		if m.retryAt[job.ID].After(now) {
+			continue
+		}
+		m.queue = append(m.queue[:i:i], m.queue[i+1:]...)
+		job.ClaimedAt = &now
+		m.status[job.ID] = domain.PrintJobPrinting
+		m.claimed = append(m.claimed, job)
+		return job, nil
+	}
+	return nil, repository.ErrNotFound
+}
+func (m *memJobs) StaleClaims(ctx context.Context, claimedBefore time.Time) ([]*domain.PrintJob, error) {
+	var stale []*domain.PrintJob
+	for _, job := range m.claimed {
+		if m.status[job.ID] == domain.PrintJobPrinting && job.ClaimedAt.Before(claimedBefore) {
+			cp := *job
+			stale = append(stale, &cp)
+		}
+	}
+	return stale, nil
+}
+func (m *memJobs) holds(id uuid.UUID, claimedAt time.Time) *domain.PrintJob {
+	for _, job := range m.claimed {
+		if job.ID == id && m.status[id] == domain.PrintJobPrinting && job.ClaimedAt.Equal(claimedAt) {
+			return job
+		}
+	}
+	return nil
+}
+func (m *memJobs) FinishClaim(ctx context.Context, id uuid.UUID, claimedAt time.Time, status domain.PrintJobStatus, errMsg *string) error {
+	if m.holds(id, claimedAt) == nil {
+		return repository.ErrNotFound
+	}
+	return m.UpdateStatus(ctx, id, status, errMsg)
+}
+func (m *memJobs) RequeueClaim(ctx context.Context, id uuid.UUID, claimedAt time.Time, errMsg *string, retryAt time.Time) error {
+	job := m.holds(id, claimedAt)
+	if job == nil {
+		return repository.ErrNotFound
+	}
+	job.Attempts++
+	m.retryAt[id] = retryAt
+	m.queue = append(m.queue, job)
+	return m.UpdateStatus(ctx, id, domain.PrintJobQueued, errMsg)
+}
+func (m *memJobs) WithTx(ctx context.Context, fn func(tx repository.PrintJobTx) error) error {
+	return errors.New("not supported")
+}
+
+func TestTableConsumerProcessNext(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		payload  string
+		attempts int
+		sinkErr  error
+		want     domain.PrintJobStatus
+	}{
+		{name: "printed", payload: `{"barcode":"4006381333931"}`, attempts: 1, want: domain.PrintJobDone},
+		{name: "invalid barcode", payload: `{"barcode":"123"}`, attempts: 1, want: domain.PrintJobFailed},
+		{name: "printer offline", payload: `{"barcode":"4006381333931"}`, attempts: 1, sinkErr: errors.New("connection refused"), want: domain.PrintJobQueued},
+		{name: "printer offline on last attempt", payload: `{"barcode":"4006381333931"}`, attempts: 3, sinkErr: errors.New("connection refused"), want: domain.PrintJobFailed},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			job := &domain.PrintJob{ID: uuid.New(), Payload: []byte(tt.payload), Attempts: tt.attempts}
+			jobs := &memJobs{
+				queue:   []*domain.PrintJob{job},
+				status:  map[uuid.UUID]domain.PrintJobStatus{},
+				errMsgs: map[uuid.UUID]string{},
+				retryAt: map[uuid.UUID]time.Time{},
+			}
+			worker := &Worker{Barcodes: service.NewBarcodeService(nil), Encoder: ZPL{}, Sink: &stubSink{err: tt.sinkErr}, RetryDelay: time.Minute}
+			consumer := NewTableConsumer(jobs, worker, time.Second)
+			consumer.MaxAttempts = 3
+
+			printed, err := consumer.ProcessNext(context.Background())
+			if err != nil || !printed {
+				t.Fatalf("ProcessNext() = %v, %v, want true, nil", printed, err)
+			}
+			if got := jobs.status[job.ID]; got != tt.want {
+				t.Fatalf("status = %q, want %q", got, tt.want)
+			}
+			if tt.want != domain.PrintJobDone && jobs.errMsgs[job.ID] == "" {
+				t.Fatal("error message was not stored")
+			}
+
+			if tt.want == domain.PrintJobQueued && job.Attempts != tt.attempts+1 {
+				t.Fatalf("attempts = %d, want %d", job.Attempts, tt.attempts+1)
+			}
+
+			// A requeued job waits for RetryDelay instead of being claimed
+			// again straight away.
+			printed, err = consumer.ProcessNext(context.Background())
+			if err != nil || printed {
+				t.Fatalf("ProcessNext() on empty queue = %v, %v, want false, nil", printed, err)
+			}
+		})
+	}
+}
+
+func TestTableConsumerRecoverStale(t *testing.T) {
+	t.Parallel()
+
+	hourAgo := time.Now().Add(-time.Hour)
+	abandoned := &domain.PrintJob{ID: uuid.New(), Payload: []byte(`{"barcode":"4006381333931"}`), Attempts: 1, ClaimedAt: &hourAgo}
+	poison := &domain.PrintJob{ID: uuid.New(), Payload: []byte(`{"barcode":"4006381333931"}`), Attempts: 3, ClaimedAt: &hourAgo}
+	jobs := &memJobs{
+		status: map[uuid.UUID]domain.PrintJobStatus{
+			abandoned.ID: domain.PrintJobPrinting,
+			poison.ID:    domain.PrintJobPrinting,
+		},
+		errMsgs: map[uuid.UUID]string{},
+		retryAt: map[uuid.UUID]time.Time{},
+		claimed: []*domain.PrintJob{abandoned, poison},
+	}
+	worker := &Worker{Barcodes: service.NewBarcodeService(nil), Encoder: ZPL{}, Sink: &stubSink{}}
+	consumer := NewTableConsumer(jobs, worker, time.Second)
+	consumer.ClaimTimeout, consumer.MaxAttempts = time.Minute, 3
+
+	// A job that is being printed right now is left alone.
+	fresh := &domain.PrintJob{ID: uuid.New(), Payload: []byte(`{"barcode":"4006381333931"}`), Attempts: 1}
+	jobs.queue = []*domain.PrintJob{fresh}
+	if _, err := jobs.ClaimNext(context.Background()); err != nil {
+		t.Fatalf("ClaimNext() error = %v", err)
+	}
+
+	if err := consumer.RecoverStale(context.Background()); err != nil {
+		t.Fatalf("RecoverStale() error = %v", err)
+	}
+	if got := jobs.status[abandoned.ID]; got != domain.PrintJobQueued || abandoned.Attempts != 2 {
+		t.Fatalf("abandoned job = %q after %d attempts, want queued after 2", got, abandoned.Attempts)
+	}
+	if got := jobs.status[poison.ID]; got != domain.PrintJobFailed || jobs.errMsgs[poison.ID] == "" {
+		t.Fatalf("poison job = %q with error %q, want failed with an error", got, jobs.errMsgs[poison.ID])
+	}
+	if got := jobs.status[fresh.ID]; got != domain.PrintJobPrinting {
+		t.Fatalf("fresh job = %q, want printing", got)
+	}
+
+	// The consumer that abandoned the job no longer holds its claim.
+	if err := jobs.FinishClaim(context.Background(), poison.ID, hourAgo, domain.PrintJobDone, nil); !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("FinishClaim() after recovery error = %v, want %v", err, repository.ErrNotFound)
+	}
+	if got := jobs.status[poison.ID]; got != domain.PrintJobFailed {
+		t.Fatalf("poison job = %q after a late finish, want failed", got)
+	}
+
+	printed, err := consumer.ProcessNext(context.Background())
+	if err != nil || !printed {
+		t.Fatalf("ProcessNext() = %v, %v, want true, nil", printed, err)
+	}
+	if got := jobs.status[abandoned.ID]; got != domain.PrintJobDone {
+		t.Fatalf("requeued job = %q, want done", got)
+	}
+}
+
+func TestSheetTemplatesValid(t *testing.T) {
+	t.Parallel()
+
+	for name, tmpl := range SheetTemplates {
+		if err := tmpl.Validate(); err != nil {
+			t.Errorf("template %s: Validate() error = %v, want nil", name, err)
+		}
+	}
+}
+
+func TestSheetTemplateValidate(t *testing.T) {
+	t.Parallel()
+
+	base := SheetTemplates["a4-3x8"]
+	tests := []struct {
+		name   string
+		modify func(*SheetTemplate)
+	}{
+		{name: "no columns", modify: func(t *SheetTemplate) { t.Columns = 0 }},
+		{name: "negative margin", modify: func(t *SheetTemplate) { t.MarginLeft = -1 }},
+		{name: "too wide", modify: func(t *SheetTemplate) { t.Columns = 4 }},
+		{name: "too tall", modify: func(t *SheetTemplate) { t.GapY = 5 }},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tmpl := base
+			tt.modify(&tmpl)
+			if err := tmpl.Validate(); !errors.Is(err, ErrInvalidTemplate) {
+				t.Fatalf("Validate() error = %v, want %v", err, ErrInvalidTemplate)
+			}
+		})
+	}
+}
+
+func TestRenderSheet(t *testing.T) {
+	t.Parallel()
+
+	tmpl := SheetTemplates["a4-3x8"]
+	labels := make([]Label, 30)
+	for i := range labels {
+		labels[i] = Label{Str1: "Title (1)", Str2: "Cabinet / Shelf", Barcode: "2000000000015"}
+	}
+
+	// 30 labels starting at cell 20 need the rest of the first page and two more.
+	pdf, err := RenderSheet(tmpl, labels, 20)
+	if err != nil {
+		t.Fatalf("RenderSheet() error = %v", err)
+	}
+
+	out := string(pdf)
+	if !strings.HasPrefix(out, "%PDF-1.4") || !strings.HasSuffix(out, "%%EOF\n") {
+		t.Fatalf("RenderSheet() output is not a PDF document")
+	}
+	if !strings.Contains(out, "/Count 3") {
+		t.Errorf("RenderSheet() output has no /Count 3 page tree")
+	}
+	if got := strings.Count(out, "(2000000000015) Tj"); got != len(labels) {
+		t.Errorf("barcode digits drawn %d times, want %d", got, len(labels))
+	}
+	// An EAN-13 symbol has 30 bars.
+	if got := strings.Count(out, " re f\n"); got != 30*len(labels) {
+		t.Errorf("bars drawn = %d, want %d", got, 30*len(labels))
+	}
+	if !strings.Contains(out, `(Title \(1\)) Tj`) {
+		t.Errorf("title is not escaped in the content stream")
+	}
+
+	// The first label goes into the third column of the seventh row.
+	x := 2 * 70 * mmToPt
+	y := (297 - 0.5 - 7*37) * mmToPt
+	clip := "q " + pdfNum(x) + " " + pdfNum(y) + " "
+	if !strings.Contains(out, clip) {
+		t.Errorf("first label is not clipped at %s", clip)
+	}
+}
+
+func TestRenderSheetMatrix(t *testing.T) {
+	t.Parallel()
+
+	labels := []Label{{Str1: "Shelf", Barcode: "2100000000042", Symbology: domain.SymbologyQR, Data: "https://library.example/l/1"}}
+	pdf, err := RenderSheet(SheetTemplates["a4-3x8"], labels, 0)
+	if err != nil {
+		t.Fatalf("RenderSheet() error = %v", err)
+	}
+	// A QR code has many more dark runs than the 30 bars of an EAN-13.
+	if got := strings.Count(string(pdf), " re f\n"); got <= 30 {
+		t.Fatalf("QR label has %d dark runs, want a matrix", got)
+	}
+	if !strings.Contains(string(pdf), "(2100000000042) Tj") {
+		t.Fatal("QR label lost the human-readable barcode")
+	}
+}
+
+func TestRenderSheetErrors(t *testing.T) {
+	t.Parallel()
+
+	tmpl := SheetTemplates["a4-3x8"]
+	if _, err := RenderSheet(tmpl, nil, tmpl.PerPage()); !errors.Is(err, ErrInvalidTemplate) {
+		t.Fatalf("RenderSheet() error = %v, want %v", err, ErrInvalidTemplate)
+	}
+
+	_, err := RenderSheet(tmpl, []Label{{Str1: "x", Barcode: "123"}}, 0)
+	if !errors.Is(err, ErrPermanent) {
+		t.Fatalf("RenderSheet() error = %v, want %v", err, ErrPermanent)
+	}
+}
+
+func TestPDFString(t *testing.T) {
+	t.Parallel()
+
+	if got, want := pdfString(`a(b)\c`+"\n"+"ж"), `a\(b\)\\c ?`; got != want {
+		t.Fatalf("pdfString() = %q, want %q", got, want)
+	}
+	if got, want := pdfString("Ŝuka g̀ ë"), "Suka g \xeb"; got != want {
+		t.Fatalf("pdfString() = %q, want %q", got, want)
+	}
+}
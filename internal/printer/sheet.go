@@ -0,0 +1,237 @@
+package printer
+
+import (
+	"elibrary/internal/domain"
+	"elibrary/internal/service"
+	"errors"
+	"fmt"
+	"image/color"
+	"sort"
+
+	"github.com/boombuler/barcode"
+)
+
+// SheetTemplate describes a sheet of sticker labels. All lengths are in
+// millimetres; labels are filled row by row from the top-left corner.
+type SheetTemplate struct {
+	Name        string  `json:"name,omitempty"`
+	PageWidth   float64 `json:"page_width"`
+	PageHeight  float64 `json:"page_height"`
+	MarginTop   float64 `json:"margin_top"`
+	MarginLeft  float64 `json:"margin_left"`
+	Columns     int     `json:"columns"`
+	Rows        int     `json:"rows"`
+	LabelWidth  float64 `json:"label_width"`
+	LabelHeight float64 `json:"label_height"`
+	GapX        float64 `json:"gap_x"`
+	GapY        float64 `json:"gap_y"`
+}
+
+// SheetTemplates are the common A4 sticker sheets.
+var SheetTemplates = map[string]SheetTemplate{
+	"a4-3x7": {
+		Name: "a4-3x7", PageWidth: 210, PageHeight: 297,
+		MarginTop: 0.45, Columns: 3, Rows: 7, LabelWidth: 70, LabelHeight: 42.3,
+	},
+	"a4-3x8": {
+		Name: "a4-3x8", PageWidth: 210, PageHeight: 297,
+		MarginTop: 0.5, Columns: 3, Rows: 8, LabelWidth: 70, LabelHeight: 37,
+	},
+	"a4-4x10": {
+		Name: "a4-4x10", PageWidth: 210, PageHeight: 297,
+		MarginTop: 21.5, MarginLeft: 8, Columns: 4, Rows: 10, LabelWidth: 48.5, LabelHeight: 25.4,
+	},
+}
+
+// SheetTemplateNames lists the built-in templates in a stable order.
+func SheetTemplateNames() []string {
+	names := make([]string, 0, len(SheetTemplates))
+	for name := range SheetTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var ErrInvalidTemplate = errors.New("invalid sheet template")
+
+func (t SheetTemplate) Validate() error {
+	if t.Columns < 1 || t.Rows < 1 || t.LabelWidth <= 0 || t.LabelHeight <= 0 {
+		return fmt.Errorf("%w: rows, columns and label size must be positive", ErrInvalidTemplate)
+	}
+	if t.MarginTop < 0 || t.MarginLeft < 0 || t.GapX < 0 || t.GapY < 0 {
+		return fmt.Errorf("%w: margins and gaps cannot be negative", ErrInvalidTemplate)
+	}
+	width := t.MarginLeft + float64(t.Columns)*t.LabelWidth + float64(t.Columns-1)*t.GapX
+	height := t.MarginTop + float64(t.Rows)*t.LabelHeight + float64(t.Rows-1)*t.GapY
+	// Allow rounding in the published sizes of commercial sheets.
+	if width > t.PageWidth+0.5 || height > t.PageHeight+0.5 {
+		return fmt.Errorf("%w: labels do not fit on the page", ErrInvalidTemplate)
+	}
+	return nil
+}
+
+func (t SheetTemplate) PerPage() int {
+	return t.Columns * t.Rows
+}
+
+// SheetLabels converts print tasks into labels for RenderSheet, spelling
+// text with scheme unless a task picks its own. The standard PDF font has no
+// Cyrillic, so TranslitNone falls back to TranslitSimple.
+func SheetLabels(tasks []service.PrintTask, scheme domain.TranslitScheme) []Label {
+	labels := make([]Label, 0, len(tasks))
+	for _, t := range tasks {
+		s := scheme
+		if t.Translit != "" {
+			s = t.Translit
+		}
+		if s == domain.TranslitNone {
+			s = domain.TranslitSimple
+		}
+		labels = append(labels, Label{
+			Str1:      service.Transliterate(t.Str1, s),
+			Str2:      service.Transliterate(t.Str2, s),
+			Barcode:   t.Barcode,
+			Symbology: t.Symbology,
+			Data:      t.Data,
+		})
+	}
+	return labels
+}
+
+// RenderSheet lays labels out on as many pages as needed and returns the
+// PDF. start skips that many cells on the first page so that a partly used
+// sheet can be fed again.
+func RenderSheet(t SheetTemplate, labels []Label, start int) ([]byte, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	if start < 0 || start >= t.PerPage() {
+		return nil, fmt.Errorf("%w: start must be between 0 and %d", ErrInvalidTemplate, t.PerPage()-1)
+	}
+
+	doc := newPDFDocument(t.PageWidth*mmToPt, t.PageHeight*mmToPt)
+	var page *pdfPage
+	for i, label := range labels {
+		cell := (start + i) % t.PerPage()
+		if page == nil || cell == 0 {
+			page = doc.addPage()
+		}
+
+		col, row := cell%t.Columns, cell/t.Columns
+		x := t.MarginLeft + float64(col)*(t.LabelWidth+t.GapX)
+		top := t.PageHeight - t.MarginTop - float64(row)*(t.LabelHeight+t.GapY)
+		if err := drawSheetLabel(page, label, x*mmToPt, (top-t.LabelHeight)*mmToPt, t.LabelWidth*mmToPt, t.LabelHeight*mmToPt); err != nil {
+			return nil, err
+		}
+	}
+	if page == nil {
+		doc.addPage()
+	}
+
+	return doc.bytes(), nil
+}
+
+const (
+	sheetPadding   = 2.5 * mmToPt
+	sheetTitleSize = 8.0
+	sheetTextSize  = 7.0
+	sheetDigitSize = 7.0
+	// sheetQuietModules is the quiet zone on both sides of a linear symbol:
+	// 11 + 7 modules for EAN-13, at least 10 + 10 for Code128.
+	sheetQuietModules = 20
+	// sheetMaxModule keeps bars close to the nominal 0.33 mm module even on
+	// wide labels, so that scanners do not see an oversized symbol.
+	sheetMaxModule = 0.4 * mmToPt
+	// helveticaDigitWidth is the advance width of a Helvetica digit in ems.
+	helveticaDigitWidth = 0.556
+)
+
+// drawSheetLabel draws one label into the box at x, y (bottom-left) of the
+// given size: two text lines at the top, the symbol and the digits below.
+func drawSheetLabel(page *pdfPage, label Label, x, y, w, h float64) error {
+	data := label.Data
+	if data == "" {
+		data = label.Barcode
+	}
+	code, err := service.EncodeSymbol(label.Symbology, data)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+
+	page.clip(x, y, w, h)
+	defer page.restore()
+
+	innerX, innerW := x+sheetPadding, w-2*sheetPadding
+	top := y + h - sheetPadding
+
+	line1 := top - sheetTitleSize
+	page.text(innerX, line1, sheetTitleSize, label.Str1)
+	line2 := line1 - sheetTextSize - 1
+	if label.Str2 != "" {
+		page.text(innerX, line2, sheetTextSize, label.Str2)
+	}
+
+	digitsY := y + sheetPadding
+	barsBottom := digitsY + sheetDigitSize + 1
+	barsTop := line2 - 3
+	if barsTop <= barsBottom {
+		return nil
+	}
+
+	if label.Symbology.TwoDimensional() {
+		drawMatrix(page, code, innerX, barsBottom, innerW, barsTop-barsBottom)
+	} else {
+		drawBars(page, code, innerX, barsBottom, innerW, barsTop-barsBottom)
+	}
+
+	digitsW := float64(len(label.Barcode)) * helveticaDigitWidth * sheetDigitSize
+	page.text(innerX+(innerW-digitsW)/2, digitsY, sheetDigitSize, label.Barcode)
+
+	return nil
+}
+
+// drawBars draws a linear symbol centred in the box.
+func drawBars(page *pdfPage, code barcode.Barcode, x, y, w, h float64) {
+	modules := code.Bounds().Dx()
+	module := min(w/float64(modules+sheetQuietModules), sheetMaxModule)
+	barsX := x + (w-float64(modules)*module)/2
+
+	for m := 0; m < modules; {
+		if code.At(m, 0) != color.Black {
+			m++
+			continue
+		}
+		run := 1
+		for m+run < modules && code.At(m+run, 0) == color.Black {
+			run++
+		}
+		page.rect(barsX+float64(m)*module, y, float64(run)*module, h)
+		m += run
+	}
+}
+
+// drawMatrix draws a 2D symbol as the largest square that fits the box,
+// leaving a quiet zone of two modules around it.
+func drawMatrix(page *pdfPage, code barcode.Barcode, x, y, w, h float64) {
+	bounds := code.Bounds()
+	cells := max(bounds.Dx(), bounds.Dy())
+	module := min(w, h) / float64(cells+4)
+	left := x + (w-float64(bounds.Dx())*module)/2
+	top := y + (h+float64(bounds.Dy())*module)/2
+
+	for row := 0; row < bounds.Dy(); row++ {
+		for col := 0; col < bounds.Dx(); {
+			if code.At(bounds.Min.X+col, bounds.Min.Y+row) != color.Black {
+				col++
+				continue
+			}
+			run := 1
+			for col+run < bounds.Dx() && code.At(bounds.Min.X+col+run, bounds.Min.Y+row) == color.Black {
+				run++
+			}
+			page.rect(left+float64(col)*module, top-float64(row+1)*module, float64(run)*module, module)
+			col += run
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package printer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Sink delivers encoded label data.
+type Sink interface {
+	Send(ctx context.Context, name string, data []byte) error
+}
+
+// TCPSink writes raw data to a network printer, usually on port 9100.
+type TCPSink struct {
+	Addr    string
+	Timeout time.Duration
+}
+
+func (s TCPSink) Send(ctx context.Context, name string, data []byte) error {
+	dialer := net.Dialer{Timeout: s.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to printer %s: %w", s.Addr, err)
+	}
+	defer conn.Close()
+
+	if s.Timeout > 0 {
+		if err := conn.SetWriteDeadline(time.Now().Add(s.Timeout)); err != nil {
+			return err
+		}
+	}
+	if _, err := conn.Write(data); err != nil {
+		return fmt.Errorf("failed to send label to printer %s: %w", s.Addr, err)
+	}
+	return nil
+}
+
+// SpoolSink writes each label to its own file in Dir. Files are written
+// under a temporary name and renamed, so readers never see partial output.
+type SpoolSink struct {
+	Dir string
+}
+
+func (s SpoolSink) Send(ctx context.Context, name string, data []byte) error {
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return err
+	}
+
+	final := filepath.Join(s.Dir, filepath.Base(name))
+	tmp, err := os.CreateTemp(s.Dir, ".spool-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), final)
+}
@@ -0,0 +1,190 @@
+package printer
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+	"elibrary/internal/service"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// TableConsumer prints jobs straight from the print_jobs table. It is used
+// by the print worker with PRINT_TRANSPORT=postgres and, inside the backend,
+// by LocalTransport.
+type TableConsumer struct {
+	Jobs   repository.PrintJobRepository
+	Worker *Worker
+	// PollInterval is how long to wait for new jobs when the queue is empty
+	// and nobody calls Wake.
+	PollInterval time.Duration
+	// ClaimTimeout is how long a job may stay in printing before it is
+	// considered abandoned by a consumer that crashed or was killed.
+	ClaimTimeout time.Duration
+	// MaxAttempts is how many times a job is tried before it is marked
+	// failed, so neither a printer that stays offline nor a job that brings
+	// the consumer down can loop.
+	MaxAttempts int
+
+	wake chan struct{}
+}
+
+const (
+	defaultClaimTimeout = 5 * time.Minute
+	defaultMaxAttempts  = 3
+)
+
+func NewTableConsumer(jobs repository.PrintJobRepository, worker *Worker, pollInterval time.Duration) *TableConsumer {
+	return &TableConsumer{
+		Jobs:         jobs,
+		Worker:       worker,
+		PollInterval: pollInterval,
+		ClaimTimeout: defaultClaimTimeout,
+		MaxAttempts:  defaultMaxAttempts,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// Wake makes an idle Run check the table immediately.
+func (c *TableConsumer) Wake() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run claims and prints jobs one at a time until ctx is cancelled. Abandoned
+// jobs are recovered on start and then every half ClaimTimeout.
+func (c *TableConsumer) Run(ctx context.Context) {
+	var nextRecovery time.Time
+	for {
+		if now := time.Now(); !now.Before(nextRecovery) {
+			if err := c.RecoverStale(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("print job consumer: recovering abandoned jobs: %v", err)
+			}
+			nextRecovery = now.Add(c.ClaimTimeout / 2)
+		}
+
+		printed, err := c.ProcessNext(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("print job consumer: %v", err)
+		}
+		if printed && err == nil {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-c.wake:
+		case <-time.After(c.PollInterval):
+		}
+	}
+}
+
+// ProcessNext prints the oldest queued job. It reports false when there was
+// nothing to print. Transient failures put the job back in the queue, to be
+// claimed again after the worker's RetryDelay, until MaxAttempts is reached.
+func (c *TableConsumer) ProcessNext(ctx context.Context) (bool, error) {
+	job, err := c.Jobs.ClaimNext(ctx)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	printErr := c.Worker.Process(ctx, job.Payload)
+
+	// Use a fresh context so that a shutdown does not leave the job stuck
+	// in printing.
+	ctx = context.WithoutCancel(ctx)
+	switch {
+	case printErr == nil:
+		err = c.Jobs.FinishClaim(ctx, job.ID, *job.ClaimedAt, domain.PrintJobDone, nil)
+	case errors.Is(printErr, ErrPermanent):
+		log.Printf("print job %s failed: %v", job.ID, printErr)
+		msg := printErr.Error()
+		err = c.Jobs.FinishClaim(ctx, job.ID, *job.ClaimedAt, domain.PrintJobFailed, &msg)
+	case job.Attempts >= c.MaxAttempts:
+		log.Printf("print job %s failed after %d attempts: %v", job.ID, job.Attempts, printErr)
+		msg := printErr.Error()
+		err = c.Jobs.FinishClaim(ctx, job.ID, *job.ClaimedAt, domain.PrintJobFailed, &msg)
+	default:
+		log.Printf("print job %s failed, requeueing: %v", job.ID, printErr)
+		msg := printErr.Error()
+		err = c.Jobs.RequeueClaim(ctx, job.ID, *job.ClaimedAt, &msg, time.Now().Add(c.Worker.RetryDelay))
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		log.Printf("print job %s was recovered by another consumer while printing", job.ID)
+		return true, nil
+	}
+	return true, err
+}
+
+// RecoverStale puts jobs that stayed in printing longer than ClaimTimeout
+// back in the queue. A job that was already tried MaxAttempts times is
+// marked failed instead; the operator can still retry it. Jobs finished or
+// claimed again in the meantime are left alone.
+func (c *TableConsumer) RecoverStale(ctx context.Context) error {
+	jobs, err := c.Jobs.StaleClaims(ctx, time.Now().Add(-c.ClaimTimeout))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	for _, job := range jobs {
+		if job.Attempts >= c.MaxAttempts {
+			log.Printf("print job %s abandoned after %d attempts, marking failed", job.ID, job.Attempts)
+			msg := fmt.Sprintf("print worker stopped while printing, %d attempts", job.Attempts)
+			err := c.Jobs.FinishClaim(ctx, job.ID, *job.ClaimedAt, domain.PrintJobFailed, &msg)
+			if err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return err
+			}
+			continue
+		}
+
+		log.Printf("print job %s abandoned since %s, requeueing", job.ID, job.ClaimedAt.Format(time.RFC3339))
+		msg := "print worker stopped while printing"
+		err := c.Jobs.RequeueClaim(ctx, job.ID, *job.ClaimedAt, &msg, time.Now())
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// LocalTransport prints inside the backend process: no broker and no
+// separate worker, only Postgres. Jobs are still stored in print_jobs, so
+// the ones queued before a restart are printed afterwards, and the one that
+// was printing is recovered once its claim times out.
+type LocalTransport struct {
+	consumer *TableConsumer
+}
+
+func NewLocalTransport(consumer *TableConsumer) *LocalTransport {
+	return &LocalTransport{consumer: consumer}
+}
+
+var (
+	_ service.PrintTransport = (*LocalTransport)(nil)
+	_ service.PrintNotifier  = (*LocalTransport)(nil)
+)
+
+func (t *LocalTransport) Enqueue(ctx context.Context, tx repository.PrintJobTx, task service.PrintTask) error {
+	return nil
+}
+
+func (t *LocalTransport) Notify() {
+	t.consumer.Wake()
+}
+
+// Run prints jobs until ctx is cancelled. Status is written to print_jobs
+// directly, so apply is not used.
+func (t *LocalTransport) Run(ctx context.Context, apply func(context.Context, service.PrintStatus) error) {
+	t.consumer.Run(ctx)
+}
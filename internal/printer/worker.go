@@ -0,0 +1,214 @@
+package printer
+
+import (
+	"context"
+	"elibrary/internal/config"
+	"elibrary/internal/domain"
+	"elibrary/internal/service"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// ErrPermanent marks tasks that can never be printed, such as malformed
+// messages or invalid barcodes. They are dropped instead of requeued.
+var ErrPermanent = errors.New("permanent print failure")
+
+// StatusReporter delivers job status updates to the reply queue named by a
+// task's publisher.
+type StatusReporter interface {
+	Report(ctx context.Context, replyTo string, status service.PrintStatus) error
+}
+
+type channelReporter struct {
+	ch *amqp.Channel
+}
+
+func (r channelReporter) Report(ctx context.Context, replyTo string, status service.PrintStatus) error {
+	body, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	return r.ch.PublishWithContext(ctx, "", replyTo, false, false, amqp.Publishing{
+		ContentType:   "application/json",
+		CorrelationId: status.JobID.String(),
+		Body:          body,
+	})
+}
+
+type Worker struct {
+	Barcodes *service.BarcodeService
+	Encoder  Encoder
+	Sink     Sink
+	// RetryDelay is waited before a failed delivery is requeued so that an
+	// offline printer does not turn into a hot redelivery loop.
+	RetryDelay time.Duration
+}
+
+// Process renders one PrintTask message and sends it to the sink.
+func (w *Worker) Process(ctx context.Context, body []byte) error {
+	var task service.PrintTask
+	if err := json.Unmarshal(body, &task); err != nil {
+		return fmt.Errorf("%w: invalid task json: %v", ErrPermanent, err)
+	}
+
+	code := strings.TrimSpace(task.Barcode)
+	task.Barcode = code
+	png, err := w.Barcodes.GenerateSymbolImage(task.Symbology, task.SymbolData())
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	img, err := DecodePNG(png)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+
+	data, err := w.Encoder.Encode(Label{
+		Str1:    service.Transliterate(task.Str1, task.Translit),
+		Str2:    service.Transliterate(task.Str2, task.Translit),
+		Barcode: code,
+		Image:   img,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+
+	name := fmt.Sprintf("%s-%s.%s", time.Now().UTC().Format("20060102T150405.000000000"), code, w.Encoder.Ext())
+	return w.Sink.Send(ctx, name, data)
+}
+
+// Handle processes a delivery and settles it: successful tasks are acked,
+// permanent failures are rejected without requeue and transient failures
+// are requeued after RetryDelay. Each step is reported through reporter when
+// the delivery carries a job ID and a reply queue; reporter may be nil.
+func (w *Worker) Handle(ctx context.Context, d amqp.Delivery, reporter StatusReporter) {
+	report := func(status domain.PrintJobStatus, cause error) {
+		if reporter == nil || d.ReplyTo == "" {
+			return
+		}
+		jobID, err := uuid.Parse(d.CorrelationId)
+		if err != nil {
+			return
+		}
+		msg := service.PrintStatus{JobID: jobID, Status: status}
+		if cause != nil {
+			msg.Error = cause.Error()
+		}
+		if err := reporter.Report(ctx, d.ReplyTo, msg); err != nil {
+			log.Printf("failed to report print job %s status: %v", jobID, err)
+		}
+	}
+
+	report(domain.PrintJobPrinting, nil)
+
+	err := w.Process(ctx, d.Body)
+	switch {
+	case err == nil:
+		report(domain.PrintJobDone, nil)
+		if ackErr := d.Ack(false); ackErr != nil {
+			log.Printf("failed to ack print task: %v", ackErr)
+		}
+	case errors.Is(err, ErrPermanent):
+		log.Printf("dropping print task: %v", err)
+		report(domain.PrintJobFailed, err)
+		if nackErr := d.Nack(false, false); nackErr != nil {
+			log.Printf("failed to reject print task: %v", nackErr)
+		}
+	default:
+		log.Printf("print task failed, requeueing: %v", err)
+		report(domain.PrintJobQueued, err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(w.RetryDelay):
+		}
+		if nackErr := d.Nack(false, true); nackErr != nil {
+			log.Printf("failed to requeue print task: %v", nackErr)
+		}
+	}
+}
+
+// Run consumes the queue over a single connection until ctx is cancelled or
+// the connection is lost. Callers reconnect by calling Run again.
+func (w *Worker) Run(ctx context.Context, url, queue string) error {
+	conn, err := amqp.Dial(url)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	// One unacknowledged task at a time: labels must come out in order and
+	// a slow printer should not hoard messages.
+	if err := ch.Qos(1, 0, false); err != nil {
+		return err
+	}
+
+	q, err := ch.QueueDeclare(
+		queue,
+		true,
+		false,
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	deliveries, err := ch.Consume(q.Name, "", false, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+
+	reporter := channelReporter{ch: ch}
+
+	log.Printf("print worker consuming %s", q.Name)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case d, ok := <-deliveries:
+			if !ok {
+				return errors.New("delivery channel closed")
+			}
+			w.Handle(ctx, d, reporter)
+		}
+	}
+}
+
+// NewWorker builds a worker from the printer settings in cfg: labels go to
+// the TCP printer when PrinterAddr is set and to the spool directory
+// otherwise.
+func NewWorker(cfg *config.PrintWorkerConfig) (*Worker, error) {
+	encoder, err := NewEncoder(cfg.PrinterFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	var sink Sink
+	if cfg.PrinterAddr != "" {
+		sink = TCPSink{Addr: cfg.PrinterAddr, Timeout: cfg.PrinterTimeout}
+		log.Printf("printing %s labels to %s", cfg.PrinterFormat, cfg.PrinterAddr)
+	} else {
+		sink = SpoolSink{Dir: cfg.SpoolDir}
+		log.Printf("spooling %s labels to %s", cfg.PrinterFormat, cfg.SpoolDir)
+	}
+
+	return &Worker{
+		Barcodes:   service.NewBarcodeService(nil),
+		Encoder:    encoder,
+		Sink:       sink,
+		RetryDelay: cfg.RetryDelay,
+	}, nil
+}
@@ -0,0 +1,40 @@
+package printer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// ZPL renders labels for Zebra-compatible printers. The barcode is sent as a
+// ^GFA graphic field so that the printed bars match the generated image.
+type ZPL struct{}
+
+func (ZPL) Ext() string { return "zpl" }
+
+func (ZPL) Encode(label Label) ([]byte, error) {
+	if label.Image == nil {
+		return nil, fmt.Errorf("label %s has no barcode image", label.Barcode)
+	}
+	bm := newBitmap(label.Image)
+
+	var buf bytes.Buffer
+	buf.WriteString("^XA\n")
+	buf.WriteString("^CI28\n")
+	fmt.Fprintf(&buf, "^FO20,20^A0N,28,28^FD%s^FS\n", zplField(label.Str1))
+	fmt.Fprintf(&buf, "^FO20,55^A0N,28,28^FD%s^FS\n", zplField(label.Str2))
+
+	total := len(bm.data)
+	fmt.Fprintf(&buf, "^FO20,95^GFA,%d,%d,%d,%s^FS\n",
+		total, total, bm.rowBytes, strings.ToUpper(hex.EncodeToString(bm.data)))
+
+	buf.WriteString("^XZ\n")
+	return buf.Bytes(), nil
+}
+
+// zplField strips the ZPL command prefixes that would otherwise terminate
+// the field data early.
+func zplField(s string) string {
+	return strings.NewReplacer("^", " ", "~", " ").Replace(s)
+}
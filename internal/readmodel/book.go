@@ -11,6 +11,9 @@ type BookPublic struct {
 	Title          string    `json:"title"`
 	Barcode        string    `json:"barcode"`
 	FactoryBarcode *string   `json:"factory_barcode,omitempty"`
+	// ISBN and ISSN are hyphenated for display.
+	ISBN *string `json:"isbn,omitempty"`
+	ISSN *string `json:"issn,omitempty"`
 
 	Publisher   *Publisher   `json:"publisher,omitempty"`
 	Works       []*WorkShort `json:"works,omitempty"`
@@ -19,6 +22,10 @@ type BookPublic struct {
 
 	Extra map[string]any `json:"extra,omitempty"`
 
+	// Replaced is set when the book was found by a barcode it no longer
+	// carries.
+	Replaced bool `json:"replaced,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -28,15 +35,25 @@ type BookInternal struct {
 	Title          string    `json:"title"`
 	Barcode        string    `json:"barcode"`
 	FactoryBarcode *string   `json:"factory_barcode,omitempty"`
+	// ISBN and ISSN are hyphenated for display.
+	ISBN *string `json:"isbn,omitempty"`
+	ISSN *string `json:"issn,omitempty"`
 
 	Publisher   *Publisher   `json:"publisher,omitempty"`
 	Location    *Location    `json:"location,omitempty"`
+	Loan        *Loan        `json:"loan,omitempty"`
 	Works       []*WorkShort `json:"works,omitempty"`
 	Year        *int         `json:"year,omitempty"`
 	Description *string      `json:"description,omitempty"`
 
 	Extra map[string]any `json:"extra,omitempty"`
 
+	// Replaced is set when the book was found by a barcode it no longer
+	// carries.
+	Replaced bool `json:"replaced,omitempty"`
+
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -58,6 +75,14 @@ type Location struct {
 	Address      string    `json:"address"`
 }
 
+type Loan struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	UserLogin string     `json:"user_login"`
+	IssuedAt  time.Time  `json:"issued_at"`
+	DueAt     *time.Time `json:"due_at,omitempty"`
+}
+
 type Author struct {
 	ID         uuid.UUID `json:"id"`
 	LastName   string    `json:"last_name"`
@@ -10,6 +10,6 @@ type RoleWithPermissions struct {
 	ID          int          `json:"id"`
 	Code        string       `json:"code"`
 	Name        string       `json:"name"`
+	IsSystem    bool         `json:"is_system"`
 	Permissions []Permission `json:"permissions"`
 }
-
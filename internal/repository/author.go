@@ -15,4 +15,7 @@ type AuthorRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 
 	GetAll(ctx context.Context) ([]readmodel.Author, error)
+	// FindByLastNames returns the authors whose last name equals one of
+	// lastNames, ignoring case.
+	FindByLastNames(ctx context.Context, lastNames []string) ([]readmodel.Author, error)
 }
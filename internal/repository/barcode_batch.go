@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"elibrary/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type BarcodeBatchRepository interface {
+	// Create stores the batch together with its reserved barcodes.
+	Create(ctx context.Context, batch domain.BarcodeBatch, barcodes []string) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.BarcodeBatch, error)
+	List(ctx context.Context, limit, offset int) ([]*domain.BarcodeBatch, error)
+	GetBarcodes(ctx context.Context, batchID uuid.UUID) ([]*domain.ReservedBarcode, error)
+}
@@ -21,12 +21,38 @@ type BookRepository interface {
 	GetPublic(ctx context.Context, filter BookFilter) ([]*readmodel.BookPublic, error)
 	GetInternal(ctx context.Context, filter BookFilter) ([]*readmodel.BookInternal, error)
 
+	GetMovements(ctx context.Context, bookID uuid.UUID) ([]*domain.BookMovement, error)
+	GetEvents(ctx context.Context, bookID uuid.UUID) ([]*domain.BookEvent, error)
+	GetEventByID(ctx context.Context, id uuid.UUID) (*domain.BookEvent, error)
+	GetReplacedBarcodes(ctx context.Context, bookID uuid.UUID) ([]*domain.ReplacedBarcode, error)
+
 	WithTx(ctx context.Context, fn func(tx BookTx) error) error
 }
 
 type BookTx interface {
+	PrintJobTx
+
 	GetDomainByID(ctx context.Context, id uuid.UUID) (*domain.Book, error)
 	CreateBook(ctx context.Context, book domain.Book) error
 	UpdateBook(ctx context.Context, book domain.Book) error
 	ReplaceBookWorks(ctx context.Context, bookID uuid.UUID, works []BookWorkInput) error
+	CreateMovement(ctx context.Context, movement domain.BookMovement) error
+
+	GetSnapshot(ctx context.Context, id uuid.UUID) (*domain.BookSnapshot, error)
+	CreateEvent(ctx context.Context, event domain.BookEvent) error
+
+	// BindReservedBarcode marks a reserved barcode as used by the book. It
+	// returns domain.ErrBarcodeNotReserved or domain.ErrBarcodeBound when the
+	// code cannot be used.
+	BindReservedBarcode(ctx context.Context, barcode string, bookID uuid.UUID) error
+	// AddReplacedBarcode records a code taken off the book by relabeling.
+	AddReplacedBarcode(ctx context.Context, entry domain.ReplacedBarcode) error
+
+	// LockBook takes a row lock on the book for the rest of the transaction
+	// so that no loan can be issued for it concurrently.
+	LockBook(ctx context.Context, id uuid.UUID) error
+	HasActiveLoan(ctx context.Context, id uuid.UUID) (bool, error)
+	SoftDelete(ctx context.Context, id uuid.UUID, deletedBy *uuid.UUID) error
+	Restore(ctx context.Context, id uuid.UUID) error
+	Purge(ctx context.Context, id uuid.UUID) error
 }
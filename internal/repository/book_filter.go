@@ -10,10 +10,23 @@ type BookFilter struct {
 	FactoryBarcode *string
 	Query          *string
 
+	// ISBN and ISSN hold normalized numbers. They match on their own or, with
+	// FactoryBarcode or Query, as an alternative to them.
+	ISBN *string
+	ISSN *string
+
+	// IDs restricts the result to the given books.
+	IDs []uuid.UUID
+	// LocationID matches books placed in the location or anywhere below it.
+	LocationID *uuid.UUID
+
 	PublisherID *uuid.UUID
 	YearFrom    *int
 	YearTo      *int
 
+	// Deleted switches the query to soft-deleted books only.
+	Deleted bool
+
 	Limit  *int
 	Offset *int
 }
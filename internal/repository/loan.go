@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LoanRepository interface {
+	Create(ctx context.Context, loan domain.Loan) error
+	Return(ctx context.Context, id uuid.UUID, returnedAt time.Time, returnedBy *uuid.UUID) error
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Loan, error)
+
+	GetActiveByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Loan, error)
+	GetActiveByBook(ctx context.Context, bookID uuid.UUID) ([]*domain.Loan, error)
+}
@@ -13,7 +13,16 @@ type LocationRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*domain.Location, error)
 	GetByType(ctx context.Context, locType domain.LocationType) ([]*domain.Location, error)
 	GetByTypeParentID(ctx context.Context, locType domain.LocationType, parentID uuid.UUID) ([]*domain.Location, error)
+	// GetByBarcode also finds a location by a barcode it carried before
+	// relabeling.
 	GetByBarcode(ctx context.Context, barcode string) (*domain.Location, error)
+	// ReplaceBarcode moves the location from entry.Barcode to
+	// entry.ReplacedBy and records the old code in the history.
+	ReplaceBarcode(ctx context.Context, entry domain.ReplacedBarcode) error
+	GetReplacedBarcodes(ctx context.Context, id uuid.UUID) ([]*domain.ReplacedBarcode, error)
+	// GetSubtree returns the location and all of its descendants, parents
+	// before children.
+	GetSubtree(ctx context.Context, id uuid.UUID) ([]*domain.Location, error)
 	HasChildren(ctx context.Context, id uuid.UUID) (bool, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 }
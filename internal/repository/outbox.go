@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"time"
+)
+
+// OutboxWriter stores a message in the current transaction. The message is
+// published only if the transaction commits.
+type OutboxWriter interface {
+	EnqueueMessage(ctx context.Context, msg domain.OutboxMessage) error
+}
+
+// OutboxRepository is used by the relay outside of any transaction, so that
+// no row locks are held while messages are published.
+type OutboxRepository interface {
+	// ClaimPending leases up to limit messages that are due for delivery by
+	// moving them lease into the future; other relays skip them until then.
+	// A relay that dies mid-batch thus only delays its messages.
+	ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxMessage, error)
+	MarkSent(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, errMsg string, availableAt time.Time) error
+	// Release makes claimed messages due again without counting an attempt.
+	Release(ctx context.Context, ids []int64) error
+}
@@ -6,6 +6,7 @@ import (
 	"elibrary/internal/readmodel"
 	"elibrary/internal/repository"
 	"errors"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -153,3 +154,41 @@ func (r *AuthorRepository) GetAll(ctx context.Context) ([]readmodel.Author, erro
 
 	return authors, nil
 }
+
+func (r *AuthorRepository) FindByLastNames(ctx context.Context, lastNames []string) ([]readmodel.Author, error) {
+	lowered := make([]string, 0, len(lastNames))
+	for _, name := range lastNames {
+		lowered = append(lowered, strings.ToLower(name))
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, last_name, first_name, middle_name
+		FROM authors
+		WHERE lower(last_name) = ANY($1::text[])
+		ORDER BY last_name, first_name, middle_name
+	`, lowered)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var authors []readmodel.Author
+	for rows.Next() {
+		var author readmodel.Author
+		if err := rows.Scan(
+			&author.ID,
+			&author.LastName,
+			&author.FirstName,
+			&author.MiddleName,
+		); err != nil {
+			return nil, err
+		}
+		authors = append(authors, author)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return authors, nil
+}
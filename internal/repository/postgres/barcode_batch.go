@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type BarcodeBatchRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewBarcodeBatchRepository(db *pgxpool.Pool) *BarcodeBatchRepository {
+	return &BarcodeBatchRepository{db: db}
+}
+
+func (r *BarcodeBatchRepository) Create(ctx context.Context, batch domain.BarcodeBatch, barcodes []string) error {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.ReadCommitted,
+		AccessMode: pgx.ReadWrite,
+	})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO barcode_batches (id, count, note, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, batch.ID, batch.Count, batch.Note, batch.CreatedBy, batch.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO reserved_barcodes (barcode, batch_id)
+		SELECT b, $1
+		FROM UNNEST($2::text[]) AS t(b)
+	`, batch.ID, barcodes)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+const barcodeBatchSelect = `
+	SELECT
+		bb.id,
+		bb.count,
+		(SELECT COUNT(*) FROM reserved_barcodes rb WHERE rb.batch_id = bb.id AND rb.bound_at IS NOT NULL),
+		bb.note,
+		bb.created_by,
+		bb.created_at
+	FROM barcode_batches bb
+`
+
+func scanBarcodeBatches(rows pgx.Rows) ([]*domain.BarcodeBatch, error) {
+	defer rows.Close()
+
+	var res []*domain.BarcodeBatch
+	for rows.Next() {
+		var b domain.BarcodeBatch
+		if err := rows.Scan(&b.ID, &b.Count, &b.Bound, &b.Note, &b.CreatedBy, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, &b)
+	}
+	return res, rows.Err()
+}
+
+func (r *BarcodeBatchRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.BarcodeBatch, error) {
+	rows, err := r.db.Query(ctx, barcodeBatchSelect+`WHERE bb.id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	batches, err := scanBarcodeBatches(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(batches) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return batches[0], nil
+}
+
+func (r *BarcodeBatchRepository) List(ctx context.Context, limit, offset int) ([]*domain.BarcodeBatch, error) {
+	rows, err := r.db.Query(ctx, barcodeBatchSelect+`ORDER BY bb.created_at DESC LIMIT $1 OFFSET $2`, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	batches, err := scanBarcodeBatches(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(batches) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return batches, nil
+}
+
+func (r *BarcodeBatchRepository) GetBarcodes(ctx context.Context, batchID uuid.UUID) ([]*domain.ReservedBarcode, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT barcode, batch_id, book_id, bound_at
+		FROM reserved_barcodes
+		WHERE batch_id = $1
+		ORDER BY barcode
+	`, batchID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []*domain.ReservedBarcode
+	for rows.Next() {
+		var b domain.ReservedBarcode
+		if err := rows.Scan(&b.Barcode, &b.BatchID, &b.BookID, &b.BoundAt); err != nil {
+			return nil, err
+		}
+		res = append(res, &b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return res, nil
+}
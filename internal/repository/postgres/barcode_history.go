@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func insertReplacedBarcode(ctx context.Context, tx pgx.Tx, entry domain.ReplacedBarcode) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO barcode_history (barcode, book_id, location_id, replaced_by, reason, created_by, replaced_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`,
+		entry.Barcode,
+		entry.BookID,
+		entry.LocationID,
+		entry.ReplacedBy,
+		entry.Reason,
+		entry.CreatedBy,
+		entry.ReplacedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.ErrBarcodeExists
+		}
+		return err
+	}
+	return nil
+}
+
+// listReplacedBarcodes returns the history of one book or location, newest
+// first. column is book_id or location_id.
+func listReplacedBarcodes(ctx context.Context, db *pgxpool.Pool, column string, id uuid.UUID) ([]*domain.ReplacedBarcode, error) {
+	rows, err := db.Query(ctx, `
+		SELECT barcode, book_id, location_id, replaced_by, reason, created_by, replaced_at
+		FROM barcode_history
+		WHERE `+column+` = $1
+		ORDER BY replaced_at DESC
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []*domain.ReplacedBarcode
+	for rows.Next() {
+		var e domain.ReplacedBarcode
+		if err := rows.Scan(
+			&e.Barcode,
+			&e.BookID,
+			&e.LocationID,
+			&e.ReplacedBy,
+			&e.Reason,
+			&e.CreatedBy,
+			&e.ReplacedAt,
+		); err != nil {
+			return nil, err
+		}
+		res = append(res, &e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return res, nil
+}
@@ -30,8 +30,8 @@ func (r *BookRepository) Create(ctx context.Context, book domain.Book) error {
 	}
 
 	_, err = r.db.Exec(ctx, `
-		INSERT INTO books (id, barcode, factory_barcode, title, publisher_id, year, description, location_id, extra)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO books (id, barcode, factory_barcode, title, publisher_id, year, description, location_id, extra, isbn, issn)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`,
 		book.ID,
 		book.Barcode,
@@ -42,6 +42,8 @@ func (r *BookRepository) Create(ctx context.Context, book domain.Book) error {
 		book.Description,
 		book.LocationID,
 		extraJSON,
+		book.ISBN,
+		book.ISSN,
 	)
 
 	if err != nil {
@@ -72,6 +74,8 @@ func (r *BookRepository) Update(ctx context.Context, book domain.Book) error {
 		    description = $7,
 		    location_id = $8,
 		    extra = $9,
+		    isbn = $10,
+		    issn = $11,
 		    updated_at = NOW()
 		WHERE id = $1
 	`,
@@ -84,6 +88,8 @@ func (r *BookRepository) Update(ctx context.Context, book domain.Book) error {
 		book.Description,
 		book.LocationID,
 		extraJSON,
+		book.ISBN,
+		book.ISSN,
 	)
 
 	if err != nil {
@@ -115,6 +121,8 @@ func (r *BookRepository) GetPublicByID(ctx context.Context, id uuid.UUID) (*read
 		&book.ID,
 		&book.Barcode,
 		&book.FactoryBarcode,
+		&book.ISBN,
+		&book.ISSN,
 		&book.Title,
 		&book.Year,
 		&book.Description,
@@ -164,6 +172,8 @@ func (r *BookRepository) GetInternalByID(ctx context.Context, id uuid.UUID) (*re
 		&book.ID,
 		&book.Barcode,
 		&book.FactoryBarcode,
+		&book.ISBN,
+		&book.ISSN,
 		&book.Title,
 		&book.Year,
 		&book.Description,
@@ -192,6 +202,10 @@ func (r *BookRepository) GetInternalByID(ctx context.Context, id uuid.UUID) (*re
 		return nil, err
 	}
 
+	if err := loadLoan(ctx, tx, id, &book.Loan); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return nil, err
 	}
@@ -206,6 +220,8 @@ func loadBookBase(
 	bookID *uuid.UUID,
 	barcode *string,
 	factoryBarcode **string,
+	isbn **string,
+	issn **string,
 	title *string,
 	year **int,
 	description **string,
@@ -224,6 +240,8 @@ func loadBookBase(
 			b.id,
 			b.barcode,
 			b.factory_barcode,
+			b.isbn,
+			b.issn,
 			b.title,
 		    b.year,
 		    b.description,
@@ -234,11 +252,13 @@ func loadBookBase(
 		    p.name
 		FROM books b
 		LEFT JOIN publishers p ON p.id = b.publisher_id
-		WHERE b.id = $1
+		WHERE b.id = $1 AND b.deleted_at IS NULL
 	`, id).Scan(
 		bookID,
 		barcode,
 		factoryBarcode,
+		isbn,
+		issn,
 		title,
 		year,
 		description,
@@ -255,6 +275,9 @@ func loadBookBase(
 		return err
 	}
 
+	*isbn = displayISBN(*isbn)
+	*issn = displayISSN(*issn)
+
 	if publisherID != nil && publisherName != nil {
 		*publisher = &readmodel.Publisher{
 			ID:   *publisherID,
@@ -403,6 +426,37 @@ func loadLocation(
 	return nil
 }
 
+func loadLoan(
+	ctx context.Context,
+	tx pgx.Tx,
+	bookID uuid.UUID,
+	target **readmodel.Loan,
+) error {
+	var loan readmodel.Loan
+
+	err := tx.QueryRow(ctx, `
+		SELECT l.id, l.user_id, u.login, l.issued_at, l.due_at
+		FROM book_loans l
+		JOIN users u ON u.id = l.user_id
+		WHERE l.book_id = $1 AND l.returned_at IS NULL
+	`, bookID).Scan(
+		&loan.ID,
+		&loan.UserID,
+		&loan.UserLogin,
+		&loan.IssuedAt,
+		&loan.DueAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	*target = &loan
+	return nil
+}
+
 func derefStr(s *string) string {
 	if s != nil {
 		return *s
@@ -410,6 +464,23 @@ func derefStr(s *string) string {
 	return ""
 }
 
+// displayISBN and displayISSN hyphenate the stored numbers.
+func displayISBN(isbn *string) *string {
+	if isbn == nil {
+		return nil
+	}
+	s := domain.HyphenateISBN(*isbn)
+	return &s
+}
+
+func displayISSN(issn *string) *string {
+	if issn == nil {
+		return nil
+	}
+	s := domain.FormatISSN(*issn)
+	return &s
+}
+
 func derefUUID(id *uuid.UUID) uuid.UUID {
 	if id != nil {
 		return *id
@@ -439,11 +510,14 @@ func (r *BookRepository) GetPublic(ctx context.Context, filter repository.BookFi
 			Title:          book.Title,
 			Barcode:        book.Barcode,
 			FactoryBarcode: book.FactoryBarcode,
+			ISBN:           book.ISBN,
+			ISSN:           book.ISSN,
 			Publisher:      book.Publisher,
 			Works:          book.Works,
 			Year:           book.Year,
 			Description:    book.Description,
 			Extra:          book.Extra,
+			Replaced:       book.Replaced,
 			CreatedAt:      book.CreatedAt,
 			UpdatedAt:      book.UpdatedAt,
 		})
@@ -475,6 +549,10 @@ func (r *BookRepository) GetInternal(ctx context.Context, filter repository.Book
 		return nil, err
 	}
 
+	if err := loadLoansForBooks(ctx, tx, base); err != nil {
+		return nil, err
+	}
+
 	res := make([]*readmodel.BookInternal, 0, len(base))
 	for _, book := range base {
 		res = append(res, &readmodel.BookInternal{
@@ -482,12 +560,17 @@ func (r *BookRepository) GetInternal(ctx context.Context, filter repository.Book
 			Title:          book.Title,
 			Barcode:        book.Barcode,
 			FactoryBarcode: book.FactoryBarcode,
+			ISBN:           book.ISBN,
+			ISSN:           book.ISSN,
 			Publisher:      book.Publisher,
 			Location:       book.Location,
+			Loan:           book.Loan,
 			Works:          book.Works,
 			Year:           book.Year,
 			Description:    book.Description,
 			Extra:          book.Extra,
+			Replaced:       book.Replaced,
+			DeletedAt:      book.DeletedAt,
 			CreatedAt:      book.CreatedAt,
 			UpdatedAt:      book.UpdatedAt,
 		})
@@ -511,31 +594,50 @@ func (r *BookRepository) getBooksBase(
 			b.id,
 			b.barcode,
 			b.factory_barcode,
+			b.isbn,
+			b.issn,
 			b.title,
 			b.year,
 			b.description,
 			b.extra,
 			b.created_at,
 			b.updated_at,
+			b.deleted_at,
 			p.id,
-			p.name
+			p.name,
+			EXISTS (
+			    SELECT 1 FROM barcode_history h
+			    WHERE h.book_id = b.id AND (h.barcode = $2 OR h.barcode = $4)
+			)
 		FROM books b
 		LEFT JOIN publishers p ON p.id = b.publisher_id
 		WHERE
 		    (
 		        ($1::uuid IS NOT NULL AND b.id = $1)
 		        OR
-		        ($1::uuid IS NULL AND $2::text IS NOT NULL AND b.barcode = $2)
+		        (
+		            $1::uuid IS NULL AND $2::text IS NOT NULL
+		            AND (
+		                b.barcode = $2
+		                OR b.id = (SELECT h.book_id FROM barcode_history h WHERE h.barcode = $2)
+		            )
+		        )
 		        OR
-		        ($1::uuid IS NULL AND $2::text IS NULL AND $3::text IS NOT NULL AND b.factory_barcode = $3)
+		        (
+		            $1::uuid IS NULL AND $2::text IS NULL AND $3::text IS NOT NULL
+		            AND (b.factory_barcode = $3 OR b.isbn = $13 OR b.issn = $14)
+		        )
 		        OR
 		        (
 		            $1::uuid IS NULL
 					AND $2::text IS NULL
 		            AND $3::text IS NULL
 		            AND (
-		                $4::text IS NULL
+		                ($4::text IS NULL AND $13::text IS NULL AND $14::text IS NULL)
 		                OR b.search_vector @@ plainto_tsquery('russian', $4)
+		                OR b.isbn = $13
+		                OR b.issn = $14
+		                OR b.id = (SELECT h.book_id FROM barcode_history h WHERE h.barcode = $4)
 		                OR EXISTS (
 		                    WITH RECURSIVE loc_chain AS (
 		                        SELECT l.id, l.parent_id, l.barcode
@@ -549,6 +651,7 @@ func (r *BookRepository) getBooksBase(
 		                    SELECT 1
 		                    FROM loc_chain
 		                    WHERE barcode = $4
+		                       OR id = (SELECT h.location_id FROM barcode_history h WHERE h.barcode = $4)
 		                )
 		            )
 		        )
@@ -556,6 +659,19 @@ func (r *BookRepository) getBooksBase(
 			AND ($5::uuid IS NULL OR b.publisher_id = $5)
 			AND ($6::int IS NULL OR b.year >= $6)
 			AND ($7::int IS NULL OR b.year <= $7)
+			AND (b.deleted_at IS NOT NULL) = $10
+			AND ($11::uuid[] IS NULL OR b.id = ANY($11))
+			AND (
+			    $12::uuid IS NULL
+			    OR b.location_id IN (
+			        WITH RECURSIVE subtree AS (
+			            SELECT l.id FROM locations l WHERE l.id = $12
+			            UNION ALL
+			            SELECT c.id FROM locations c JOIN subtree s ON c.parent_id = s.id
+			        )
+			        SELECT id FROM subtree
+			    )
+			)
 		ORDER BY b.created_at DESC
 		LIMIT $8 OFFSET $9
 	`,
@@ -568,6 +684,11 @@ func (r *BookRepository) getBooksBase(
 		filter.YearTo,
 		filter.LimitOr(20),
 		filter.OffsetOr(0),
+		filter.Deleted,
+		filter.IDs,
+		filter.LocationID,
+		filter.ISBN,
+		filter.ISSN,
 	)
 	if err != nil {
 		return nil, err
@@ -588,18 +709,25 @@ func (r *BookRepository) getBooksBase(
 			&book.ID,
 			&book.Barcode,
 			&book.FactoryBarcode,
+			&book.ISBN,
+			&book.ISSN,
 			&book.Title,
 			&book.Year,
 			&book.Description,
 			&extraJSON,
 			&book.CreatedAt,
 			&book.UpdatedAt,
+			&book.DeletedAt,
 			&publisherID,
 			&publisherName,
+			&book.Replaced,
 		); err != nil {
 			return nil, err
 		}
 
+		book.ISBN = displayISBN(book.ISBN)
+		book.ISSN = displayISSN(book.ISSN)
+
 		if publisherID != nil {
 			book.Publisher = &readmodel.Publisher{
 				ID:   *publisherID,
@@ -817,6 +945,100 @@ func loadLocationsForBooks(ctx context.Context, tx pgx.Tx, books []*bookBase) er
 	return rows.Err()
 }
 
+func loadLoansForBooks(ctx context.Context, tx pgx.Tx, books []*bookBase) error {
+	if len(books) == 0 {
+		return nil
+	}
+	bookMap := make(map[uuid.UUID]*bookBase, len(books))
+	bookIDs := make([]uuid.UUID, 0, len(books))
+
+	for _, book := range books {
+		bookMap[book.ID] = book
+		bookIDs = append(bookIDs, book.ID)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT l.book_id, l.id, l.user_id, u.login, l.issued_at, l.due_at
+		FROM book_loans l
+		JOIN users u ON u.id = l.user_id
+		WHERE l.book_id = ANY($1) AND l.returned_at IS NULL
+	`, bookIDs)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			bookID uuid.UUID
+			loan   readmodel.Loan
+		)
+
+		if err := rows.Scan(
+			&bookID,
+			&loan.ID,
+			&loan.UserID,
+			&loan.UserLogin,
+			&loan.IssuedAt,
+			&loan.DueAt,
+		); err != nil {
+			return err
+		}
+
+		if book, ok := bookMap[bookID]; ok {
+			book.Loan = &loan
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r *BookRepository) GetReplacedBarcodes(ctx context.Context, bookID uuid.UUID) ([]*domain.ReplacedBarcode, error) {
+	return listReplacedBarcodes(ctx, r.db, "book_id", bookID)
+}
+
+func (r *BookRepository) GetMovements(ctx context.Context, bookID uuid.UUID) ([]*domain.BookMovement, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, book_id, from_location_id, to_location_id, moved_at, actor_user_id, reason
+		FROM book_movements
+		WHERE book_id = $1
+		ORDER BY moved_at DESC
+	`, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []*domain.BookMovement
+	for rows.Next() {
+		var m domain.BookMovement
+
+		if err := rows.Scan(
+			&m.ID,
+			&m.BookID,
+			&m.FromLocationID,
+			&m.ToLocationID,
+			&m.MovedAt,
+			&m.ActorUserID,
+			&m.Reason,
+		); err != nil {
+			return nil, err
+		}
+
+		movements = append(movements, &m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(movements) == 0 {
+		return nil, repository.ErrNotFound
+	}
+
+	return movements, nil
+}
+
 func (r *BookRepository) WithTx(ctx context.Context, fn func(tx repository.BookTx) error) error {
 	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
 		IsoLevel:   pgx.ReadCommitted,
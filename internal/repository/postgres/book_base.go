@@ -11,13 +11,18 @@ type bookBase struct {
 	ID             uuid.UUID
 	Barcode        string
 	FactoryBarcode *string
+	ISBN           *string
+	ISSN           *string
 	Title          string
 	Publisher      *readmodel.Publisher
 	Location       *readmodel.Location
+	Loan           *readmodel.Loan
 	Year           *int
 	Description    *string
 	Extra          map[string]any
 	Works          []*readmodel.WorkShort
+	DeletedAt      *time.Time
+	Replaced       bool
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 }
@@ -0,0 +1,143 @@
+package postgres
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func (t *bookTx) GetSnapshot(ctx context.Context, id uuid.UUID) (*domain.BookSnapshot, error) {
+	book, err := t.GetDomainByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := t.tx.Query(ctx, `
+		SELECT work_id, position
+		FROM book_works
+		WHERE book_id = $1
+		ORDER BY position NULLS LAST, work_id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	snapshot := &domain.BookSnapshot{
+		Book:  *book,
+		Works: make([]domain.BookSnapshotWork, 0),
+	}
+	for rows.Next() {
+		var w domain.BookSnapshotWork
+		if err := rows.Scan(&w.WorkID, &w.Position); err != nil {
+			return nil, err
+		}
+		snapshot.Works = append(snapshot.Works, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+func (t *bookTx) CreateEvent(ctx context.Context, event domain.BookEvent) error {
+	snapshotJSON, err := json.Marshal(event.Snapshot)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.tx.Exec(ctx, `
+		INSERT INTO book_events (id, book_id, event_type, occurred_at, actor_user_id, snapshot)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`,
+		event.ID,
+		event.BookID,
+		event.Type,
+		event.OccurredAt,
+		event.ActorUserID,
+		snapshotJSON,
+	)
+
+	return err
+}
+
+func (r *BookRepository) GetEvents(ctx context.Context, bookID uuid.UUID) ([]*domain.BookEvent, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, book_id, event_type, occurred_at, actor_user_id, snapshot
+		FROM book_events
+		WHERE book_id = $1
+		ORDER BY occurred_at DESC
+	`, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*domain.BookEvent
+	for rows.Next() {
+		event, err := scanBookEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(events) == 0 {
+		return nil, repository.ErrNotFound
+	}
+
+	return events, nil
+}
+
+func (r *BookRepository) GetEventByID(ctx context.Context, id uuid.UUID) (*domain.BookEvent, error) {
+	event, err := scanBookEvent(r.db.QueryRow(ctx, `
+		SELECT id, book_id, event_type, occurred_at, actor_user_id, snapshot
+		FROM book_events
+		WHERE id = $1
+	`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return event, nil
+}
+
+func scanBookEvent(row pgx.Row) (*domain.BookEvent, error) {
+	var (
+		event        domain.BookEvent
+		snapshotJSON []byte
+	)
+
+	if err := row.Scan(
+		&event.ID,
+		&event.BookID,
+		&event.Type,
+		&event.OccurredAt,
+		&event.ActorUserID,
+		&snapshotJSON,
+	); err != nil {
+		return nil, err
+	}
+
+	if len(snapshotJSON) > 0 {
+		if err := json.Unmarshal(snapshotJSON, &event.Snapshot); err != nil {
+			return nil, err
+		}
+	}
+
+	return &event, nil
+}
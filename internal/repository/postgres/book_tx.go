@@ -25,8 +25,8 @@ func (t *bookTx) CreateBook(ctx context.Context, book domain.Book) error {
 	}
 
 	_, err = t.tx.Exec(ctx, `
-		INSERT INTO books (id, barcode, factory_barcode, title, publisher_id, year, description, location_id, extra)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO books (id, barcode, factory_barcode, title, publisher_id, year, description, location_id, extra, isbn, issn)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`,
 		book.ID,
 		book.Barcode,
@@ -37,12 +37,19 @@ func (t *bookTx) CreateBook(ctx context.Context, book domain.Book) error {
 		book.Description,
 		book.LocationID,
 		extraJSON,
+		book.ISBN,
+		book.ISSN,
 	)
 
 	if err != nil {
 		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			return domain.ErrBarcodeExists
+		if errors.As(err, &pgErr) {
+			switch {
+			case pgErr.Code == "23505":
+				return domain.ErrBarcodeExists
+			case pgErr.Code == "23503" && pgErr.ConstraintName == "books_location_id_fkey":
+				return domain.ErrLocationNotFound
+			}
 		}
 		return err
 	}
@@ -67,6 +74,8 @@ func (t *bookTx) UpdateBook(ctx context.Context, book domain.Book) error {
 		    description = $7,
 		    location_id = $8,
 		    extra = $9,
+		    isbn = $10,
+		    issn = $11,
 		    updated_at = NOW()
 		WHERE id = $1
 	`,
@@ -79,9 +88,20 @@ func (t *bookTx) UpdateBook(ctx context.Context, book domain.Book) error {
 		book.Description,
 		book.LocationID,
 		extraJSON,
+		book.ISBN,
+		book.ISSN,
 	)
 
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch {
+			case pgErr.Code == "23505":
+				return domain.ErrBarcodeExists
+			case pgErr.Code == "23503" && pgErr.ConstraintName == "books_location_id_fkey":
+				return domain.ErrLocationNotFound
+			}
+		}
 		return err
 	}
 
@@ -121,6 +141,24 @@ func (t *bookTx) ReplaceBookWorks(ctx context.Context, bookID uuid.UUID, works [
 
 	return err
 }
+
+func (t *bookTx) CreateMovement(ctx context.Context, movement domain.BookMovement) error {
+	_, err := t.tx.Exec(ctx, `
+		INSERT INTO book_movements (id, book_id, from_location_id, to_location_id, moved_at, actor_user_id, reason)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`,
+		movement.ID,
+		movement.BookID,
+		movement.FromLocationID,
+		movement.ToLocationID,
+		movement.MovedAt,
+		movement.ActorUserID,
+		movement.Reason,
+	)
+
+	return err
+}
+
 func (t *bookTx) GetDomainByID(ctx context.Context, id uuid.UUID) (*domain.Book, error) {
 	var book domain.Book
 	var extraJSON []byte
@@ -130,12 +168,16 @@ func (t *bookTx) GetDomainByID(ctx context.Context, id uuid.UUID) (*domain.Book,
 		    id,
 		    barcode,
 		    factory_barcode,
+		    isbn,
+		    issn,
 		    title,
 		    publisher_id,
 		    year,
 		    description,
 		    location_id,
 		    extra,
+		    deleted_at,
+		    deleted_by,
 		    created_at,
 		    updated_at
 		FROM books
@@ -144,12 +186,16 @@ func (t *bookTx) GetDomainByID(ctx context.Context, id uuid.UUID) (*domain.Book,
 		&book.ID,
 		&book.Barcode,
 		&book.FactoryBarcode,
+		&book.ISBN,
+		&book.ISSN,
 		&book.Title,
 		&book.PublisherID,
 		&book.Year,
 		&book.Description,
 		&book.LocationID,
 		&extraJSON,
+		&book.DeletedAt,
+		&book.DeletedBy,
 		&book.CreatedAt,
 		&book.UpdatedAt,
 	)
@@ -170,3 +216,149 @@ func (t *bookTx) GetDomainByID(ctx context.Context, id uuid.UUID) (*domain.Book,
 
 	return &book, nil
 }
+
+func (t *bookTx) BindReservedBarcode(ctx context.Context, barcode string, bookID uuid.UUID) error {
+	var bound bool
+	err := t.tx.QueryRow(ctx, `
+		SELECT bound_at IS NOT NULL
+		FROM reserved_barcodes
+		WHERE barcode = $1
+		FOR UPDATE
+	`, barcode).Scan(&bound)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrBarcodeNotReserved
+		}
+		return err
+	}
+	if bound {
+		return domain.ErrBarcodeBound
+	}
+
+	_, err = t.tx.Exec(ctx, `
+		UPDATE reserved_barcodes
+		SET book_id = $2, bound_at = NOW()
+		WHERE barcode = $1
+	`, barcode, bookID)
+	return err
+}
+
+func (t *bookTx) AddReplacedBarcode(ctx context.Context, entry domain.ReplacedBarcode) error {
+	return insertReplacedBarcode(ctx, t.tx, entry)
+}
+
+func (t *bookTx) LockBook(ctx context.Context, id uuid.UUID) error {
+	var locked uuid.UUID
+	err := t.tx.QueryRow(ctx, `
+		SELECT id
+		FROM books
+		WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(&locked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (t *bookTx) HasActiveLoan(ctx context.Context, id uuid.UUID) (bool, error) {
+	var exists bool
+	err := t.tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM book_loans
+			WHERE book_id = $1 AND returned_at IS NULL
+		)
+	`, id).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+func (t *bookTx) SoftDelete(ctx context.Context, id uuid.UUID, deletedBy *uuid.UUID) error {
+	res, err := t.tx.Exec(ctx, `
+		UPDATE books
+		SET
+		    deleted_at = NOW(),
+		    deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`, id, deletedBy)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (t *bookTx) Restore(ctx context.Context, id uuid.UUID) error {
+	res, err := t.tx.Exec(ctx, `
+		UPDATE books
+		SET
+		    deleted_at = NULL,
+		    deleted_by = NULL
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// Purge removes a soft-deleted book for good together with its loan and
+// movement history. Events are kept so the audit trail survives the purge.
+func (t *bookTx) Purge(ctx context.Context, id uuid.UUID) error {
+	_, err := t.tx.Exec(ctx, `
+		DELETE FROM book_movements
+		WHERE book_id = $1
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.tx.Exec(ctx, `
+		DELETE FROM book_loans
+		WHERE book_id = $1
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	res, err := t.tx.Exec(ctx, `
+		DELETE FROM books
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (t *bookTx) CreatePrintJob(ctx context.Context, job domain.PrintJob) error {
+	return insertPrintJob(ctx, t.tx, job)
+}
+
+func (t *bookTx) RequeuePrintJob(ctx context.Context, id uuid.UUID) error {
+	return requeuePrintJob(ctx, t.tx, id)
+}
+
+func (t *bookTx) EnqueueMessage(ctx context.Context, msg domain.OutboxMessage) error {
+	return insertOutboxMessage(ctx, t.tx, msg)
+}
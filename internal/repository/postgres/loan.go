@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LoanRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewLoanRepository(db *pgxpool.Pool) *LoanRepository {
+	return &LoanRepository{db: db}
+}
+
+func (r *LoanRepository) Create(ctx context.Context, loan domain.Loan) error {
+	res, err := r.db.Exec(ctx, `
+		INSERT INTO book_loans (id, book_id, user_id, issued_at, due_at, issued_by, note)
+		SELECT $1, b.id, $3, $4, $5, $6, $7
+		FROM books b
+		WHERE b.id = $2 AND b.deleted_at IS NULL
+		FOR SHARE
+	`,
+		loan.ID,
+		loan.BookID,
+		loan.UserID,
+		loan.IssuedAt,
+		loan.DueAt,
+		loan.IssuedBy,
+		loan.Note,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				return domain.ErrBookOnLoan
+			case "23503":
+				return repository.ErrNotFound
+			}
+		}
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *LoanRepository) Return(ctx context.Context, id uuid.UUID, returnedAt time.Time, returnedBy *uuid.UUID) error {
+	res, err := r.db.Exec(ctx, `
+		UPDATE book_loans
+		SET
+		    returned_at = $2,
+		    returned_by = $3
+		WHERE id = $1 AND returned_at IS NULL
+	`, id, returnedAt, returnedBy)
+	if err != nil {
+		return err
+	}
+
+	if res.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *LoanRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Loan, error) {
+	var loan domain.Loan
+
+	err := r.db.QueryRow(ctx, `
+		SELECT id, book_id, user_id, issued_at, due_at, returned_at, issued_by, returned_by, note
+		FROM book_loans
+		WHERE id = $1
+	`, id).Scan(
+		&loan.ID,
+		&loan.BookID,
+		&loan.UserID,
+		&loan.IssuedAt,
+		&loan.DueAt,
+		&loan.ReturnedAt,
+		&loan.IssuedBy,
+		&loan.ReturnedBy,
+		&loan.Note,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &loan, nil
+}
+
+func (r *LoanRepository) GetActiveByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Loan, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, book_id, user_id, issued_at, due_at, returned_at, issued_by, returned_by, note
+		FROM book_loans
+		WHERE user_id = $1 AND returned_at IS NULL
+		ORDER BY issued_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanLoans(rows)
+}
+
+func (r *LoanRepository) GetActiveByBook(ctx context.Context, bookID uuid.UUID) ([]*domain.Loan, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, book_id, user_id, issued_at, due_at, returned_at, issued_by, returned_by, note
+		FROM book_loans
+		WHERE book_id = $1 AND returned_at IS NULL
+		ORDER BY issued_at DESC
+	`, bookID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanLoans(rows)
+}
+
+func scanLoans(rows pgx.Rows) ([]*domain.Loan, error) {
+	var loans []*domain.Loan
+	for rows.Next() {
+		var loan domain.Loan
+
+		if err := rows.Scan(
+			&loan.ID,
+			&loan.BookID,
+			&loan.UserID,
+			&loan.IssuedAt,
+			&loan.DueAt,
+			&loan.ReturnedAt,
+			&loan.IssuedBy,
+			&loan.ReturnedBy,
+			&loan.Note,
+		); err != nil {
+			return nil, err
+		}
+
+		loans = append(loans, &loan)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(loans) == 0 {
+		return nil, repository.ErrNotFound
+	}
+
+	return loans, nil
+}
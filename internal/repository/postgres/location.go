@@ -201,6 +201,7 @@ func (r *LocationRepository) GetByBarcode(ctx context.Context, barcode string) (
 		SELECT id, parent_id, type, name, barcode, address, description, created_at, updated_at
 		FROM locations
 		WHERE barcode = $1
+		   OR id = (SELECT location_id FROM barcode_history WHERE barcode = $1)
 	`, barcode).Scan(
 		&location.ID,
 		&location.ParentID,
@@ -222,6 +223,43 @@ func (r *LocationRepository) GetByBarcode(ctx context.Context, barcode string) (
 	return &location, nil
 }
 
+func (r *LocationRepository) ReplaceBarcode(ctx context.Context, entry domain.ReplacedBarcode) error {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.ReadCommitted,
+		AccessMode: pgx.ReadWrite,
+	})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	res, err := tx.Exec(ctx, `
+		UPDATE locations
+		SET barcode = $3, updated_at = NOW()
+		WHERE id = $1 AND barcode = $2
+	`, entry.LocationID, entry.Barcode, entry.ReplacedBy)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return domain.ErrBarcodeExists
+		}
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	if err := insertReplacedBarcode(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *LocationRepository) GetReplacedBarcodes(ctx context.Context, id uuid.UUID) ([]*domain.ReplacedBarcode, error) {
+	return listReplacedBarcodes(ctx, r.db, "location_id", id)
+}
+
 func (r *LocationRepository) HasChildren(ctx context.Context, id uuid.UUID) (bool, error) {
 	var exists bool
 	err := r.db.QueryRow(ctx, `
@@ -252,3 +290,55 @@ func (r *LocationRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+func (r *LocationRepository) GetSubtree(ctx context.Context, id uuid.UUID) ([]*domain.Location, error) {
+	rows, err := r.db.Query(ctx, `
+		WITH RECURSIVE subtree AS (
+			SELECT l.*, 0 AS depth
+			FROM locations l
+			WHERE l.id = $1
+			UNION ALL
+			SELECT c.*, s.depth + 1
+			FROM locations c
+			JOIN subtree s ON c.parent_id = s.id
+		)
+		SELECT id, parent_id, type, name, barcode, address, description, created_at, updated_at
+		FROM subtree
+		ORDER BY depth, name
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var locations []*domain.Location
+	for rows.Next() {
+		var loc domain.Location
+
+		if err := rows.Scan(
+			&loc.ID,
+			&loc.ParentID,
+			&loc.Type,
+			&loc.Name,
+			&loc.Barcode,
+			&loc.Address,
+			&loc.Description,
+			&loc.CreatedAt,
+			&loc.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		locations = append(locations, &loc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(locations) == 0 {
+		return nil, repository.ErrNotFound
+	}
+
+	return locations, nil
+}
@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+	"sort"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OutboxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewOutboxRepository(db *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+var _ repository.OutboxRepository = (*OutboxRepository)(nil)
+
+func (r *OutboxRepository) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxMessage, error) {
+	rows, err := r.db.Query(ctx, `
+		UPDATE outbox o
+		SET available_at = NOW() + make_interval(secs => $2)
+		FROM (
+			SELECT id
+			FROM outbox
+			WHERE available_at <= NOW()
+			ORDER BY available_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		) due
+		WHERE o.id = due.id
+		RETURNING o.id, o.queue, COALESCE(o.correlation_id, ''), COALESCE(o.reply_to, ''), o.payload,
+		          o.attempts, o.last_error, o.available_at, o.created_at
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	msgs := make([]*domain.OutboxMessage, 0)
+	for rows.Next() {
+		var msg domain.OutboxMessage
+		if err := rows.Scan(
+			&msg.ID,
+			&msg.Queue,
+			&msg.CorrelationID,
+			&msg.ReplyTo,
+			&msg.Payload,
+			&msg.Attempts,
+			&msg.LastError,
+			&msg.AvailableAt,
+			&msg.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, &msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep the order of the subquery.
+	sort.Slice(msgs, func(i, j int) bool { return msgs[i].ID < msgs[j].ID })
+	return msgs, nil
+}
+
+func (r *OutboxRepository) MarkSent(ctx context.Context, id int64) error {
+	_, err := r.db.Exec(ctx, `DELETE FROM outbox WHERE id = $1`, id)
+	return err
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id int64, errMsg string, availableAt time.Time) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE outbox
+		SET attempts = attempts + 1, last_error = $2, available_at = $3
+		WHERE id = $1
+	`, id, errMsg, availableAt)
+	return err
+}
+
+func (r *OutboxRepository) Release(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := r.db.Exec(ctx, `
+		UPDATE outbox
+		SET available_at = NOW()
+		WHERE id = ANY($1::bigint[])
+	`, ids)
+	return err
+}
+
+func insertOutboxMessage(ctx context.Context, tx pgx.Tx, msg domain.OutboxMessage) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO outbox (queue, correlation_id, reply_to, payload)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4)
+	`, msg.Queue, msg.CorrelationID, msg.ReplyTo, msg.Payload)
+	return err
+}
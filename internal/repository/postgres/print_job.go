@@ -0,0 +1,245 @@
+package postgres
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PrintJobRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPrintJobRepository(db *pgxpool.Pool) *PrintJobRepository {
+	return &PrintJobRepository{db: db}
+}
+
+func (r *PrintJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.PrintJob, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, requested_by, payload, status, error, attempts, claimed_at, created_at, updated_at
+		FROM print_jobs
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := scanPrintJobs(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return jobs[0], nil
+}
+
+func (r *PrintJobRepository) List(ctx context.Context, filter repository.PrintJobFilter) ([]*domain.PrintJob, error) {
+	var status *string
+	if filter.Status != nil {
+		s := string(*filter.Status)
+		status = &s
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, requested_by, payload, status, error, attempts, claimed_at, created_at, updated_at
+		FROM print_jobs
+		WHERE ($1::text IS NULL OR status = $1)
+			AND ($2::uuid IS NULL OR requested_by = $2)
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`, status, filter.RequestedBy, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := scanPrintJobs(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return jobs, nil
+}
+
+func (r *PrintJobRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.PrintJobStatus, errMsg *string) error {
+	res, err := r.db.Exec(ctx, `
+		UPDATE print_jobs
+		SET status = $2, error = $3, updated_at = NOW()
+		WHERE id = $1
+	`, id, status, errMsg)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PrintJobRepository) ClaimNext(ctx context.Context) (*domain.PrintJob, error) {
+	rows, err := r.db.Query(ctx, `
+		UPDATE print_jobs
+		SET status = 'printing', claimed_at = NOW(), updated_at = NOW()
+		WHERE id = (
+			SELECT id
+			FROM print_jobs
+			WHERE status = 'queued' AND (retry_at IS NULL OR retry_at <= NOW())
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, requested_by, payload, status, error, attempts, claimed_at, created_at, updated_at
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := scanPrintJobs(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return jobs[0], nil
+}
+
+func (r *PrintJobRepository) StaleClaims(ctx context.Context, claimedBefore time.Time) ([]*domain.PrintJob, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, requested_by, payload, status, error, attempts, claimed_at, created_at, updated_at
+		FROM print_jobs
+		WHERE status = 'printing' AND claimed_at < $1
+		ORDER BY claimed_at
+	`, claimedBefore)
+	if err != nil {
+		return nil, err
+	}
+	return scanPrintJobs(rows)
+}
+
+func (r *PrintJobRepository) FinishClaim(ctx context.Context, id uuid.UUID, claimedAt time.Time, status domain.PrintJobStatus, errMsg *string) error {
+	res, err := r.db.Exec(ctx, `
+		UPDATE print_jobs
+		SET status = $3, error = $4, updated_at = NOW()
+		WHERE id = $1 AND status = 'printing' AND claimed_at = $2
+	`, id, claimedAt, status, errMsg)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PrintJobRepository) RequeueClaim(ctx context.Context, id uuid.UUID, claimedAt time.Time, errMsg *string, retryAt time.Time) error {
+	res, err := r.db.Exec(ctx, `
+		UPDATE print_jobs
+		SET status = 'queued', error = $3, retry_at = $4, attempts = attempts + 1, updated_at = NOW()
+		WHERE id = $1 AND status = 'printing' AND claimed_at = $2
+	`, id, claimedAt, errMsg, retryAt)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *PrintJobRepository) WithTx(ctx context.Context, fn func(tx repository.PrintJobTx) error) error {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.ReadCommitted,
+		AccessMode: pgx.ReadWrite,
+	})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(&printJobTx{tx: tx}); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+type printJobTx struct {
+	tx pgx.Tx
+}
+
+var _ repository.PrintJobTx = (*printJobTx)(nil)
+
+func (t *printJobTx) CreatePrintJob(ctx context.Context, job domain.PrintJob) error {
+	return insertPrintJob(ctx, t.tx, job)
+}
+
+func (t *printJobTx) RequeuePrintJob(ctx context.Context, id uuid.UUID) error {
+	return requeuePrintJob(ctx, t.tx, id)
+}
+
+func (t *printJobTx) EnqueueMessage(ctx context.Context, msg domain.OutboxMessage) error {
+	return insertOutboxMessage(ctx, t.tx, msg)
+}
+
+func insertPrintJob(ctx context.Context, tx pgx.Tx, job domain.PrintJob) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO print_jobs (id, requested_by, payload, status)
+		VALUES ($1, $2, $3, $4)
+	`, job.ID, job.RequestedBy, job.Payload, job.Status)
+	return err
+}
+
+func requeuePrintJob(ctx context.Context, tx pgx.Tx, id uuid.UUID) error {
+	res, err := tx.Exec(ctx, `
+		UPDATE print_jobs
+		SET status = 'queued', error = NULL, retry_at = NULL, attempts = attempts + 1, updated_at = NOW()
+		WHERE id = $1 AND status = 'failed'
+	`, id)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func scanPrintJobs(rows pgx.Rows) ([]*domain.PrintJob, error) {
+	defer rows.Close()
+
+	jobs := make([]*domain.PrintJob, 0)
+	for rows.Next() {
+		var (
+			job    domain.PrintJob
+			status string
+		)
+		if err := rows.Scan(
+			&job.ID,
+			&job.RequestedBy,
+			&job.Payload,
+			&status,
+			&job.Error,
+			&job.Attempts,
+			&job.ClaimedAt,
+			&job.CreatedAt,
+			&job.UpdatedAt,
+		); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, repository.ErrNotFound
+			}
+			return nil, err
+		}
+		job.Status = domain.PrintJobStatus(status)
+		jobs = append(jobs, &job)
+	}
+	return jobs, rows.Err()
+}
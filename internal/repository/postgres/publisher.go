@@ -6,6 +6,7 @@ import (
 	"elibrary/internal/readmodel"
 	"elibrary/internal/repository"
 	"errors"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -131,3 +132,37 @@ func (r *PublisherRepository) GetAll(ctx context.Context) ([]readmodel.Publisher
 
 	return res, nil
 }
+
+func (r *PublisherRepository) FindByNames(ctx context.Context, names []string) ([]readmodel.Publisher, error) {
+	lowered := make([]string, 0, len(names))
+	for _, name := range names {
+		lowered = append(lowered, strings.ToLower(name))
+	}
+
+	rows, err := r.db.Query(ctx, `
+		SELECT id, name
+		FROM publishers
+		WHERE lower(name) = ANY($1::text[])
+		ORDER BY name
+	`, lowered)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []readmodel.Publisher
+	for rows.Next() {
+		var publisher readmodel.Publisher
+
+		if err := rows.Scan(&publisher.ID, &publisher.Name); err != nil {
+			return nil, err
+		}
+		res = append(res, publisher)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
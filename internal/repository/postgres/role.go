@@ -7,6 +7,7 @@ import (
 	"elibrary/internal/repository"
 	"errors"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -23,7 +24,7 @@ func NewRoleRepository(db *pgxpool.Pool) *RoleRepository {
 func (r *RoleRepository) GetAllWithPermissions(ctx context.Context) ([]*readmodel.RoleWithPermissions, error) {
 	rows, err := r.db.Query(ctx, `
 		SELECT
-			rl.id, rl.code, rl.name,
+			rl.id, rl.code, rl.name, rl.is_system,
 			p.id, p.code, p.name
 		FROM roles rl
 		LEFT JOIN role_permissions rp ON rp.role_id = rl.id
@@ -33,6 +34,58 @@ func (r *RoleRepository) GetAllWithPermissions(ctx context.Context) ([]*readmode
 	if err != nil {
 		return nil, err
 	}
+
+	return scanRolesWithPermissions(rows)
+}
+
+func (r *RoleRepository) GetByID(ctx context.Context, id int) (*readmodel.RoleWithPermissions, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT
+			rl.id, rl.code, rl.name, rl.is_system,
+			p.id, p.code, p.name
+		FROM roles rl
+		LEFT JOIN role_permissions rp ON rp.role_id = rl.id
+		LEFT JOIN permissions p ON p.id = rp.permission_id
+		WHERE rl.id = $1
+		ORDER BY p.code
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := scanRolesWithPermissions(rows)
+	if err != nil {
+		return nil, err
+	}
+	return roles[0], nil
+}
+
+func (r *RoleRepository) GetByCode(ctx context.Context, code string) (*readmodel.RoleWithPermissions, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT
+			rl.id, rl.code, rl.name, rl.is_system,
+			p.id, p.code, p.name
+		FROM roles rl
+		LEFT JOIN role_permissions rp ON rp.role_id = rl.id
+		LEFT JOIN permissions p ON p.id = rp.permission_id
+		WHERE rl.code = $1
+		ORDER BY p.code
+	`, code)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := scanRolesWithPermissions(rows)
+	if err != nil {
+		return nil, err
+	}
+	return roles[0], nil
+}
+
+// scanRolesWithPermissions folds role/permission join rows into roles,
+// preserving the order of the query. It returns repository.ErrNotFound
+// when no rows were read.
+func scanRolesWithPermissions(rows pgx.Rows) ([]*readmodel.RoleWithPermissions, error) {
 	defer rows.Close()
 
 	rolesByID := make(map[int]*readmodel.RoleWithPermissions)
@@ -40,9 +93,10 @@ func (r *RoleRepository) GetAllWithPermissions(ctx context.Context) ([]*readmode
 
 	for rows.Next() {
 		var (
-			roleID   int
-			roleCode string
-			roleName string
+			roleID       int
+			roleCode     string
+			roleName     string
+			roleIsSystem bool
 
 			permID   *int
 			permCode *string
@@ -52,6 +106,7 @@ func (r *RoleRepository) GetAllWithPermissions(ctx context.Context) ([]*readmode
 			&roleID,
 			&roleCode,
 			&roleName,
+			&roleIsSystem,
 			&permID,
 			&permCode,
 			&permName,
@@ -62,9 +117,11 @@ func (r *RoleRepository) GetAllWithPermissions(ctx context.Context) ([]*readmode
 		role, ok := rolesByID[roleID]
 		if !ok {
 			role = &readmodel.RoleWithPermissions{
-				ID:   roleID,
-				Code: roleCode,
-				Name: roleName,
+				ID:          roleID,
+				Code:        roleCode,
+				Name:        roleName,
+				IsSystem:    roleIsSystem,
+				Permissions: make([]readmodel.Permission, 0),
 			}
 			rolesByID[roleID] = role
 			order = append(order, roleID)
@@ -145,18 +202,143 @@ func (r *RoleRepository) Create(ctx context.Context, code, name string, permissi
 		return err
 	}
 
-	if len(permissionCodes) > 0 {
-		_, err = tx.Exec(ctx, `
-			INSERT INTO role_permissions (role_id, permission_id)
-			SELECT $1, p.id
-			FROM permissions p
-			WHERE p.code = ANY($2::text[])
-		`, roleID, permissionCodes)
-		if err != nil {
-			return err
-		}
+	if err := setRolePermissions(ctx, tx, roleID, permissionCodes); err != nil {
+		return err
 	}
 
 	return tx.Commit(ctx)
 }
 
+func (r *RoleRepository) Update(ctx context.Context, id int, name string, permissionCodes []string) error {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.ReadCommitted,
+		AccessMode: pgx.ReadWrite,
+	})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	res, err := tx.Exec(ctx, `
+		UPDATE roles
+		SET name = $2
+		WHERE id = $1
+	`, id, name)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	_, err = tx.Exec(ctx, `
+		DELETE FROM role_permissions
+		WHERE role_id = $1
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	if err := setRolePermissions(ctx, tx, id, permissionCodes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// setRolePermissions grants the listed permissions to the role. Every code
+// must exist in the catalog, otherwise domain.ErrUnknownPermission is returned
+// and the caller's transaction should be rolled back.
+func setRolePermissions(ctx context.Context, tx pgx.Tx, roleID int, permissionCodes []string) error {
+	if len(permissionCodes) == 0 {
+		return nil
+	}
+
+	var known int
+	err := tx.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM permissions
+		WHERE code = ANY($1::text[])
+	`, permissionCodes).Scan(&known)
+	if err != nil {
+		return err
+	}
+	if known != len(permissionCodes) {
+		return domain.ErrUnknownPermission
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO role_permissions (role_id, permission_id)
+		SELECT $1, p.id
+		FROM permissions p
+		WHERE p.code = ANY($2::text[])
+	`, roleID, permissionCodes)
+	return err
+}
+
+func (r *RoleRepository) Delete(ctx context.Context, id int) error {
+	res, err := r.db.Exec(ctx, `
+		DELETE FROM roles
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *RoleRepository) AssignToUser(ctx context.Context, userID uuid.UUID, roleID int) error {
+	_, err := r.db.Exec(ctx, `
+		INSERT INTO user_roles (user_id, role_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, userID, roleID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return repository.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *RoleRepository) RevokeFromUser(ctx context.Context, userID uuid.UUID, roleID int) error {
+	res, err := r.db.Exec(ctx, `
+		DELETE FROM user_roles
+		WHERE user_id = $1 AND role_id = $2
+	`, userID, roleID)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *RoleRepository) UserHasRole(ctx context.Context, userID uuid.UUID, roleID int) (bool, error) {
+	var holds bool
+	err := r.db.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM user_roles
+			WHERE user_id = $1 AND role_id = $2
+		)
+	`, userID, roleID).Scan(&holds)
+	return holds, err
+}
+
+func (r *RoleRepository) CountOtherActiveHolders(ctx context.Context, roleID int, userID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*)
+		FROM user_roles ur
+		JOIN users u ON u.id = ur.user_id
+		WHERE ur.role_id = $1 AND ur.user_id <> $2 AND u.is_active
+	`, roleID, userID).Scan(&count)
+	return count, err
+}
@@ -3,7 +3,12 @@ package postgres
 import (
 	"context"
 	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+	"errors"
+	"fmt"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -19,23 +24,137 @@ func (r *SequenceRepository) GetNext(ctx context.Context, t domain.BarcodeType)
 	err = r.db.QueryRow(ctx, `
 		UPDATE barcode_sequences
 		SET last_value = last_value + 1
-		WHERE type = $1
+		WHERE type = $1 AND active
 		RETURNING last_value, prefix
 	`, t).Scan(&seq, &prefix)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = repository.ErrNotFound
+	}
 
 	return
 }
 
-func (r *SequenceRepository) SetType(ctx context.Context, t domain.BarcodeType, prefix int, description string) error {
+func (r *SequenceRepository) GetNextRange(ctx context.Context, t domain.BarcodeType, n int) (first int64, prefix int, err error) {
+	var last int64
+	err = r.db.QueryRow(ctx, `
+		UPDATE barcode_sequences
+		SET last_value = last_value + $2
+		WHERE type = $1 AND active
+		RETURNING last_value, prefix
+	`, t, n).Scan(&last, &prefix)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, 0, repository.ErrNotFound
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return last - int64(n) + 1, prefix, nil
+}
+
+const sequenceColumns = `prefix, type, last_value, description, active, created_at, updated_at`
+
+func scanSequence(row pgx.Row) (*domain.BarcodeSequence, error) {
+	var s domain.BarcodeSequence
+	if err := row.Scan(&s.Prefix, &s.Type, &s.LastValue, &s.Description, &s.Active, &s.CreatedAt, &s.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *SequenceRepository) List(ctx context.Context) ([]*domain.BarcodeSequence, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT `+sequenceColumns+`
+		FROM barcode_sequences
+		ORDER BY type, prefix
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []*domain.BarcodeSequence
+	for rows.Next() {
+		s, err := scanSequence(rows)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, repository.ErrNotFound
+	}
+
+	return res, nil
+}
+
+func (r *SequenceRepository) GetByPrefix(ctx context.Context, prefix int) (*domain.BarcodeSequence, error) {
+	s, err := scanSequence(r.db.QueryRow(ctx, `
+		SELECT `+sequenceColumns+`
+		FROM barcode_sequences
+		WHERE prefix = $1
+	`, prefix))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return s, nil
+}
+
+func (r *SequenceRepository) Create(ctx context.Context, seq domain.BarcodeSequence) error {
 	_, err := r.db.Exec(ctx, `
-		INSERT INTO barcode_sequences (type, prefix, description)
-		VALUES ($1, $2, $3)
-		ON CONFLICT (type)
-		DO UPDATE SET
-		    prefix = EXCLUDED.prefix,
-		    description = EXCLUDED.description,
-		    updated_at = NOW()
-	`, t, prefix, description)
-
-	return err
+		INSERT INTO barcode_sequences (prefix, type, last_value, description, active)
+		VALUES ($1, $2, $3, $4, false)
+	`, seq.Prefix, seq.Type, seq.LastValue, seq.Description)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "23505":
+				return domain.ErrSequenceExists
+			case "23514":
+				return fmt.Errorf("%w: prefix %d is outside the range of %s", domain.ErrInvalidInput, seq.Prefix, seq.Type)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *SequenceRepository) Activate(ctx context.Context, prefix int) error {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.ReadCommitted,
+		AccessMode: pgx.ReadWrite,
+	})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var t domain.BarcodeType
+	err = tx.QueryRow(ctx, `SELECT type FROM barcode_sequences WHERE prefix = $1 FOR UPDATE`, prefix).Scan(&t)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return repository.ErrNotFound
+		}
+		return err
+	}
+
+	// The old active row has to be switched off first, otherwise the
+	// partial unique index on the active type rejects the update.
+	if _, err := tx.Exec(ctx, `
+		UPDATE barcode_sequences SET active = false WHERE type = $1 AND active AND prefix <> $2
+	`, t, prefix); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `UPDATE barcode_sequences SET active = true WHERE prefix = $1`, prefix); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
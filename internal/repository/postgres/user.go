@@ -134,6 +134,69 @@ func (r *UserRepository) Update(ctx context.Context, user domain.User) error {
 	return tx.Commit(ctx)
 }
 
+func (r *UserRepository) UpdateProfile(ctx context.Context, id uuid.UUID, profile repository.UserProfile) error {
+	res, err := r.db.Exec(ctx, `
+		UPDATE users
+		SET
+		    first_name = $2,
+		    last_name = $3,
+		    middle_name = $4,
+		    email = $5,
+		    updated_at = NOW()
+		WHERE id = $1
+	`,
+		id,
+		profile.FirstName,
+		profile.LastName,
+		profile.MiddleName,
+		profile.Email,
+	)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
+		IsoLevel:   pgx.ReadCommitted,
+		AccessMode: pgx.ReadWrite,
+	})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	res, err := tx.Exec(ctx, `
+		UPDATE users
+		SET
+		    password_hash = $2,
+		    token_version = token_version + 1,
+		    updated_at = NOW()
+		WHERE id = $1
+	`, id, passwordHash)
+	if err != nil {
+		return err
+	}
+	if res.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
 func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	tx, err := r.db.BeginTx(ctx, pgx.TxOptions{
 		IsoLevel:   pgx.ReadCommitted,
@@ -170,7 +233,7 @@ func (r *UserRepository) GetByLogin(ctx context.Context, login string) (*domain.
 	var user domain.User
 
 	err := r.db.QueryRow(ctx, `
-		SELECT id, login, first_name, last_name, middle_name, email, password_hash, is_active, created_at, updated_at
+		SELECT id, login, first_name, last_name, middle_name, email, password_hash, is_active, token_version, created_at, updated_at
 		FROM users
 		WHERE login = $1
 	`, login).Scan(
@@ -182,6 +245,7 @@ func (r *UserRepository) GetByLogin(ctx context.Context, login string) (*domain.
 		&user.Email,
 		&user.PasswordHash,
 		&user.IsActive,
+		&user.TokenVersion,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -199,7 +263,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Use
 	var user domain.User
 
 	err := r.db.QueryRow(ctx, `
-		SELECT id, login, first_name, last_name, middle_name, email, password_hash, is_active, created_at, updated_at
+		SELECT id, login, first_name, last_name, middle_name, email, password_hash, is_active, token_version, created_at, updated_at
 		FROM users
 		WHERE id = $1
 	`, id).Scan(
@@ -211,6 +275,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Use
 		&user.Email,
 		&user.PasswordHash,
 		&user.IsActive,
+		&user.TokenVersion,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
@@ -228,7 +293,7 @@ func (r *UserRepository) GetByIDWithRoles(ctx context.Context, id uuid.UUID) (*d
 	rows, err := r.db.Query(ctx, `
 		SELECT
 			u.id, u.login, u.first_name, u.last_name, u.middle_name, u.email,
-			u.password_hash, u.is_active, u.created_at, u.updated_at,
+			u.password_hash, u.is_active, u.token_version, u.created_at, u.updated_at,
 			r.id, r.code, r.name
 		FROM users u
 		LEFT JOIN user_roles ur ON ur.user_id = u.id
@@ -259,6 +324,7 @@ func (r *UserRepository) GetByIDWithRoles(ctx context.Context, id uuid.UUID) (*d
 			&u.Email,
 			&u.PasswordHash,
 			&u.IsActive,
+			&u.TokenVersion,
 			&u.CreatedAt,
 			&u.UpdatedAt,
 			&roleID,
@@ -282,13 +348,51 @@ func (r *UserRepository) GetByIDWithRoles(ctx context.Context, id uuid.UUID) (*d
 		}
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
 	if user == nil {
 		return nil, repository.ErrNotFound
 	}
 
+	permissions, err := r.getPermissions(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	user.Permissions = permissions
+
 	return user, nil
 }
 
+// getPermissions returns the effective permission codes granted to the user
+// through all of their roles.
+func (r *UserRepository) getPermissions(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT DISTINCT p.code
+		FROM user_roles ur
+		JOIN role_permissions rp ON rp.role_id = ur.role_id
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE ur.user_id = $1
+		ORDER BY p.code
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := make([]string, 0)
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, code)
+	}
+
+	return permissions, rows.Err()
+}
+
 func (r *UserRepository) GetAllWithRoles(ctx context.Context) ([]*domain.User, error) {
 	rows, err := r.db.Query(ctx, `
 		SELECT
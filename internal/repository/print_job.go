@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PrintJobFilter struct {
+	Status      *domain.PrintJobStatus
+	RequestedBy *uuid.UUID
+
+	Limit  int
+	Offset int
+}
+
+type PrintJobRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.PrintJob, error)
+	List(ctx context.Context, filter PrintJobFilter) ([]*domain.PrintJob, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, status domain.PrintJobStatus, errMsg *string) error
+	// ClaimNext marks the oldest queued job as printing and returns it. Jobs
+	// claimed by another worker or waiting for a retry are skipped. It
+	// returns ErrNotFound when nothing is queued.
+	ClaimNext(ctx context.Context) (*domain.PrintJob, error)
+	// StaleClaims lists jobs still printing that were claimed before the
+	// given time, most likely by a consumer that stopped mid-print.
+	StaleClaims(ctx context.Context, claimedBefore time.Time) ([]*domain.PrintJob, error)
+	// FinishClaim sets the final status of a job claimed at claimedAt. It
+	// returns ErrNotFound if the job was finished or claimed again since.
+	FinishClaim(ctx context.Context, id uuid.UUID, claimedAt time.Time, status domain.PrintJobStatus, errMsg *string) error
+	// RequeueClaim moves a job claimed at claimedAt back to queued, counts
+	// the attempt and keeps it from being claimed before retryAt. It returns
+	// ErrNotFound if the job was finished or claimed again since.
+	RequeueClaim(ctx context.Context, id uuid.UUID, claimedAt time.Time, errMsg *string, retryAt time.Time) error
+
+	WithTx(ctx context.Context, fn func(tx PrintJobTx) error) error
+}
+
+// PrintJobTx records print jobs together with their outbox messages. Book
+// transactions embed it so that a label can be scheduled atomically with
+// the change that needs it.
+type PrintJobTx interface {
+	OutboxWriter
+
+	CreatePrintJob(ctx context.Context, job domain.PrintJob) error
+	// RequeuePrintJob moves a failed job back to queued and counts the
+	// attempt. It returns ErrNotFound if the job does not exist or has not
+	// failed.
+	RequeuePrintJob(ctx context.Context, id uuid.UUID) error
+}
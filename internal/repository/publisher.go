@@ -15,4 +15,7 @@ type PublisherRepository interface {
 	Delete(ctx context.Context, id uuid.UUID) error
 
 	GetAll(ctx context.Context) ([]readmodel.Publisher, error)
+	// FindByNames returns the publishers whose name equals one of names,
+	// ignoring case.
+	FindByNames(ctx context.Context, names []string) ([]readmodel.Publisher, error)
 }
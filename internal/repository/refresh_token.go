@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"elibrary/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token domain.RefreshToken) error
+	GetByHash(ctx context.Context, hash string) (*domain.RefreshToken, error)
+	// Rotate revokes the old token and stores its replacement atomically.
+	// It returns ErrNotFound if the old token was already revoked.
+	Rotate(ctx context.Context, oldID uuid.UUID, next domain.RefreshToken) error
+	Revoke(ctx context.Context, id uuid.UUID) error
+	// RevokeAllForUser revokes every refresh token of the user and bumps the
+	// user's token version so that issued access tokens stop being accepted.
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID) error
+}
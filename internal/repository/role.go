@@ -3,11 +3,21 @@ package repository
 import (
 	"context"
 	"elibrary/internal/readmodel"
+
+	"github.com/google/uuid"
 )
 
 type RoleRepository interface {
 	GetAllWithPermissions(ctx context.Context) ([]*readmodel.RoleWithPermissions, error)
 	GetAllPermissions(ctx context.Context) ([]*readmodel.Permission, error)
+	GetByID(ctx context.Context, id int) (*readmodel.RoleWithPermissions, error)
+	GetByCode(ctx context.Context, code string) (*readmodel.RoleWithPermissions, error)
 	Create(ctx context.Context, code, name string, permissionCodes []string) error
-}
+	Update(ctx context.Context, id int, name string, permissionCodes []string) error
+	Delete(ctx context.Context, id int) error
 
+	AssignToUser(ctx context.Context, userID uuid.UUID, roleID int) error
+	RevokeFromUser(ctx context.Context, userID uuid.UUID, roleID int) error
+	UserHasRole(ctx context.Context, userID uuid.UUID, roleID int) (bool, error)
+	CountOtherActiveHolders(ctx context.Context, roleID int, userID uuid.UUID) (int, error)
+}
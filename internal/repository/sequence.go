@@ -6,6 +6,14 @@ import (
 )
 
 type SequenceRepository interface {
+	// GetNext takes the next serial from the active sequence of type t.
 	GetNext(ctx context.Context, t domain.BarcodeType) (int64, int, error)
-	SetType(ctx context.Context, t domain.BarcodeType, prefix int, description string) error
+	// GetNextRange takes n consecutive serials and returns the first one.
+	GetNextRange(ctx context.Context, t domain.BarcodeType, n int) (int64, int, error)
+
+	List(ctx context.Context) ([]*domain.BarcodeSequence, error)
+	GetByPrefix(ctx context.Context, prefix int) (*domain.BarcodeSequence, error)
+	Create(ctx context.Context, seq domain.BarcodeSequence) error
+	// Activate makes prefix the active sequence of its type.
+	Activate(ctx context.Context, prefix int) error
 }
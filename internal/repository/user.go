@@ -7,9 +7,24 @@ import (
 	"github.com/google/uuid"
 )
 
+// UserProfile holds the fields a user may change about themselves.
+type UserProfile struct {
+	FirstName  string
+	LastName   *string
+	MiddleName *string
+	Email      *string
+}
+
 type UserRepository interface {
 	Create(ctx context.Context, user domain.User) error
 	Update(ctx context.Context, user domain.User) error
+	// UpdateProfile changes only the profile fields and leaves login,
+	// password, activity and roles as they are.
+	UpdateProfile(ctx context.Context, id uuid.UUID, profile UserProfile) error
+	// UpdatePassword stores the new password hash and, in the same
+	// transaction, ends every session of the user: their refresh tokens are
+	// revoked and token_version is bumped.
+	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error
 	Delete(ctx context.Context, it uuid.UUID) error
 
 	GetByLogin(ctx context.Context, login string) (*domain.User, error)
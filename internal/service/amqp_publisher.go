@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+var (
+	ErrBrokerUnavailable = errors.New("message broker unavailable")
+	ErrPublishNotAcked   = errors.New("message was not confirmed by the broker")
+)
+
+const (
+	publisherMinBackoff = time.Second
+	publisherMaxBackoff = 30 * time.Second
+)
+
+// AMQPPublisher publishes over one long-lived connection. Channels are kept
+// in confirm mode and reused through a small pool; every message is sent as
+// persistent and Publish returns only after the broker confirms it.
+//
+// A lost connection is re-dialled on the next Publish. Failed dials back off
+// exponentially so that an unreachable broker is not hammered by every
+// request; in between Publish fails fast with ErrBrokerUnavailable.
+type AMQPPublisher struct {
+	url            string
+	confirmTimeout time.Duration
+	poolSize       int
+
+	mu       sync.Mutex
+	conn     *amqp.Connection
+	idle     []*amqp.Channel
+	declared map[string]bool
+	backoff  time.Duration
+	retryAt  time.Time
+	closed   bool
+}
+
+func NewAMQPPublisher(url string, confirmTimeout time.Duration, poolSize int) *AMQPPublisher {
+	if poolSize < 1 {
+		poolSize = 1
+	}
+	return &AMQPPublisher{
+		url:            url,
+		confirmTimeout: confirmTimeout,
+		poolSize:       poolSize,
+	}
+}
+
+// Publish sends msg to the durable queue named queue through the default
+// exchange and waits for the broker confirm.
+func (p *AMQPPublisher) Publish(ctx context.Context, queue string, msg amqp.Publishing) error {
+	ch, err := p.acquire(queue)
+	if err != nil {
+		return err
+	}
+
+	msg.DeliveryMode = amqp.Persistent
+	if msg.Timestamp.IsZero() {
+		msg.Timestamp = time.Now()
+	}
+
+	dc, err := ch.PublishWithDeferredConfirmWithContext(ctx, "", queue, false, false, msg)
+	if err != nil {
+		p.discard(ch)
+		return err
+	}
+
+	acked := make(chan bool, 1)
+	go func() { acked <- dc.Wait() }()
+
+	timer := time.NewTimer(p.confirmTimeout)
+	defer timer.Stop()
+
+	select {
+	case ok := <-acked:
+		if !ok {
+			// A nack or a closed channel: the channel may be gone, so do
+			// not hand it out again.
+			p.discard(ch)
+			return ErrPublishNotAcked
+		}
+		p.release(ch)
+		return nil
+	case <-timer.C:
+		// Closing the channel releases the Wait goroutine.
+		p.discard(ch)
+		return fmt.Errorf("%w: no confirm within %s", ErrPublishNotAcked, p.confirmTimeout)
+	case <-ctx.Done():
+		p.discard(ch)
+		return ctx.Err()
+	}
+}
+
+// Close shuts the connection down. Publish fails after Close.
+func (p *AMQPPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	p.idle = nil
+	if p.conn == nil {
+		return nil
+	}
+	err := p.conn.Close()
+	p.conn = nil
+	return err
+}
+
+func (p *AMQPPublisher) acquire(queue string) (*amqp.Channel, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed {
+		return nil, ErrBrokerUnavailable
+	}
+	if err := p.connectLocked(); err != nil {
+		return nil, err
+	}
+
+	var ch *amqp.Channel
+	for len(p.idle) > 0 && ch == nil {
+		last := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		if !last.IsClosed() {
+			ch = last
+		}
+	}
+	if ch == nil {
+		var err error
+		if ch, err = p.conn.Channel(); err != nil {
+			return nil, err
+		}
+		if err := ch.Confirm(false); err != nil {
+			_ = ch.Close()
+			return nil, err
+		}
+	}
+
+	if !p.declared[queue] {
+		if _, err := ch.QueueDeclare(queue, true, false, false, false, nil); err != nil {
+			_ = ch.Close()
+			return nil, err
+		}
+		p.declared[queue] = true
+	}
+
+	return ch, nil
+}
+
+func (p *AMQPPublisher) connectLocked() error {
+	if p.conn != nil && !p.conn.IsClosed() {
+		return nil
+	}
+
+	now := time.Now()
+	if now.Before(p.retryAt) {
+		return fmt.Errorf("%w: retrying in %s", ErrBrokerUnavailable, p.retryAt.Sub(now).Round(time.Millisecond))
+	}
+
+	conn, err := amqp.Dial(p.url)
+	if err != nil {
+		if p.backoff == 0 {
+			p.backoff = publisherMinBackoff
+		} else if p.backoff < publisherMaxBackoff {
+			p.backoff *= 2
+		}
+		p.retryAt = now.Add(p.backoff)
+		return fmt.Errorf("%w: %v", ErrBrokerUnavailable, err)
+	}
+
+	p.conn = conn
+	p.idle = nil
+	p.declared = make(map[string]bool)
+	p.backoff = 0
+	p.retryAt = time.Time{}
+
+	closed := conn.NotifyClose(make(chan *amqp.Error, 1))
+	go p.watch(conn, closed)
+
+	return nil
+}
+
+// watch drops the pooled state as soon as the broker closes the connection
+// so that the next Publish reconnects instead of failing on a dead channel.
+func (p *AMQPPublisher) watch(conn *amqp.Connection, closed <-chan *amqp.Error) {
+	err, ok := <-closed
+	if ok && err != nil {
+		log.Printf("rabbitmq connection lost: %v", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == conn {
+		p.conn = nil
+		p.idle = nil
+	}
+}
+
+func (p *AMQPPublisher) release(ch *amqp.Channel) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.closed || ch.IsClosed() || len(p.idle) >= p.poolSize {
+		_ = ch.Close()
+		return
+	}
+	p.idle = append(p.idle, ch)
+}
+
+func (p *AMQPPublisher) discard(ch *amqp.Channel) {
+	_ = ch.Close()
+}
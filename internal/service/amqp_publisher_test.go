@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func unreachableBrokerURL(t *testing.T) string {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+	return "amqp://guest:guest@" + addr + "/"
+}
+
+func TestAMQPPublisherBacksOffWhenBrokerIsDown(t *testing.T) {
+	t.Parallel()
+
+	p := NewAMQPPublisher(unreachableBrokerURL(t), time.Second, 2)
+
+	err := p.Publish(context.Background(), "print_queue", amqp.Publishing{Body: []byte("{}")})
+	if !errors.Is(err, ErrBrokerUnavailable) {
+		t.Fatalf("Publish() error = %v, want %v", err, ErrBrokerUnavailable)
+	}
+
+	err = p.Publish(context.Background(), "print_queue", amqp.Publishing{Body: []byte("{}")})
+	if !errors.Is(err, ErrBrokerUnavailable) || !strings.Contains(err.Error(), "retrying in") {
+		t.Fatalf("Publish() error = %v, want fast failure during backoff", err)
+	}
+	if p.backoff != publisherMinBackoff {
+		t.Fatalf("backoff = %v, want %v after one failed dial", p.backoff, publisherMinBackoff)
+	}
+}
+
+func TestAMQPPublisherClosed(t *testing.T) {
+	t.Parallel()
+
+	p := NewAMQPPublisher(unreachableBrokerURL(t), time.Second, 0)
+	if p.poolSize != 1 {
+		t.Fatalf("poolSize = %d, want 1", p.poolSize)
+	}
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if err := p.Publish(context.Background(), "print_queue", amqp.Publishing{}); !errors.Is(err, ErrBrokerUnavailable) {
+		t.Fatalf("Publish() error = %v, want %v", err, ErrBrokerUnavailable)
+	}
+}
@@ -2,43 +2,259 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
 	"elibrary/internal/domain"
 	"elibrary/internal/repository"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var ErrInvalidCredentials = errors.New("invalid credentials")
+var (
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+)
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
 
 type AuthService struct {
-	users repository.UserRepository
-	jwt   *JWTManager
+	users      repository.UserRepository
+	tokens     repository.RefreshTokenRepository
+	jwt        *JWTManager
+	refreshTTL time.Duration
+	policy     PasswordPolicy
 }
 
-func NewAuthService(users repository.UserRepository, jwt *JWTManager) *AuthService {
-	return &AuthService{users: users, jwt: jwt}
+func NewAuthService(
+	users repository.UserRepository,
+	tokens repository.RefreshTokenRepository,
+	jwt *JWTManager,
+	refreshTTL time.Duration,
+	policy PasswordPolicy,
+) *AuthService {
+	return &AuthService{users: users, tokens: tokens, jwt: jwt, refreshTTL: refreshTTL, policy: policy}
 }
 
-func (s *AuthService) Login(ctx context.Context, login, password string) (string, error) {
+func (s *AuthService) Login(ctx context.Context, login, password string) (*TokenPair, error) {
 	user, err := s.users.GetByLogin(ctx, login)
 	if err != nil || !user.IsActive {
-		return "", ErrInvalidCredentials
+		return nil, ErrInvalidCredentials
 	}
 
 	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
-		return "", ErrInvalidCredentials
+		return nil, ErrInvalidCredentials
 	}
 
-	return s.jwt.Generate(user.ID)
+	pair, token, err := s.issue(user)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokens.Create(ctx, *token); err != nil {
+		return nil, err
+	}
+	return pair, nil
 }
 
+// Refresh exchanges a refresh token for a new token pair. The presented token
+// is revoked; presenting an already revoked token is treated as theft and
+// revokes every session of its owner.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*TokenPair, error) {
+	current, err := s.tokens.GetByHash(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if current.IsRevoked() {
+		if err := s.tokens.RevokeAllForUser(ctx, current.UserID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+	if current.IsExpired(time.Now()) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.users.GetByID(ctx, current.UserID)
+	if err != nil || !user.IsActive {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	pair, next, err := s.issue(user)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokens.Rotate(ctx, current.ID, *next); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+	return pair, nil
+}
+
+// Logout revokes the given refresh token, or every session of its owner when
+// all is set. Unknown tokens are ignored so that logout is idempotent.
+func (s *AuthService) Logout(ctx context.Context, refreshToken string, all bool) error {
+	current, err := s.tokens.GetByHash(ctx, hashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if all {
+		return s.tokens.RevokeAllForUser(ctx, current.UserID)
+	}
+	return s.tokens.Revoke(ctx, current.ID)
+}
+
+// Me returns the user's profile together with their roles and effective
+// permissions.
 func (s *AuthService) Me(ctx context.Context, userID uuid.UUID) (*domain.User, error) {
-	user, err := s.users.GetByID(ctx, userID)
+	user, err := s.users.GetByIDWithRoles(ctx, userID)
 	if err != nil || !user.IsActive {
 		return nil, domain.ErrNotFound
 	}
 
 	return user, nil
 }
+
+type UpdateProfileRequest struct {
+	FirstName  *string `json:"first_name"`
+	LastName   *string `json:"last_name,omitempty"`
+	MiddleName *string `json:"middle_name,omitempty"`
+	Email      *string `json:"email,omitempty"`
+}
+
+// UpdateMe changes the user's own profile fields. Login, roles and activity
+// remain under administrator control.
+func (s *AuthService) UpdateMe(ctx context.Context, userID uuid.UUID, updates UpdateProfileRequest) (*domain.User, error) {
+	user, err := s.Me(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := repository.UserProfile{
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		MiddleName: user.MiddleName,
+		Email:      user.Email,
+	}
+	if updates.FirstName != nil {
+		firstName := strings.TrimSpace(*updates.FirstName)
+		if firstName == "" {
+			return nil, fmt.Errorf("%w: first name is required", domain.ErrInvalidInput)
+		}
+		profile.FirstName = firstName
+	}
+	if updates.LastName != nil {
+		profile.LastName = updates.LastName
+	}
+	if updates.MiddleName != nil {
+		profile.MiddleName = updates.MiddleName
+	}
+	if updates.Email != nil {
+		profile.Email = updates.Email
+	}
+
+	if err := s.users.UpdateProfile(ctx, user.ID, profile); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return s.Me(ctx, userID)
+}
+
+// ChangePassword replaces the user's password after checking the current one
+// and the password policy. All existing sessions are revoked and a fresh
+// token pair is returned for the caller.
+func (s *AuthService) ChangePassword(ctx context.Context, userID uuid.UUID, current, next string) (*TokenPair, error) {
+	user, err := s.Me(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(current)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if err := s.policy.Validate(next); err != nil {
+		return nil, err
+	}
+	if next == current {
+		return nil, fmt.Errorf("%w: must differ from the current password", ErrWeakPassword)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(next), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.users.UpdatePassword(ctx, user.ID, string(hash)); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	// Reload to pick up the token version bumped with the password.
+	user, err = s.users.GetByID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	pair, token, err := s.issue(user)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokens.Create(ctx, *token); err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+func (s *AuthService) issue(user *domain.User) (*TokenPair, *domain.RefreshToken, error) {
+	access, err := s.jwt.Generate(user.ID, user.TokenVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, nil, err
+	}
+	refresh := base64.RawURLEncoding.EncodeToString(raw)
+
+	pair := &TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		ExpiresIn:    int(s.jwt.TTL.Seconds()),
+	}
+	token := &domain.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		TokenHash: hashRefreshToken(refresh),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}
+	return pair, token, nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -14,13 +14,40 @@ import (
 )
 
 type stubAuthUserRepo struct {
-	getByLogin func(ctx context.Context, login string) (*domain.User, error)
-	getByID    func(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	getByLogin       func(ctx context.Context, login string) (*domain.User, error)
+	getByID          func(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	getByIDWithRoles func(ctx context.Context, id uuid.UUID) (*domain.User, error)
+	update           func(ctx context.Context, user domain.User) error
+	delete           func(ctx context.Context, id uuid.UUID) error
+	updateProfile    func(ctx context.Context, id uuid.UUID, profile repository.UserProfile) error
+	updatePassword   func(ctx context.Context, id uuid.UUID, passwordHash string) error
 }
 
 func (s stubAuthUserRepo) Create(ctx context.Context, user domain.User) error { return nil }
-func (s stubAuthUserRepo) Update(ctx context.Context, user domain.User) error { return nil }
-func (s stubAuthUserRepo) Delete(ctx context.Context, id uuid.UUID) error     { return nil }
+func (s stubAuthUserRepo) Update(ctx context.Context, user domain.User) error {
+	if s.update == nil {
+		return nil
+	}
+	return s.update(ctx, user)
+}
+func (s stubAuthUserRepo) UpdateProfile(ctx context.Context, id uuid.UUID, profile repository.UserProfile) error {
+	if s.updateProfile == nil {
+		return nil
+	}
+	return s.updateProfile(ctx, id, profile)
+}
+func (s stubAuthUserRepo) UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	if s.updatePassword == nil {
+		return nil
+	}
+	return s.updatePassword(ctx, id, passwordHash)
+}
+func (s stubAuthUserRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	if s.delete == nil {
+		return nil
+	}
+	return s.delete(ctx, id)
+}
 func (s stubAuthUserRepo) GetByLogin(ctx context.Context, login string) (*domain.User, error) {
 	return s.getByLogin(ctx, login)
 }
@@ -28,7 +55,10 @@ func (s stubAuthUserRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Us
 	return s.getByID(ctx, id)
 }
 func (s stubAuthUserRepo) GetByIDWithRoles(ctx context.Context, id uuid.UUID) (*domain.User, error) {
-	return nil, nil
+	if s.getByIDWithRoles == nil {
+		return nil, repository.ErrNotFound
+	}
+	return s.getByIDWithRoles(ctx, id)
 }
 func (s stubAuthUserRepo) GetAllWithRoles(ctx context.Context) ([]*domain.User, error) {
 	return nil, nil
@@ -36,6 +66,62 @@ func (s stubAuthUserRepo) GetAllWithRoles(ctx context.Context) ([]*domain.User,
 
 var _ repository.UserRepository = stubAuthUserRepo{}
 
+type stubRefreshTokenRepo struct {
+	tokens     map[string]*domain.RefreshToken
+	revokedAll []uuid.UUID
+}
+
+func newStubRefreshTokenRepo() *stubRefreshTokenRepo {
+	return &stubRefreshTokenRepo{tokens: make(map[string]*domain.RefreshToken)}
+}
+
+func (s *stubRefreshTokenRepo) Create(ctx context.Context, token domain.RefreshToken) error {
+	s.tokens[token.TokenHash] = &token
+	return nil
+}
+func (s *stubRefreshTokenRepo) GetByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	token, ok := s.tokens[hash]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *token
+	return &copied, nil
+}
+func (s *stubRefreshTokenRepo) Rotate(ctx context.Context, oldID uuid.UUID, next domain.RefreshToken) error {
+	for _, token := range s.tokens {
+		if token.ID == oldID {
+			if token.IsRevoked() {
+				return repository.ErrNotFound
+			}
+			now := time.Now()
+			token.RevokedAt = &now
+			token.ReplacedBy = &next.ID
+		}
+	}
+	return s.Create(ctx, next)
+}
+func (s *stubRefreshTokenRepo) Revoke(ctx context.Context, id uuid.UUID) error {
+	for _, token := range s.tokens {
+		if token.ID == id {
+			now := time.Now()
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+func (s *stubRefreshTokenRepo) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	s.revokedAll = append(s.revokedAll, userID)
+	for _, token := range s.tokens {
+		if token.UserID == userID && !token.IsRevoked() {
+			now := time.Now()
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+var _ repository.RefreshTokenRepository = (*stubRefreshTokenRepo)(nil)
+
 func TestAuthServiceLogin(t *testing.T) {
 	t.Parallel()
 
@@ -57,14 +143,14 @@ func TestAuthServiceLogin(t *testing.T) {
 				IsActive:     true,
 			}, nil
 		},
-	}, &JWTManager{Secret: []byte("secret"), TTL: time.Minute})
+	}, newStubRefreshTokenRepo(), &JWTManager{Secret: []byte("secret"), TTL: time.Minute}, time.Hour, PasswordPolicy{})
 
-	token, err := svc.Login(context.Background(), "reader", "secret")
+	tokens, err := svc.Login(context.Background(), "reader", "secret")
 	if err != nil {
 		t.Fatalf("Login() error = %v", err)
 	}
-	if token == "" {
-		t.Fatal("Login() returned empty token")
+	if tokens.AccessToken == "" || tokens.RefreshToken == "" {
+		t.Fatalf("Login() = %+v, want both tokens", tokens)
 	}
 }
 
@@ -115,7 +201,7 @@ func TestAuthServiceLoginRejectsInvalidCredentials(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			svc := NewAuthService(tt.repo, &JWTManager{Secret: []byte("secret"), TTL: time.Minute})
+			svc := NewAuthService(tt.repo, newStubRefreshTokenRepo(), &JWTManager{Secret: []byte("secret"), TTL: time.Minute}, time.Hour, PasswordPolicy{})
 			_, err := svc.Login(context.Background(), "reader", tt.pass)
 			if !errors.Is(err, ErrInvalidCredentials) {
 				t.Fatalf("Login() error = %v, want %v", err, ErrInvalidCredentials)
@@ -130,14 +216,13 @@ func TestAuthServiceMe(t *testing.T) {
 	userID := uuid.New()
 	wantUser := &domain.User{ID: userID, IsActive: true}
 	svc := NewAuthService(stubAuthUserRepo{
-		getByLogin: nil,
-		getByID: func(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+		getByIDWithRoles: func(ctx context.Context, id uuid.UUID) (*domain.User, error) {
 			if id != userID {
-				t.Fatalf("GetByID() id = %v, want %v", id, userID)
+				t.Fatalf("GetByIDWithRoles() id = %v, want %v", id, userID)
 			}
 			return wantUser, nil
 		},
-	}, &JWTManager{})
+	}, newStubRefreshTokenRepo(), &JWTManager{}, time.Hour, PasswordPolicy{})
 
 	got, err := svc.Me(context.Background(), userID)
 	if err != nil {
@@ -158,7 +243,7 @@ func TestAuthServiceMeNotFound(t *testing.T) {
 		{
 			name: "repo error",
 			repo: stubAuthUserRepo{
-				getByID: func(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+				getByIDWithRoles: func(ctx context.Context, id uuid.UUID) (*domain.User, error) {
 					return nil, errors.New("db error")
 				},
 			},
@@ -166,7 +251,7 @@ func TestAuthServiceMeNotFound(t *testing.T) {
 		{
 			name: "inactive",
 			repo: stubAuthUserRepo{
-				getByID: func(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+				getByIDWithRoles: func(ctx context.Context, id uuid.UUID) (*domain.User, error) {
 					return &domain.User{ID: id, IsActive: false}, nil
 				},
 			},
@@ -178,7 +263,7 @@ func TestAuthServiceMeNotFound(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			svc := NewAuthService(tt.repo, &JWTManager{})
+			svc := NewAuthService(tt.repo, newStubRefreshTokenRepo(), &JWTManager{}, time.Hour, PasswordPolicy{})
 			_, err := svc.Me(context.Background(), uuid.New())
 			if !errors.Is(err, domain.ErrNotFound) {
 				t.Fatalf("Me() error = %v, want %v", err, domain.ErrNotFound)
@@ -186,3 +271,202 @@ func TestAuthServiceMeNotFound(t *testing.T) {
 		})
 	}
 }
+
+func TestAuthServiceRefreshRotatesToken(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	tokens := newStubRefreshTokenRepo()
+	svc := NewAuthService(stubAuthUserRepo{
+		getByID: func(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+			return &domain.User{ID: id, IsActive: true}, nil
+		},
+	}, tokens, &JWTManager{Secret: []byte("secret"), TTL: time.Minute}, time.Hour, PasswordPolicy{})
+
+	first, _, err := svc.issue(&domain.User{ID: userID})
+	if err != nil {
+		t.Fatalf("issue() error = %v", err)
+	}
+	tokens.tokens[hashRefreshToken(first.RefreshToken)] = &domain.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TokenHash: hashRefreshToken(first.RefreshToken),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	second, err := svc.Refresh(context.Background(), first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken {
+		t.Fatal("Refresh() returned the same refresh token")
+	}
+
+	if _, err := svc.Refresh(context.Background(), first.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("Refresh() with reused token error = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+	if len(tokens.revokedAll) != 1 || tokens.revokedAll[0] != userID {
+		t.Fatalf("RevokeAllForUser() calls = %v, want [%v]", tokens.revokedAll, userID)
+	}
+	if _, err := svc.Refresh(context.Background(), second.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+		t.Fatalf("Refresh() after reuse error = %v, want %v", err, ErrInvalidRefreshToken)
+	}
+}
+
+func TestAuthServiceRefreshRejectsInvalidTokens(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	tests := []struct {
+		name   string
+		token  *domain.RefreshToken
+		active bool
+	}{
+		{name: "unknown", active: true},
+		{name: "expired", token: &domain.RefreshToken{UserID: userID, ExpiresAt: time.Now().Add(-time.Minute)}, active: true},
+		{name: "inactive user", token: &domain.RefreshToken{UserID: userID, ExpiresAt: time.Now().Add(time.Hour)}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			tokens := newStubRefreshTokenRepo()
+			if tt.token != nil {
+				tt.token.ID = uuid.New()
+				tt.token.TokenHash = hashRefreshToken("raw")
+				tokens.tokens[tt.token.TokenHash] = tt.token
+			}
+			svc := NewAuthService(stubAuthUserRepo{
+				getByID: func(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+					return &domain.User{ID: id, IsActive: tt.active}, nil
+				},
+			}, tokens, &JWTManager{Secret: []byte("secret"), TTL: time.Minute}, time.Hour, PasswordPolicy{})
+
+			if _, err := svc.Refresh(context.Background(), "raw"); !errors.Is(err, ErrInvalidRefreshToken) {
+				t.Fatalf("Refresh() error = %v, want %v", err, ErrInvalidRefreshToken)
+			}
+		})
+	}
+}
+
+func TestAuthServiceLogoutAll(t *testing.T) {
+	t.Parallel()
+
+	userID := uuid.New()
+	tokens := newStubRefreshTokenRepo()
+	tokens.tokens[hashRefreshToken("raw")] = &domain.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TokenHash: hashRefreshToken("raw"),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	svc := NewAuthService(stubAuthUserRepo{}, tokens, &JWTManager{}, time.Hour, PasswordPolicy{})
+
+	if err := svc.Logout(context.Background(), "unknown", true); err != nil {
+		t.Fatalf("Logout() with unknown token error = %v, want nil", err)
+	}
+	if err := svc.Logout(context.Background(), "raw", true); err != nil {
+		t.Fatalf("Logout() error = %v", err)
+	}
+	if len(tokens.revokedAll) != 1 || tokens.revokedAll[0] != userID {
+		t.Fatalf("RevokeAllForUser() calls = %v, want [%v]", tokens.revokedAll, userID)
+	}
+}
+
+func TestAuthServiceChangePassword(t *testing.T) {
+	t.Parallel()
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("old-secret1"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		current string
+		next    string
+		wantErr error
+	}{
+		{name: "wrong current", current: "nope", next: "new-secret1", wantErr: ErrInvalidCredentials},
+		{name: "weak", current: "old-secret1", next: "short", wantErr: ErrWeakPassword},
+		{name: "same", current: "old-secret1", next: "old-secret1", wantErr: ErrWeakPassword},
+		{name: "ok", current: "old-secret1", next: "new-secret1"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var saved string
+			tokens := newStubRefreshTokenRepo()
+			repo := stubAuthUserRepo{
+				getByIDWithRoles: func(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+					return &domain.User{ID: id, PasswordHash: string(passwordHash), IsActive: true}, nil
+				},
+				getByID: func(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+					return &domain.User{ID: id, IsActive: true, TokenVersion: 1}, nil
+				},
+				update: func(ctx context.Context, user domain.User) error {
+					t.Fatal("ChangePassword() rewrote the whole user")
+					return nil
+				},
+				updatePassword: func(ctx context.Context, id uuid.UUID, passwordHash string) error {
+					saved = passwordHash
+					return nil
+				},
+			}
+			svc := NewAuthService(repo, tokens, &JWTManager{Secret: []byte("secret"), TTL: time.Minute}, time.Hour,
+				PasswordPolicy{MinLength: 8, RequireDigit: true})
+
+			pair, err := svc.ChangePassword(context.Background(), uuid.New(), tt.current, tt.next)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChangePassword() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if saved != "" {
+					t.Fatal("ChangePassword() stored a password on failure")
+				}
+				return
+			}
+
+			if bcrypt.CompareHashAndPassword([]byte(saved), []byte(tt.next)) != nil {
+				t.Fatal("ChangePassword() did not store the new password hash")
+			}
+			if _, version, err := svc.jwt.Parse(pair.AccessToken); err != nil || version != 1 {
+				t.Fatalf("access token version = %d (err %v), want 1", version, err)
+			}
+		})
+	}
+}
+
+func TestAuthServiceUpdateMeKeepsRoles(t *testing.T) {
+	t.Parallel()
+
+	lastName := "Иванов"
+	firstName := " Петр "
+	var saved repository.UserProfile
+	repo := stubAuthUserRepo{
+		getByIDWithRoles: func(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+			return &domain.User{ID: id, FirstName: "Иван", IsActive: true, Roles: []domain.Role{{ID: 1, Code: "admin"}}}, nil
+		},
+		update: func(ctx context.Context, user domain.User) error {
+			t.Fatal("UpdateMe() rewrote the whole user")
+			return nil
+		},
+		updateProfile: func(ctx context.Context, id uuid.UUID, profile repository.UserProfile) error {
+			saved = profile
+			return nil
+		},
+	}
+	svc := NewAuthService(repo, newStubRefreshTokenRepo(), &JWTManager{Secret: []byte("secret"), TTL: time.Minute}, time.Hour, PasswordPolicy{})
+
+	if _, err := svc.UpdateMe(context.Background(), uuid.New(), UpdateProfileRequest{FirstName: &firstName, LastName: &lastName}); err != nil {
+		t.Fatalf("UpdateMe() error = %v", err)
+	}
+	if saved.FirstName != "Петр" || saved.LastName == nil || *saved.LastName != lastName {
+		t.Fatalf("UpdateProfile() = %+v, want the new names", saved)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"elibrary/internal/domain"
 	"elibrary/internal/repository"
+	"errors"
 	"fmt"
 	"image/png"
 	"strconv"
@@ -15,6 +16,12 @@ import (
 
 type BarcodeService struct {
 	seqRepo repository.SequenceRepository
+
+	// Symbologies pick the symbol printed on labels for each barcode type.
+	Symbologies Symbologies
+	// LowWatermark is how many codes may remain in a sequence before
+	// generation starts logging warnings. Zero disables the warnings.
+	LowWatermark int64
 }
 
 func NewBarcodeService(seqRepo repository.SequenceRepository) *BarcodeService {
@@ -26,12 +33,16 @@ func NewBarcodeService(seqRepo repository.SequenceRepository) *BarcodeService {
 func (s *BarcodeService) GenerateEAN13(ctx context.Context, t domain.BarcodeType) (string, error) {
 	sequence, prefix, err := s.seqRepo.GetNext(ctx, t)
 	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", fmt.Errorf("no active barcode sequence for %s", t)
+		}
 		return "", fmt.Errorf("failed to get barcode sequence: %w", err)
 	}
 
-	if sequence > 999999999 {
+	if sequence > domain.MaxBarcodeSequence {
 		return "", fmt.Errorf("barcode sequence overflow for prefix %d", prefix)
 	}
+	s.warnIfLow(t, prefix, domain.MaxBarcodeSequence-sequence)
 
 	base := fmt.Sprintf("%03d%09d", prefix, sequence)
 
@@ -42,6 +53,31 @@ func (s *BarcodeService) GenerateEAN13(ctx context.Context, t domain.BarcodeType
 	return ean13, nil
 }
 
+// ReserveEAN13 takes n consecutive codes of type t at once, e.g. for a block
+// of labels printed in advance.
+func (s *BarcodeService) ReserveEAN13(ctx context.Context, t domain.BarcodeType, n int) ([]string, error) {
+	first, prefix, err := s.seqRepo.GetNextRange(ctx, t, n)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("no active barcode sequence for %s", t)
+		}
+		return nil, fmt.Errorf("failed to get barcode sequence: %w", err)
+	}
+
+	last := first + int64(n) - 1
+	if last > domain.MaxBarcodeSequence {
+		return nil, fmt.Errorf("barcode sequence overflow for prefix %d", prefix)
+	}
+	s.warnIfLow(t, prefix, domain.MaxBarcodeSequence-last)
+
+	codes := make([]string, 0, n)
+	for seq := first; seq <= last; seq++ {
+		base := fmt.Sprintf("%03d%09d", prefix, seq)
+		codes = append(codes, base+strconv.Itoa(s.calculateEAN13Checksum(base)))
+	}
+	return codes, nil
+}
+
 func (s *BarcodeService) calculateEAN13Checksum(first12 string) int {
 	sum := 0
 	for i, ch := range first12 {
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrInvalidBatchSize = fmt.Errorf("count must be between 1 and %d", MaxLabelBatch)
+
+// BarcodeBatchService reserves blocks of book barcodes whose labels are
+// printed before the books are catalogued.
+type BarcodeBatchService struct {
+	repo      repository.BarcodeBatchRepository
+	barcodes  *BarcodeService
+	printJobs *PrintJobService
+}
+
+func NewBarcodeBatchService(repo repository.BarcodeBatchRepository, barcodes *BarcodeService, printJobs *PrintJobService) *BarcodeBatchService {
+	return &BarcodeBatchService{
+		repo:      repo,
+		barcodes:  barcodes,
+		printJobs: printJobs,
+	}
+}
+
+type ReserveBarcodesRequest struct {
+	Count int     `json:"count"`
+	Note  *string `json:"note,omitempty"`
+}
+
+type BarcodeBatchDetails struct {
+	domain.BarcodeBatch
+	Barcodes []*domain.ReservedBarcode `json:"barcodes"`
+}
+
+func (s *BarcodeBatchService) Reserve(ctx context.Context, req ReserveBarcodesRequest, actorID *uuid.UUID) (*BarcodeBatchDetails, error) {
+	if req.Count < 1 || req.Count > MaxLabelBatch {
+		return nil, ErrInvalidBatchSize
+	}
+	if req.Note != nil {
+		note := strings.TrimSpace(*req.Note)
+		req.Note = &note
+		if note == "" {
+			req.Note = nil
+		}
+	}
+
+	codes, err := s.barcodes.ReserveEAN13(ctx, domain.BarcodeTypeBook, req.Count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve barcodes: %w", err)
+	}
+
+	batch := domain.BarcodeBatch{
+		ID:        uuid.New(),
+		Count:     req.Count,
+		Note:      req.Note,
+		CreatedBy: actorID,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repo.Create(ctx, batch, codes); err != nil {
+		return nil, err
+	}
+
+	return s.Get(ctx, batch.ID)
+}
+
+func (s *BarcodeBatchService) List(ctx context.Context, limit, offset int) ([]*domain.BarcodeBatch, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	batches, err := s.repo.List(ctx, limit, offset)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return batches, nil
+}
+
+func (s *BarcodeBatchService) Get(ctx context.Context, id uuid.UUID) (*BarcodeBatchDetails, error) {
+	batch, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	codes, err := s.repo.GetBarcodes(ctx, id)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, err
+	}
+	if codes == nil {
+		codes = []*domain.ReservedBarcode{}
+	}
+
+	return &BarcodeBatchDetails{BarcodeBatch: *batch, Barcodes: codes}, nil
+}
+
+// Tasks builds one label per code of the batch not yet bound to a book. The
+// note goes on the first line so the volunteers can tell the blocks apart.
+func (s *BarcodeBatchService) Tasks(ctx context.Context, id uuid.UUID, symbology domain.Symbology) ([]PrintTask, error) {
+	batch, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var line string
+	if batch.Note != nil {
+		line = truncateLabelLine(*batch.Note)
+	}
+
+	tasks := make([]PrintTask, 0, len(batch.Barcodes))
+	for _, code := range batch.Barcodes {
+		if code.IsBound() {
+			continue
+		}
+		task := PrintTask{Str1: line, Barcode: code.Barcode}
+		if err := s.barcodes.ApplySymbology(&task, domain.BarcodeTypeBook, uuid.Nil, symbology); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	if len(tasks) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	return tasks, nil
+}
+
+func (s *BarcodeBatchService) Print(ctx context.Context, id uuid.UUID, symbology domain.Symbology, requestedBy *uuid.UUID) (*BatchPrintResult, error) {
+	tasks, err := s.Tasks(ctx, id, symbology)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := s.printJobs.SubmitBatch(ctx, tasks, requestedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &BatchPrintResult{Count: len(ids), JobIDs: ids}, nil
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type stubBarcodeBatchRepo struct {
+	batches map[uuid.UUID]*domain.BarcodeBatch
+	codes   map[uuid.UUID][]*domain.ReservedBarcode
+}
+
+func newStubBarcodeBatchRepo() *stubBarcodeBatchRepo {
+	return &stubBarcodeBatchRepo{
+		batches: make(map[uuid.UUID]*domain.BarcodeBatch),
+		codes:   make(map[uuid.UUID][]*domain.ReservedBarcode),
+	}
+}
+
+func (s *stubBarcodeBatchRepo) Create(ctx context.Context, batch domain.BarcodeBatch, barcodes []string) error {
+	s.batches[batch.ID] = &batch
+	for _, code := range barcodes {
+		s.codes[batch.ID] = append(s.codes[batch.ID], &domain.ReservedBarcode{Barcode: code, BatchID: batch.ID})
+	}
+	return nil
+}
+
+func (s *stubBarcodeBatchRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.BarcodeBatch, error) {
+	batch, ok := s.batches[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return batch, nil
+}
+
+func (s *stubBarcodeBatchRepo) List(ctx context.Context, limit, offset int) ([]*domain.BarcodeBatch, error) {
+	return nil, repository.ErrNotFound
+}
+
+func (s *stubBarcodeBatchRepo) GetBarcodes(ctx context.Context, batchID uuid.UUID) ([]*domain.ReservedBarcode, error) {
+	codes, ok := s.codes[batchID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return codes, nil
+}
+
+func newBatchTestService(repo *stubBarcodeBatchRepo) *BarcodeBatchService {
+	barcodes := NewBarcodeService(stubSequenceRepo{
+		getNextRange: func(ctx context.Context, t domain.BarcodeType, n int) (int64, int, error) {
+			if t != domain.BarcodeTypeBook {
+				return 0, 0, errors.New("unexpected barcode type")
+			}
+			return 1, 200, nil
+		},
+	})
+	return NewBarcodeBatchService(repo, barcodes, nil)
+}
+
+func TestBarcodeBatchServiceReserve(t *testing.T) {
+	t.Parallel()
+
+	repo := newStubBarcodeBatchRepo()
+	service := newBatchTestService(repo)
+	note := "  Коробка 12 "
+	actor := uuid.New()
+
+	got, err := service.Reserve(context.Background(), ReserveBarcodesRequest{Count: 3, Note: &note}, &actor)
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	if got.Count != 3 || len(got.Barcodes) != 3 {
+		t.Fatalf("Reserve() count = %d with %d codes, want 3 and 3", got.Count, len(got.Barcodes))
+	}
+	if got.Note == nil || *got.Note != "Коробка 12" {
+		t.Fatalf("Reserve() note = %v, want trimmed note", got.Note)
+	}
+	if got.CreatedBy == nil || *got.CreatedBy != actor {
+		t.Fatalf("Reserve() created_by = %v, want %s", got.CreatedBy, actor)
+	}
+	if got.Barcodes[0].Barcode != "2000000000015" {
+		t.Fatalf("Reserve() first code = %q, want 2000000000015", got.Barcodes[0].Barcode)
+	}
+}
+
+func TestBarcodeBatchServiceReserveRejectsCount(t *testing.T) {
+	t.Parallel()
+
+	service := newBatchTestService(newStubBarcodeBatchRepo())
+
+	for _, count := range []int{0, -1, MaxLabelBatch + 1} {
+		if _, err := service.Reserve(context.Background(), ReserveBarcodesRequest{Count: count}, nil); !errors.Is(err, ErrInvalidBatchSize) {
+			t.Fatalf("Reserve(%d) error = %v, want %v", count, err, ErrInvalidBatchSize)
+		}
+	}
+}
+
+func TestBarcodeBatchServiceTasksSkipsBoundCodes(t *testing.T) {
+	t.Parallel()
+
+	repo := newStubBarcodeBatchRepo()
+	service := newBatchTestService(repo)
+
+	batch, err := service.Reserve(context.Background(), ReserveBarcodesRequest{Count: 3}, nil)
+	if err != nil {
+		t.Fatalf("Reserve() error = %v", err)
+	}
+	boundAt := time.Now()
+	bookID := uuid.New()
+	repo.codes[batch.ID][1].BookID = &bookID
+	repo.codes[batch.ID][1].BoundAt = &boundAt
+
+	tasks, err := service.Tasks(context.Background(), batch.ID, "")
+	if err != nil {
+		t.Fatalf("Tasks() error = %v", err)
+	}
+	if len(tasks) != 2 {
+		t.Fatalf("Tasks() returned %d labels, want 2", len(tasks))
+	}
+	for _, task := range tasks {
+		if task.Barcode == repo.codes[batch.ID][1].Barcode {
+			t.Fatalf("Tasks() included bound code %q", task.Barcode)
+		}
+		if task.Symbology != domain.SymbologyEAN13 {
+			t.Fatalf("Tasks() symbology = %q, want %q", task.Symbology, domain.SymbologyEAN13)
+		}
+	}
+
+	if _, err := service.Tasks(context.Background(), uuid.New(), ""); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("Tasks() error = %v, want %v", err, domain.ErrNotFound)
+	}
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/ean"
+)
+
+type BarcodeFormat string
+
+const (
+	BarcodeFormatPNG BarcodeFormat = "png"
+	BarcodeFormatSVG BarcodeFormat = "svg"
+)
+
+var ErrInvalidBarcodeOptions = errors.New("invalid barcode image options")
+
+// BarcodeImageOptions control how RenderBarcode draws an EAN-13 symbol. Zero
+// values select a PNG at nominal size and 300 DPI with the standard quiet
+// zones.
+type BarcodeImageOptions struct {
+	Format BarcodeFormat
+	// Size is the magnification in percent of the nominal 0.33 mm module.
+	// GS1 allows 80 to 200.
+	Size int
+	// DPI is the resolution of PNG images; SVG images are sized in mm.
+	DPI int
+	// QuietZone is the blank margin on each side in modules. Nil keeps the
+	// standard 11 modules on the left and 7 on the right.
+	QuietZone *int
+}
+
+type BarcodeImage struct {
+	Data        []byte
+	ContentType string
+	// ETag identifies the image by code and options: the same input always
+	// renders the same bytes.
+	ETag string
+}
+
+const (
+	eanModuleMM   = 0.33
+	eanModules    = 95
+	eanQuietLeft  = 11
+	eanQuietRight = 7
+	// Heights in modules: the bars, the extra length of the guard bars and
+	// the digit band under them.
+	eanBarHeight   = 69
+	eanGuardExtra  = 5
+	eanDigitTop    = eanBarHeight + 2
+	eanImageHeight = eanDigitTop + digitGlyphHeight + 1
+	// Digits sit in 7-module cells: six under each half of the symbol and the
+	// first one in the left quiet zone.
+	eanDigitCell = 7
+
+	maxQuietZone = 50
+)
+
+func (o BarcodeImageOptions) normalize() (BarcodeImageOptions, error) {
+	o.Format = BarcodeFormat(strings.ToLower(string(o.Format)))
+	if o.Format == "" {
+		o.Format = BarcodeFormatPNG
+	}
+	if o.Format != BarcodeFormatPNG && o.Format != BarcodeFormatSVG {
+		return o, fmt.Errorf("%w: format must be png or svg", ErrInvalidBarcodeOptions)
+	}
+	if o.Size == 0 {
+		o.Size = 100
+	}
+	if o.Size < 80 || o.Size > 200 {
+		return o, fmt.Errorf("%w: size must be between 80 and 200", ErrInvalidBarcodeOptions)
+	}
+	if o.DPI == 0 {
+		o.DPI = 300
+	}
+	if o.DPI < 72 || o.DPI > 1200 {
+		return o, fmt.Errorf("%w: dpi must be between 72 and 1200", ErrInvalidBarcodeOptions)
+	}
+	if o.QuietZone != nil && (*o.QuietZone < 0 || *o.QuietZone > maxQuietZone) {
+		return o, fmt.Errorf("%w: quiet zone must be between 0 and %d modules", ErrInvalidBarcodeOptions, maxQuietZone)
+	}
+	return o, nil
+}
+
+func (o BarcodeImageOptions) quietZones() (int, int) {
+	if o.QuietZone == nil {
+		return eanQuietLeft, eanQuietRight
+	}
+	return *o.QuietZone, *o.QuietZone
+}
+
+// RenderBarcode draws an EAN-13 code with its digits under the bars.
+func (s *BarcodeService) RenderBarcode(ean13 string, opts BarcodeImageOptions) (*BarcodeImage, error) {
+	if !s.ValidateEAN13(ean13) {
+		return nil, fmt.Errorf("invalid EAN-13: %s", ean13)
+	}
+	opts, err := opts.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := ean.Encode(ean13)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode EAN-13: %w", err)
+	}
+
+	img := &BarcodeImage{ETag: barcodeETag(ean13, opts)}
+	switch opts.Format {
+	case BarcodeFormatSVG:
+		img.Data = renderBarcodeSVG(code, ean13, opts)
+		img.ContentType = "image/svg+xml"
+	default:
+		img.Data, err = renderBarcodePNG(code, ean13, opts)
+		if err != nil {
+			return nil, err
+		}
+		img.ContentType = "image/png"
+	}
+	return img, nil
+}
+
+func barcodeETag(ean13 string, opts BarcodeImageOptions) string {
+	left, right := opts.quietZones()
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d|%d|%d|%d", ean13, opts.Format, opts.Size, opts.DPI, left, right)))
+	return `"` + hex.EncodeToString(sum[:8]) + `"`
+}
+
+// isGuard reports whether module x belongs to the start, middle or end
+// guard pattern, which are drawn longer than the data bars.
+func isGuard(x int) bool {
+	return x < 3 || (x >= 45 && x < 50) || x >= 92
+}
+
+// barRuns returns the dark bars of the symbol as module offsets and widths.
+func barRuns(code barcode.Barcode) [][2]int {
+	var runs [][2]int
+	width := code.Bounds().Dx()
+	for x := 0; x < width; {
+		if code.At(x, 0) != color.Black {
+			x++
+			continue
+		}
+		start := x
+		// Guard and data bars are split so that they get their own heights.
+		for x < width && code.At(x, 0) == color.Black && isGuard(x) == isGuard(start) {
+			x++
+		}
+		runs = append(runs, [2]int{start, x - start})
+	}
+	return runs
+}
+
+// digitCells returns the left edge, in modules from the first bar, of the
+// cell for each of the 13 digits.
+func digitCells() [13]int {
+	var cells [13]int
+	cells[0] = -eanDigitCell - 1
+	for i := 0; i < 6; i++ {
+		cells[1+i] = 3 + i*eanDigitCell
+		cells[7+i] = 50 + i*eanDigitCell
+	}
+	return cells
+}
+
+func renderBarcodePNG(code barcode.Barcode, ean13 string, opts BarcodeImageOptions) ([]byte, error) {
+	left, right := opts.quietZones()
+	moduleMM := eanModuleMM * float64(opts.Size) / 100
+	// Every module gets the same whole number of pixels, otherwise bar
+	// widths drift and scanners misread the code.
+	px := int(math.Max(1, math.Round(moduleMM*float64(opts.DPI)/25.4)))
+
+	img := image.NewGray(image.Rect(0, 0, (left+eanModules+right)*px, eanImageHeight*px))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	fill := func(x, y, w, h int) {
+		for yy := y * px; yy < (y+h)*px; yy++ {
+			for xx := x * px; xx < (x+w)*px; xx++ {
+				if xx >= 0 && xx < img.Rect.Dx() {
+					img.Pix[yy*img.Stride+xx] = 0
+				}
+			}
+		}
+	}
+
+	for _, run := range barRuns(code) {
+		h := eanBarHeight
+		if isGuard(run[0]) {
+			h += eanGuardExtra
+		}
+		fill(left+run[0], 0, run[1], h)
+	}
+
+	cells := digitCells()
+	for i, ch := range ean13 {
+		glyph := digitGlyphs[ch-'0']
+		x0 := left + cells[i] + (eanDigitCell-digitGlyphWidth)/2
+		for row, bits := range glyph {
+			for col := 0; col < digitGlyphWidth; col++ {
+				if bits&(1<<(digitGlyphWidth-1-col)) != 0 {
+					fill(x0+col, eanDigitTop+row, 1, 1)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func renderBarcodeSVG(code barcode.Barcode, ean13 string, opts BarcodeImageOptions) []byte {
+	left, right := opts.quietZones()
+	moduleMM := eanModuleMM * float64(opts.Size) / 100
+	width := left + eanModules + right
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.2fmm" height="%.2fmm" viewBox="0 0 %d %d">`,
+		float64(width)*moduleMM, float64(eanImageHeight)*moduleMM, width, eanImageHeight)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/><g fill="#000">`, width, eanImageHeight)
+	for _, run := range barRuns(code) {
+		h := eanBarHeight
+		if isGuard(run[0]) {
+			h += eanGuardExtra
+		}
+		fmt.Fprintf(&b, `<rect x="%d" width="%d" height="%d"/>`, left+run[0], run[1], h)
+	}
+	b.WriteString(`</g><g font-family="OCR-B, monospace" font-size="9" text-anchor="middle">`)
+	cells := digitCells()
+	for i, ch := range ean13 {
+		x := float64(left+cells[i]) + float64(eanDigitCell)/2
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d">%c</text>`, x, eanDigitTop+digitGlyphHeight, ch)
+	}
+	b.WriteString(`</g></svg>`)
+	return b.Bytes()
+}
+
+const (
+	digitGlyphWidth  = 5
+	digitGlyphHeight = 7
+)
+
+// digitGlyphs is a 5x7 bitmap font for the digits under PNG barcodes; each
+// row holds five pixels in its low bits.
+var digitGlyphs = [10][digitGlyphHeight]uint8{
+	{0b01110, 0b10001, 0b10011, 0b10101, 0b11001, 0b10001, 0b01110},
+	{0b00100, 0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	{0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b01000, 0b11111},
+	{0b11111, 0b00010, 0b00100, 0b00010, 0b00001, 0b10001, 0b01110},
+	{0b00010, 0b00110, 0b01010, 0b10010, 0b11111, 0b00010, 0b00010},
+	{0b11111, 0b10000, 0b11110, 0b00001, 0b00001, 0b10001, 0b01110},
+	{0b00110, 0b01000, 0b10000, 0b11110, 0b10001, 0b10001, 0b01110},
+	{0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b01000, 0b01000},
+	{0b01110, 0b10001, 0b10001, 0b01110, 0b10001, 0b10001, 0b01110},
+	{0b01110, 0b10001, 0b10001, 0b01111, 0b00001, 0b00010, 0b01100},
+}
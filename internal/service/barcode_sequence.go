@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// sequenceWarnEvery thins out low-sequence warnings to one per that many
+// generated codes.
+const sequenceWarnEvery = 100
+
+// BarcodeSequenceInfo is a sequence with its remaining capacity.
+type BarcodeSequenceInfo struct {
+	domain.BarcodeSequence
+	Remaining int64 `json:"remaining"`
+	// Low is set once fewer than LowWatermark codes remain.
+	Low bool `json:"low"`
+}
+
+type CreateSequenceRequest struct {
+	Type        domain.BarcodeType `json:"type"`
+	Prefix      int                `json:"prefix"`
+	Description *string            `json:"description,omitempty"`
+	// Activate switches new barcodes of the type to this prefix right away.
+	Activate bool `json:"activate,omitempty"`
+}
+
+func (s *BarcodeService) warnIfLow(t domain.BarcodeType, prefix int, remaining int64) {
+	if s.LowWatermark <= 0 || remaining >= s.LowWatermark {
+		return
+	}
+	if remaining%sequenceWarnEvery == 0 || remaining < 10 {
+		log.Printf("barcode sequence %d (%s) is running out: %d codes left, add and activate a new prefix", prefix, t, remaining)
+	}
+}
+
+func (s *BarcodeService) sequenceInfo(seq *domain.BarcodeSequence) BarcodeSequenceInfo {
+	remaining := seq.Remaining()
+	return BarcodeSequenceInfo{
+		BarcodeSequence: *seq,
+		Remaining:       remaining,
+		Low:             s.LowWatermark > 0 && remaining < s.LowWatermark,
+	}
+}
+
+func (s *BarcodeService) ListSequences(ctx context.Context) ([]BarcodeSequenceInfo, error) {
+	seqs, err := s.seqRepo.List(ctx)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	res := make([]BarcodeSequenceInfo, 0, len(seqs))
+	for _, seq := range seqs {
+		res = append(res, s.sequenceInfo(seq))
+	}
+	return res, nil
+}
+
+// CreateSequence adds a prefix range. The prefix must lie in the range of
+// its type, so that scanned codes can still be told apart by prefix.
+func (s *BarcodeService) CreateSequence(ctx context.Context, req CreateSequenceRequest) (*BarcodeSequenceInfo, error) {
+	if !req.Type.Valid() {
+		return nil, fmt.Errorf("%w: unknown barcode type %q", domain.ErrInvalidInput, req.Type)
+	}
+	if t, ok := domain.BarcodeTypeOfPrefix(req.Prefix); !ok || t != req.Type {
+		return nil, fmt.Errorf("%w: prefix %d is outside the range of %s", domain.ErrInvalidInput, req.Prefix, req.Type)
+	}
+	if req.Description != nil {
+		d := strings.TrimSpace(*req.Description)
+		req.Description = &d
+	}
+
+	err := s.seqRepo.Create(ctx, domain.BarcodeSequence{
+		Prefix:      req.Prefix,
+		Type:        req.Type,
+		Description: req.Description,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if req.Activate {
+		if err := s.seqRepo.Activate(ctx, req.Prefix); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.getSequence(ctx, req.Prefix)
+}
+
+func (s *BarcodeService) ActivateSequence(ctx context.Context, prefix int) (*BarcodeSequenceInfo, error) {
+	if err := s.seqRepo.Activate(ctx, prefix); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return s.getSequence(ctx, prefix)
+}
+
+func (s *BarcodeService) getSequence(ctx context.Context, prefix int) (*BarcodeSequenceInfo, error) {
+	seq, err := s.seqRepo.GetByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	info := s.sequenceInfo(seq)
+	return &info, nil
+}
@@ -9,17 +9,60 @@ import (
 	"testing"
 
 	"elibrary/internal/domain"
+	"elibrary/internal/repository"
 )
 
 type stubSequenceRepo struct {
-	getNext func(ctx context.Context, t domain.BarcodeType) (int64, int, error)
+	getNext      func(ctx context.Context, t domain.BarcodeType) (int64, int, error)
+	getNextRange func(ctx context.Context, t domain.BarcodeType, n int) (int64, int, error)
+	seqs         map[int]*domain.BarcodeSequence
 }
 
 func (s stubSequenceRepo) GetNext(ctx context.Context, t domain.BarcodeType) (int64, int, error) {
 	return s.getNext(ctx, t)
 }
 
-func (s stubSequenceRepo) SetType(ctx context.Context, t domain.BarcodeType, prefix int, description string) error {
+func (s stubSequenceRepo) GetNextRange(ctx context.Context, t domain.BarcodeType, n int) (int64, int, error) {
+	return s.getNextRange(ctx, t, n)
+}
+
+func (s stubSequenceRepo) List(ctx context.Context) ([]*domain.BarcodeSequence, error) {
+	if len(s.seqs) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	var res []*domain.BarcodeSequence
+	for _, seq := range s.seqs {
+		res = append(res, seq)
+	}
+	return res, nil
+}
+
+func (s stubSequenceRepo) GetByPrefix(ctx context.Context, prefix int) (*domain.BarcodeSequence, error) {
+	seq, ok := s.seqs[prefix]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return seq, nil
+}
+
+func (s stubSequenceRepo) Create(ctx context.Context, seq domain.BarcodeSequence) error {
+	if _, ok := s.seqs[seq.Prefix]; ok {
+		return domain.ErrSequenceExists
+	}
+	s.seqs[seq.Prefix] = &seq
+	return nil
+}
+
+func (s stubSequenceRepo) Activate(ctx context.Context, prefix int) error {
+	target, ok := s.seqs[prefix]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	for _, seq := range s.seqs {
+		if seq.Type == target.Type {
+			seq.Active = seq.Prefix == prefix
+		}
+	}
 	return nil
 }
 
@@ -85,6 +128,41 @@ func TestBarcodeServiceGenerateEAN13RejectsOverflow(t *testing.T) {
 	}
 }
 
+func TestBarcodeServiceReserveEAN13(t *testing.T) {
+	t.Parallel()
+
+	service := NewBarcodeService(stubSequenceRepo{
+		getNextRange: func(ctx context.Context, t domain.BarcodeType, n int) (int64, int, error) {
+			return 123, 200, nil
+		},
+	})
+
+	got, err := service.ReserveEAN13(context.Background(), domain.BarcodeTypeBook, 3)
+	if err != nil {
+		t.Fatalf("ReserveEAN13() error = %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("ReserveEAN13() returned %d codes, want 3", len(got))
+	}
+	for i, code := range got {
+		if !service.ValidateEAN13(code) {
+			t.Fatalf("ReserveEAN13()[%d] = %q, want a valid EAN-13", i, code)
+		}
+	}
+	if got[0] != "2000000001234" || got[2][:12] != "200000000125" {
+		t.Fatalf("ReserveEAN13() = %v, want consecutive codes from 200000000123", got)
+	}
+
+	overflow := NewBarcodeService(stubSequenceRepo{
+		getNextRange: func(ctx context.Context, t domain.BarcodeType, n int) (int64, int, error) {
+			return domain.MaxBarcodeSequence - 1, 200, nil
+		},
+	})
+	if _, err := overflow.ReserveEAN13(context.Background(), domain.BarcodeTypeBook, 3); err == nil || !strings.Contains(err.Error(), "overflow") {
+		t.Fatalf("ReserveEAN13() error = %v, want overflow error", err)
+	}
+}
+
 func TestBarcodeServiceValidateEAN13(t *testing.T) {
 	t.Parallel()
 
@@ -148,3 +226,216 @@ func TestBarcodeServiceGenerateBarcodeImageRejectsInvalidCode(t *testing.T) {
 		t.Fatalf("GenerateBarcodeImage() error = %q, want invalid EAN-13 error", err.Error())
 	}
 }
+
+func TestBarcodeServiceRenderBarcodePNG(t *testing.T) {
+	t.Parallel()
+
+	service := NewBarcodeService(nil)
+
+	img, err := service.RenderBarcode("9780000001238", BarcodeImageOptions{})
+	if err != nil {
+		t.Fatalf("RenderBarcode() error = %v", err)
+	}
+	if img.ContentType != "image/png" {
+		t.Fatalf("RenderBarcode() content type = %q, want image/png", img.ContentType)
+	}
+
+	decoded, err := png.Decode(bytes.NewReader(img.Data))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	// 0.33 mm at 300 DPI rounds to 4 pixels per module.
+	const px = 4
+	bounds := decoded.Bounds()
+	if bounds.Dx() != (11+95+7)*px || bounds.Dy() != eanImageHeight*px {
+		t.Fatalf("barcode image size = %dx%d, want %dx%d", bounds.Dx(), bounds.Dy(), (11+95+7)*px, eanImageHeight*px)
+	}
+
+	dark := func(x, y int) bool {
+		r, _, _, _ := decoded.At(x, y).RGBA()
+		return r == 0
+	}
+	if dark(11*px-1, 0) || !dark(11*px, 0) {
+		t.Fatal("first bar does not start after the 11-module quiet zone")
+	}
+	var digitPixels int
+	for y := eanDigitTop * px; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			if dark(x, y) {
+				digitPixels++
+			}
+		}
+	}
+	if digitPixels == 0 {
+		t.Fatal("RenderBarcode() drew no digits under the bars")
+	}
+}
+
+func TestBarcodeServiceRenderBarcodeSVG(t *testing.T) {
+	t.Parallel()
+
+	service := NewBarcodeService(nil)
+	quiet := 0
+
+	img, err := service.RenderBarcode("9780000001238", BarcodeImageOptions{Format: "SVG", Size: 200, QuietZone: &quiet})
+	if err != nil {
+		t.Fatalf("RenderBarcode() error = %v", err)
+	}
+	svg := string(img.Data)
+	if img.ContentType != "image/svg+xml" || !strings.HasPrefix(svg, "<svg") {
+		t.Fatalf("RenderBarcode() = %q (%s), want an SVG document", svg, img.ContentType)
+	}
+	if !strings.Contains(svg, `width="62.70mm"`) {
+		t.Errorf("RenderBarcode() SVG is not 95 modules of 0.66 mm wide: %s", svg[:120])
+	}
+	if got := strings.Count(svg, "<text"); got != 13 {
+		t.Errorf("RenderBarcode() SVG has %d digits, want 13", got)
+	}
+}
+
+func TestBarcodeServiceRenderBarcodeETag(t *testing.T) {
+	t.Parallel()
+
+	service := NewBarcodeService(nil)
+	render := func(code string, opts BarcodeImageOptions) string {
+		img, err := service.RenderBarcode(code, opts)
+		if err != nil {
+			t.Fatalf("RenderBarcode() error = %v", err)
+		}
+		return img.ETag
+	}
+
+	base := render("9780000001238", BarcodeImageOptions{})
+	if got := render("9780000001238", BarcodeImageOptions{Format: BarcodeFormatPNG, Size: 100, DPI: 300}); got != base {
+		t.Errorf("ETag with explicit defaults = %s, want %s", got, base)
+	}
+	if got := render("9780000001238", BarcodeImageOptions{DPI: 600}); got == base {
+		t.Error("ETag does not change with dpi")
+	}
+	if got := render("2000000000015", BarcodeImageOptions{}); got == base {
+		t.Error("ETag does not change with code")
+	}
+}
+
+func TestBarcodeServiceRenderBarcodeRejectsOptions(t *testing.T) {
+	t.Parallel()
+
+	service := NewBarcodeService(nil)
+	quiet := -1
+
+	tests := []BarcodeImageOptions{
+		{Format: "gif"},
+		{Size: 50},
+		{DPI: 5000},
+		{QuietZone: &quiet},
+	}
+	for _, opts := range tests {
+		if _, err := service.RenderBarcode("9780000001238", opts); !errors.Is(err, ErrInvalidBarcodeOptions) {
+			t.Errorf("RenderBarcode(%+v) error = %v, want %v", opts, err, ErrInvalidBarcodeOptions)
+		}
+	}
+}
+
+func TestBarcodeServiceGenerateSymbolImage(t *testing.T) {
+	t.Parallel()
+
+	service := NewBarcodeService(nil)
+
+	tests := []struct {
+		sym    domain.Symbology
+		data   string
+		square bool
+	}{
+		{sym: domain.SymbologyEAN13, data: "9780000001238"},
+		{sym: domain.SymbologyCode128, data: "LEGACY-0042"},
+		{sym: domain.SymbologyQR, data: "https://library.example/locations/1", square: true},
+		{sym: domain.SymbologyDataMatrix, data: "LEGACY-0042", square: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(string(tt.sym), func(t *testing.T) {
+			t.Parallel()
+
+			data, err := service.GenerateSymbolImage(tt.sym, tt.data)
+			if err != nil {
+				t.Fatalf("GenerateSymbolImage() error = %v", err)
+			}
+			cfg, err := png.DecodeConfig(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("DecodeConfig() error = %v", err)
+			}
+			if tt.square && (cfg.Width != cfg.Height || cfg.Width > 150) {
+				t.Fatalf("symbol size = %dx%d, want a square up to 150 px", cfg.Width, cfg.Height)
+			}
+			if !tt.square && (cfg.Width != 300 || cfg.Height != 150) {
+				t.Fatalf("symbol size = %dx%d, want 300x150", cfg.Width, cfg.Height)
+			}
+		})
+	}
+}
+
+func TestEncodeSymbolRejectsInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	if _, err := EncodeSymbol(domain.SymbologyEAN13, "9780000001239"); !errors.Is(err, domain.ErrInvalidBarcode) {
+		t.Fatalf("EncodeSymbol(bad checksum) error = %v, want %v", err, domain.ErrInvalidBarcode)
+	}
+	if _, err := EncodeSymbol("pdf417", "1"); !errors.Is(err, domain.ErrInvalidSymbology) {
+		t.Fatalf("EncodeSymbol(pdf417) error = %v, want %v", err, domain.ErrInvalidSymbology)
+	}
+}
+
+func TestBarcodeServiceGenerateEAN13NoActiveSequence(t *testing.T) {
+	t.Parallel()
+
+	service := NewBarcodeService(stubSequenceRepo{
+		getNext: func(ctx context.Context, t domain.BarcodeType) (int64, int, error) {
+			return 0, 0, repository.ErrNotFound
+		},
+	})
+
+	_, err := service.GenerateEAN13(context.Background(), domain.BarcodeTypeLocation)
+	if err == nil || !strings.Contains(err.Error(), "no active barcode sequence") {
+		t.Fatalf("GenerateEAN13() error = %v, want no active sequence error", err)
+	}
+}
+
+func TestBarcodeServiceSequences(t *testing.T) {
+	t.Parallel()
+
+	repo := stubSequenceRepo{seqs: map[int]*domain.BarcodeSequence{
+		200: {Prefix: 200, Type: domain.BarcodeTypeBook, LastValue: domain.MaxBarcodeSequence - 50, Active: true},
+	}}
+	service := NewBarcodeService(repo)
+	service.LowWatermark = 1000
+
+	seqs, err := service.ListSequences(context.Background())
+	if err != nil {
+		t.Fatalf("ListSequences() error = %v", err)
+	}
+	if len(seqs) != 1 || seqs[0].Remaining != 50 || !seqs[0].Low {
+		t.Fatalf("ListSequences() = %+v, want 50 remaining and low", seqs)
+	}
+
+	created, err := service.CreateSequence(context.Background(), CreateSequenceRequest{Type: domain.BarcodeTypeBook, Prefix: 201, Activate: true})
+	if err != nil {
+		t.Fatalf("CreateSequence() error = %v", err)
+	}
+	if !created.Active || created.Remaining != domain.MaxBarcodeSequence || created.Low {
+		t.Fatalf("CreateSequence() = %+v, want an active empty sequence", created)
+	}
+	if repo.seqs[200].Active {
+		t.Fatal("CreateSequence() left the old prefix active")
+	}
+
+	if _, err := service.CreateSequence(context.Background(), CreateSequenceRequest{Type: domain.BarcodeTypeLocation, Prefix: 250}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("CreateSequence(book prefix for locations) error = %v, want %v", err, domain.ErrInvalidInput)
+	}
+	if _, err := service.CreateSequence(context.Background(), CreateSequenceRequest{Type: domain.BarcodeTypeBook, Prefix: 201}); !errors.Is(err, domain.ErrSequenceExists) {
+		t.Fatalf("CreateSequence(duplicate) error = %v, want %v", err, domain.ErrSequenceExists)
+	}
+	if _, err := service.ActivateSequence(context.Background(), 299); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("ActivateSequence(missing) error = %v, want %v", err, domain.ErrNotFound)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -18,6 +19,7 @@ type BookService struct {
 	workRepo        repository.WorkRepository
 	workAuthorsRepo repository.WorkAuthorsRepository
 	barcodeSvc      *BarcodeService
+	printJobs       *PrintJobService
 }
 
 func NewBookService(
@@ -26,6 +28,7 @@ func NewBookService(
 	workRepo repository.WorkRepository,
 	workAuthorsRepo repository.WorkAuthorsRepository,
 	barcodeSvc *BarcodeService,
+	printJobs *PrintJobService,
 ) *BookService {
 	return &BookService{
 		bookRepo:        bookRepo,
@@ -33,21 +36,49 @@ func NewBookService(
 		workRepo:        workRepo,
 		workAuthorsRepo: workAuthorsRepo,
 		barcodeSvc:      barcodeSvc,
+		printJobs:       printJobs,
 	}
 }
 
-func (s *BookService) Create(ctx context.Context, book domain.Book, works []repository.BookWorkInput) (*domain.Book, error) {
+// CreatedBook is a new book together with the books that already carry its
+// ISBN or ISSN. Duplicates are only reported: a library may hold several
+// copies of one edition, but more often it is the same book entered twice.
+type CreatedBook struct {
+	domain.Book
+	Duplicates []*readmodel.BookPublic `json:"duplicates,omitempty"`
+}
+
+// Create stores a new book. With printLabel the label print job is scheduled
+// in the same transaction, so either both exist or neither does.
+//
+// A book.Barcode that is already set must be a reserved, still unbound code
+// from a pre-printed batch; otherwise a new code is generated.
+func (s *BookService) Create(ctx context.Context, book domain.Book, works []repository.BookWorkInput, printLabel bool, actorID *uuid.UUID) (*CreatedBook, error) {
 	if strings.TrimSpace(book.Title) == "" {
 		return nil, errors.New("title is required")
 	}
-
-	ean13, err := s.barcodeSvc.GenerateEAN13(ctx, domain.BarcodeTypeBook)
+	if err := normalizeStandardNumbers(&book); err != nil {
+		return nil, err
+	}
+	duplicates, err := s.findDuplicates(ctx, book)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate barcode: %w", err)
+		return nil, err
+	}
+
+	reserved := book.Barcode != ""
+	if reserved {
+		if !s.barcodeSvc.ValidateEAN13(book.Barcode) {
+			return nil, domain.ErrInvalidBarcode
+		}
+	} else {
+		ean13, err := s.barcodeSvc.GenerateEAN13(ctx, domain.BarcodeTypeBook)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate barcode: %w", err)
+		}
+		book.Barcode = ean13
 	}
 
 	book.ID = uuid.New()
-	book.Barcode = ean13
 
 	if book.Extra == nil {
 		book.Extra = make(map[string]any)
@@ -63,17 +94,64 @@ func (s *BookService) Create(ctx context.Context, book domain.Book, works []repo
 		if err := tx.CreateBook(ctx, book); err != nil {
 			return err
 		}
-		return tx.ReplaceBookWorks(ctx, book.ID, works)
+		if reserved {
+			if err := tx.BindReservedBarcode(ctx, book.Barcode, book.ID); err != nil {
+				return err
+			}
+		}
+		if book.LocationID != nil {
+			if err := recordMovement(ctx, tx, book.ID, nil, book.LocationID, actorID, nil); err != nil {
+				return err
+			}
+		}
+		if err := tx.ReplaceBookWorks(ctx, book.ID, works); err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, tx, book.ID, domain.BookEventCreated, nil, actorID); err != nil {
+			return err
+		}
+		if printLabel && s.printJobs != nil {
+			task := PrintTask{Str1: book.Title, Barcode: book.Barcode}
+			if err := s.barcodeSvc.ApplySymbology(&task, domain.BarcodeTypeBook, book.ID, ""); err != nil {
+				return err
+			}
+			if _, err := s.printJobs.schedule(ctx, tx, task, actorID); err != nil {
+				return fmt.Errorf("failed to schedule label: %w", err)
+			}
+		}
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
+	if printLabel && s.printJobs != nil {
+		s.printJobs.notify()
+	}
 
-	return &book, nil
+	return &CreatedBook{Book: book, Duplicates: duplicates}, nil
+}
+
+// findDuplicates lists the live books with the ISBN or ISSN of book.
+func (s *BookService) findDuplicates(ctx context.Context, book domain.Book) ([]*readmodel.BookPublic, error) {
+	if book.ISBN == nil && book.ISSN == nil {
+		return nil, nil
+	}
+
+	limit := maxScanMatches
+	books, err := s.bookRepo.GetPublic(ctx, repository.BookFilter{ISBN: book.ISBN, ISSN: book.ISSN, Limit: &limit})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to look up duplicates: %w", err)
+	}
+	return books, nil
 }
 
 type UpdateBookRequest struct {
 	FactoryBarcode *string        `json:"factory_barcode,omitempty"`
+	ISBN           *string        `json:"isbn,omitempty"`
+	ISSN           *string        `json:"issn,omitempty"`
 	Title          *string        `json:"title,omitempty"`
 	PublisherID    *uuid.UUID     `json:"publisher_id,omitempty"`
 	Year           *int           `json:"year,omitempty"`
@@ -82,22 +160,38 @@ type UpdateBookRequest struct {
 	Extra          map[string]any `json:"extra,omitempty"`
 
 	Works *[]repository.BookWorkInput `json:"works,omitempty"`
+
+	MoveReason *string `json:"move_reason,omitempty"`
 }
 
-func (s *BookService) Update(ctx context.Context, id uuid.UUID, updates UpdateBookRequest) error {
+func (s *BookService) Update(ctx context.Context, id uuid.UUID, updates UpdateBookRequest, actorID *uuid.UUID) error {
 	return s.bookRepo.WithTx(ctx, func(tx repository.BookTx) error {
-
-		book, err := tx.GetDomainByID(ctx, id)
+		before, err := getLiveSnapshot(ctx, tx, id)
 		if err != nil {
-			if errors.Is(err, repository.ErrNotFound) {
-				return domain.ErrNotFound
-			}
 			return err
 		}
+		book := before.Book
 
 		if updates.FactoryBarcode != nil {
+			// Numbers taken from the old barcode follow it unless the request
+			// sets them; ones entered by hand are kept.
+			if updates.ISBN == nil && equalStrPtr(book.ISBN, factoryISBN(book.FactoryBarcode)) {
+				book.ISBN = nil
+			}
+			if updates.ISSN == nil && equalStrPtr(book.ISSN, factoryISSN(book.FactoryBarcode)) {
+				book.ISSN = nil
+			}
 			book.FactoryBarcode = updates.FactoryBarcode
 		}
+		if updates.ISBN != nil {
+			book.ISBN = updates.ISBN
+		}
+		if updates.ISSN != nil {
+			book.ISSN = updates.ISSN
+		}
+		if err := normalizeStandardNumbers(&book); err != nil {
+			return err
+		}
 		if updates.Title != nil {
 			title := strings.TrimSpace(*updates.Title)
 			if title == "" {
@@ -114,6 +208,7 @@ func (s *BookService) Update(ctx context.Context, id uuid.UUID, updates UpdateBo
 		if updates.Description != nil {
 			book.Description = updates.Description
 		}
+		fromLocationID := book.LocationID
 		if updates.LocationID != nil {
 			book.LocationID = updates.LocationID
 		}
@@ -121,20 +216,298 @@ func (s *BookService) Update(ctx context.Context, id uuid.UUID, updates UpdateBo
 			book.Extra = updates.Extra
 		}
 
-		if err := tx.UpdateBook(ctx, *book); err != nil {
+		if err := tx.UpdateBook(ctx, book); err != nil {
 			return err
 		}
 
+		if locationChanged(fromLocationID, book.LocationID) {
+			if err := recordMovement(ctx, tx, book.ID, fromLocationID, book.LocationID, actorID, updates.MoveReason); err != nil {
+				return err
+			}
+		}
+
 		if updates.Works != nil {
 			if err := tx.ReplaceBookWorks(ctx, book.ID, *updates.Works); err != nil {
 				return err
 			}
 		}
 
+		return recordEvent(ctx, tx, book.ID, domain.BookEventUpdated, before, actorID)
+	})
+}
+
+// RelabelRequest reissues the label of a book. Barcode, when set, is a
+// reserved code from a pre-printed batch; otherwise a new code is generated.
+type RelabelRequest struct {
+	Barcode    string  `json:"barcode,omitempty"`
+	Reason     *string `json:"reason,omitempty"`
+	PrintLabel bool    `json:"print_label,omitempty"`
+}
+
+// Relabel gives a book a new barcode, e.g. for a damaged or lost label. The
+// old code goes to the barcode history and keeps resolving to the book.
+func (s *BookService) Relabel(ctx context.Context, id uuid.UUID, req RelabelRequest, actorID *uuid.UUID) (*domain.Book, error) {
+	reserved := req.Barcode != ""
+	code := req.Barcode
+	if reserved {
+		if !s.barcodeSvc.ValidateEAN13(code) {
+			return nil, domain.ErrInvalidBarcode
+		}
+	} else {
+		ean13, err := s.barcodeSvc.GenerateEAN13(ctx, domain.BarcodeTypeBook)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate barcode: %w", err)
+		}
+		code = ean13
+	}
+
+	var book domain.Book
+	err := s.bookRepo.WithTx(ctx, func(tx repository.BookTx) error {
+		before, err := getLiveSnapshot(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		book = before.Book
+
+		if reserved {
+			if err := tx.BindReservedBarcode(ctx, code, book.ID); err != nil {
+				return err
+			}
+		}
+		if err := tx.AddReplacedBarcode(ctx, domain.ReplacedBarcode{
+			Barcode:    book.Barcode,
+			BookID:     &book.ID,
+			ReplacedBy: code,
+			Reason:     req.Reason,
+			CreatedBy:  actorID,
+			ReplacedAt: time.Now(),
+		}); err != nil {
+			return err
+		}
+
+		book.Barcode = code
+		if err := tx.UpdateBook(ctx, book); err != nil {
+			return err
+		}
+		if err := recordEvent(ctx, tx, book.ID, domain.BookEventUpdated, before, actorID); err != nil {
+			return err
+		}
+
+		if req.PrintLabel && s.printJobs != nil {
+			task := PrintTask{Str1: book.Title, Barcode: book.Barcode}
+			if err := s.barcodeSvc.ApplySymbology(&task, domain.BarcodeTypeBook, book.ID, ""); err != nil {
+				return err
+			}
+			if _, err := s.printJobs.schedule(ctx, tx, task, actorID); err != nil {
+				return fmt.Errorf("failed to schedule label: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if req.PrintLabel && s.printJobs != nil {
+		s.printJobs.notify()
+	}
+
+	return &book, nil
+}
+
+// BarcodeHistory lists the codes the book carried before, newest first.
+func (s *BookService) BarcodeHistory(ctx context.Context, id uuid.UUID) ([]*domain.ReplacedBarcode, error) {
+	history, err := s.bookRepo.GetReplacedBarcodes(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return history, nil
+}
+
+type MoveBooksRequest struct {
+	BookIDs    []uuid.UUID `json:"book_ids"`
+	LocationID *uuid.UUID  `json:"location_id"`
+	Reason     *string     `json:"reason,omitempty"`
+}
+
+// Move relocates several books at once. Books already at the target location
+// are left untouched and do not get a movement record.
+func (s *BookService) Move(ctx context.Context, req MoveBooksRequest, actorID *uuid.UUID) error {
+	if len(req.BookIDs) == 0 {
+		return errors.New("book_ids are required")
+	}
+
+	return s.bookRepo.WithTx(ctx, func(tx repository.BookTx) error {
+		for _, id := range req.BookIDs {
+			before, err := getLiveSnapshot(ctx, tx, id)
+			if err != nil {
+				return err
+			}
+			book := before.Book
+
+			if !locationChanged(book.LocationID, req.LocationID) {
+				continue
+			}
+
+			fromLocationID := book.LocationID
+			book.LocationID = req.LocationID
+
+			if err := tx.UpdateBook(ctx, book); err != nil {
+				return err
+			}
+			if err := recordMovement(ctx, tx, book.ID, fromLocationID, book.LocationID, actorID, req.Reason); err != nil {
+				return err
+			}
+			if err := recordEvent(ctx, tx, book.ID, domain.BookEventUpdated, before, actorID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *BookService) GetMovements(ctx context.Context, bookID uuid.UUID) ([]*domain.BookMovement, error) {
+	movements, err := s.bookRepo.GetMovements(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return movements, nil
+}
+
+// Delete moves the book to the trash. Books that are currently on loan
+// cannot be deleted.
+func (s *BookService) Delete(ctx context.Context, id uuid.UUID, actorID *uuid.UUID) error {
+	return s.bookRepo.WithTx(ctx, func(tx repository.BookTx) error {
+		if err := tx.LockBook(ctx, id); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return domain.ErrNotFound
+			}
+			return err
+		}
+
+		before, err := getLiveSnapshot(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		onLoan, err := tx.HasActiveLoan(ctx, id)
+		if err != nil {
+			return err
+		}
+		if onLoan {
+			return domain.ErrBookOnLoan
+		}
+
+		if err := tx.SoftDelete(ctx, id, actorID); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return domain.ErrNotFound
+			}
+			return err
+		}
+
+		return recordEvent(ctx, tx, id, domain.BookEventDeleted, before, actorID)
+	})
+}
+
+func (s *BookService) Restore(ctx context.Context, id uuid.UUID, actorID *uuid.UUID) error {
+	return s.bookRepo.WithTx(ctx, func(tx repository.BookTx) error {
+		before, err := getDeletedSnapshot(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Restore(ctx, id); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return domain.ErrBookNotDeleted
+			}
+			return err
+		}
+
+		return recordEvent(ctx, tx, id, domain.BookEventRestored, before, actorID)
+	})
+}
+
+// Purge permanently removes a book that is already in the trash.
+func (s *BookService) Purge(ctx context.Context, id uuid.UUID) error {
+	return s.bookRepo.WithTx(ctx, func(tx repository.BookTx) error {
+		if _, err := getDeletedSnapshot(ctx, tx, id); err != nil {
+			return err
+		}
+
+		if err := tx.Purge(ctx, id); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return domain.ErrBookNotDeleted
+			}
+			return err
+		}
 		return nil
 	})
 }
 
+func (s *BookService) GetTrash(ctx context.Context, filter repository.BookFilter) ([]*readmodel.BookInternal, error) {
+	filter.Deleted = true
+	return s.GetInternal(ctx, filter)
+}
+
+func getLiveSnapshot(ctx context.Context, tx repository.BookTx, id uuid.UUID) (*domain.BookSnapshot, error) {
+	snapshot, err := tx.GetSnapshot(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if snapshot.Book.DeletedAt != nil {
+		return nil, domain.ErrNotFound
+	}
+	return snapshot, nil
+}
+
+func getDeletedSnapshot(ctx context.Context, tx repository.BookTx, id uuid.UUID) (*domain.BookSnapshot, error) {
+	snapshot, err := tx.GetSnapshot(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if snapshot.Book.DeletedAt == nil {
+		return nil, domain.ErrBookNotDeleted
+	}
+	return snapshot, nil
+}
+
+func recordMovement(
+	ctx context.Context,
+	tx repository.BookTx,
+	bookID uuid.UUID,
+	from, to *uuid.UUID,
+	actorID *uuid.UUID,
+	reason *string,
+) error {
+	return tx.CreateMovement(ctx, domain.BookMovement{
+		ID:             uuid.New(),
+		BookID:         bookID,
+		FromLocationID: from,
+		ToLocationID:   to,
+		MovedAt:        time.Now(),
+		ActorUserID:    actorID,
+		Reason:         reason,
+	})
+}
+
+func locationChanged(from, to *uuid.UUID) bool {
+	if from == nil || to == nil {
+		return from != to
+	}
+	return *from != *to
+}
+
 func (s *BookService) GetPublicByID(ctx context.Context, id uuid.UUID) (*readmodel.BookPublic, error) {
 	book, err := s.bookRepo.GetPublicByID(ctx, id)
 	if err != nil {
@@ -159,8 +532,17 @@ func (s *BookService) GetInternalByID(ctx context.Context, id uuid.UUID) (*readm
 	return book, nil
 }
 
+// BarcodeImage renders the library barcode of a book.
+func (s *BookService) BarcodeImage(ctx context.Context, id uuid.UUID, opts BarcodeImageOptions) (*BarcodeImage, error) {
+	book, err := s.GetInternalByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.barcodeSvc.RenderBarcode(book.Barcode, opts)
+}
+
 func (s *BookService) GetPublic(ctx context.Context, filter repository.BookFilter) ([]*readmodel.BookPublic, error) {
-	books, err := s.bookRepo.GetPublic(ctx, filter)
+	books, err := s.bookRepo.GetPublic(ctx, withStandardNumbers(filter))
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return nil, domain.ErrNotFound
@@ -172,7 +554,7 @@ func (s *BookService) GetPublic(ctx context.Context, filter repository.BookFilte
 }
 
 func (s *BookService) GetInternal(ctx context.Context, filter repository.BookFilter) ([]*readmodel.BookInternal, error) {
-	books, err := s.bookRepo.GetInternal(ctx, filter)
+	books, err := s.bookRepo.GetInternal(ctx, withStandardNumbers(filter))
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return nil, domain.ErrNotFound
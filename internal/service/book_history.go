@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var ErrEventBookMismatch = errors.New("event belongs to another book")
+
+// diffIgnoredFields are bumped on every write and would show up in every diff.
+var diffIgnoredFields = map[string]bool{
+	"updated_at": true,
+}
+
+func (s *BookService) GetHistory(ctx context.Context, bookID uuid.UUID) ([]*domain.BookEvent, error) {
+	events, err := s.bookRepo.GetEvents(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return events, nil
+}
+
+// Diff compares the state of the book after event fromID with its state after
+// event toID. When toID is nil, it shows what event fromID itself changed.
+func (s *BookService) Diff(ctx context.Context, bookID, fromID uuid.UUID, toID *uuid.UUID) ([]domain.FieldChange, error) {
+	from, err := s.getBookEvent(ctx, bookID, fromID)
+	if err != nil {
+		return nil, err
+	}
+
+	if toID == nil {
+		return diffSnapshots(from.Snapshot.Before, from.Snapshot.After)
+	}
+
+	to, err := s.getBookEvent(ctx, bookID, *toID)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffSnapshots(from.Snapshot.After, to.Snapshot.After)
+}
+
+func (s *BookService) getBookEvent(ctx context.Context, bookID, id uuid.UUID) (*domain.BookEvent, error) {
+	event, err := s.bookRepo.GetEventByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if event.BookID != bookID {
+		return nil, ErrEventBookMismatch
+	}
+	return event, nil
+}
+
+func recordEvent(
+	ctx context.Context,
+	tx repository.BookTx,
+	bookID uuid.UUID,
+	eventType domain.BookEventType,
+	before *domain.BookSnapshot,
+	actorID *uuid.UUID,
+) error {
+	after, err := tx.GetSnapshot(ctx, bookID)
+	if err != nil {
+		return err
+	}
+
+	return tx.CreateEvent(ctx, domain.BookEvent{
+		ID:          uuid.New(),
+		BookID:      bookID,
+		Type:        eventType,
+		OccurredAt:  time.Now(),
+		ActorUserID: actorID,
+		Snapshot: domain.BookEventSnapshot{
+			Before: before,
+			After:  after,
+		},
+	})
+}
+
+func diffSnapshots(a, b *domain.BookSnapshot) ([]domain.FieldChange, error) {
+	left, err := snapshotFields(a)
+	if err != nil {
+		return nil, err
+	}
+	right, err := snapshotFields(b)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]struct{}, len(left)+len(right))
+	for k := range left {
+		keys[k] = struct{}{}
+	}
+	for k := range right {
+		keys[k] = struct{}{}
+	}
+
+	fields := make([]string, 0, len(keys))
+	for k := range keys {
+		if !diffIgnoredFields[k] {
+			fields = append(fields, k)
+		}
+	}
+	sort.Strings(fields)
+
+	changes := make([]domain.FieldChange, 0)
+	for _, field := range fields {
+		if reflect.DeepEqual(left[field], right[field]) {
+			continue
+		}
+		changes = append(changes, domain.FieldChange{
+			Field: field,
+			From:  left[field],
+			To:    right[field],
+		})
+	}
+
+	return changes, nil
+}
+
+// snapshotFields flattens a snapshot into the JSON field names of the book
+// plus a single "works" field, so diffs use the same names as the API.
+func snapshotFields(snapshot *domain.BookSnapshot) (map[string]any, error) {
+	fields := make(map[string]any)
+	if snapshot == nil {
+		return fields, nil
+	}
+
+	raw, err := json.Marshal(snapshot.Book)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	raw, err = json.Marshal(snapshot.Works)
+	if err != nil {
+		return nil, err
+	}
+	var works any
+	if err := json.Unmarshal(raw, &works); err != nil {
+		return nil, err
+	}
+	fields["works"] = works
+
+	return fields, nil
+}
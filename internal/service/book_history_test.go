@@ -0,0 +1,67 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"elibrary/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	workID := uuid.New()
+	year := 1999
+
+	before := &domain.BookSnapshot{
+		Book: domain.Book{ID: id, Title: "Old", Barcode: "2000000000015"},
+	}
+	after := &domain.BookSnapshot{
+		Book:  domain.Book{ID: id, Title: "New", Barcode: "2000000000015", Year: &year},
+		Works: []domain.BookSnapshotWork{{WorkID: workID}},
+	}
+
+	got, err := diffSnapshots(before, after)
+	if err != nil {
+		t.Fatalf("diffSnapshots() error = %v", err)
+	}
+
+	fields := make([]string, 0, len(got))
+	for _, change := range got {
+		fields = append(fields, change.Field)
+	}
+	want := []string{"title", "works", "year"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Fatalf("changed fields = %v, want %v", fields, want)
+	}
+	if got[0].From != "Old" || got[0].To != "New" {
+		t.Fatalf("title change = %v -> %v, want Old -> New", got[0].From, got[0].To)
+	}
+	if got[2].From != nil || got[2].To != float64(1999) {
+		t.Fatalf("year change = %v -> %v, want <nil> -> 1999", got[2].From, got[2].To)
+	}
+}
+
+func TestDiffSnapshotsFromNothing(t *testing.T) {
+	t.Parallel()
+
+	got, err := diffSnapshots(nil, &domain.BookSnapshot{Book: domain.Book{Title: "Created"}})
+	if err != nil {
+		t.Fatalf("diffSnapshots() error = %v", err)
+	}
+
+	for _, change := range got {
+		if change.Field == "updated_at" {
+			t.Fatal("diffSnapshots() reported updated_at, want it ignored")
+		}
+		if change.From != nil {
+			t.Fatalf("change %q from = %v, want nil", change.Field, change.From)
+		}
+	}
+	if len(got) == 0 {
+		t.Fatal("diffSnapshots() returned no changes for a created book")
+	}
+}
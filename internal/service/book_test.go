@@ -0,0 +1,558 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"elibrary/internal/domain"
+	"elibrary/internal/readmodel"
+	"elibrary/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// memBookRepo keeps books in memory. WithTx works on a copy that is only
+// kept when the callback succeeds, like a rolled back transaction.
+type memBookRepo struct {
+	repository.BookRepository
+	state     memBookState
+	locations map[uuid.UUID]bool
+	onLoan    map[uuid.UUID]bool
+}
+
+type memBookState struct {
+	books     map[uuid.UUID]domain.Book
+	movements []domain.BookMovement
+	events    []domain.BookEvent
+	replaced  []domain.ReplacedBarcode
+	// reserved maps reserved barcodes to whether they are bound to a book.
+	reserved map[string]bool
+}
+
+func (s memBookState) clone() memBookState {
+	books := make(map[uuid.UUID]domain.Book, len(s.books))
+	for id, b := range s.books {
+		books[id] = b
+	}
+	reserved := make(map[string]bool, len(s.reserved))
+	for code, bound := range s.reserved {
+		reserved[code] = bound
+	}
+	return memBookState{
+		books:     books,
+		movements: append([]domain.BookMovement(nil), s.movements...),
+		events:    append([]domain.BookEvent(nil), s.events...),
+		replaced:  append([]domain.ReplacedBarcode(nil), s.replaced...),
+		reserved:  reserved,
+	}
+}
+
+func newMemBookRepo(books ...domain.Book) *memBookRepo {
+	r := &memBookRepo{
+		state:     memBookState{books: make(map[uuid.UUID]domain.Book), reserved: make(map[string]bool)},
+		locations: make(map[uuid.UUID]bool),
+		onLoan:    make(map[uuid.UUID]bool),
+	}
+	for _, b := range books {
+		r.state.books[b.ID] = b
+		if b.LocationID != nil {
+			r.locations[*b.LocationID] = true
+		}
+	}
+	return r
+}
+
+func (r *memBookRepo) WithTx(ctx context.Context, fn func(tx repository.BookTx) error) error {
+	tx := &memBookTx{repo: r, state: r.state.clone()}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	r.state = tx.state
+	return nil
+}
+
+func (r *memBookRepo) GetMovements(ctx context.Context, bookID uuid.UUID) ([]*domain.BookMovement, error) {
+	var res []*domain.BookMovement
+	for i := len(r.state.movements) - 1; i >= 0; i-- {
+		if m := r.state.movements[i]; m.BookID == bookID {
+			res = append(res, &m)
+		}
+	}
+	if len(res) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return res, nil
+}
+
+func (r *memBookRepo) GetReplacedBarcodes(ctx context.Context, bookID uuid.UUID) ([]*domain.ReplacedBarcode, error) {
+	var res []*domain.ReplacedBarcode
+	for i := len(r.state.replaced) - 1; i >= 0; i-- {
+		if e := r.state.replaced[i]; e.BookID != nil && *e.BookID == bookID {
+			res = append(res, &e)
+		}
+	}
+	if len(res) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return res, nil
+}
+
+// GetInternal matches by ID, barcode or factory barcode and, like the SQL,
+// returns live books unless filter.Deleted asks for the trash.
+func (r *memBookRepo) GetInternal(ctx context.Context, filter repository.BookFilter) ([]*readmodel.BookInternal, error) {
+	var res []*readmodel.BookInternal
+	for _, b := range r.state.books {
+		if (b.DeletedAt != nil) != filter.Deleted {
+			continue
+		}
+		if filter.ID != nil && b.ID != *filter.ID || filter.Barcode != nil && b.Barcode != *filter.Barcode {
+			continue
+		}
+		if filter.FactoryBarcode != nil && (b.FactoryBarcode == nil || *b.FactoryBarcode != *filter.FactoryBarcode) {
+			continue
+		}
+		res = append(res, &readmodel.BookInternal{ID: b.ID, Title: b.Title, Barcode: b.Barcode, DeletedAt: b.DeletedAt})
+	}
+	if len(res) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return res, nil
+}
+
+type memBookTx struct {
+	repository.BookTx
+	repo  *memBookRepo
+	state memBookState
+}
+
+func (t *memBookTx) GetSnapshot(ctx context.Context, id uuid.UUID) (*domain.BookSnapshot, error) {
+	book, ok := t.state.books[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &domain.BookSnapshot{Book: book}, nil
+}
+
+func (t *memBookTx) UpdateBook(ctx context.Context, book domain.Book) error {
+	if _, ok := t.state.books[book.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	if book.LocationID != nil && !t.repo.locations[*book.LocationID] {
+		return domain.ErrLocationNotFound
+	}
+	for _, other := range t.state.books {
+		if other.ID != book.ID && book.Barcode != "" && other.Barcode == book.Barcode {
+			return domain.ErrBarcodeExists
+		}
+	}
+	t.state.books[book.ID] = book
+	return nil
+}
+
+func (t *memBookTx) BindReservedBarcode(ctx context.Context, barcode string, bookID uuid.UUID) error {
+	bound, ok := t.state.reserved[barcode]
+	if !ok {
+		return domain.ErrBarcodeNotReserved
+	}
+	if bound {
+		return domain.ErrBarcodeBound
+	}
+	t.state.reserved[barcode] = true
+	return nil
+}
+
+func (t *memBookTx) AddReplacedBarcode(ctx context.Context, entry domain.ReplacedBarcode) error {
+	t.state.replaced = append(t.state.replaced, entry)
+	return nil
+}
+
+func (t *memBookTx) CreateMovement(ctx context.Context, movement domain.BookMovement) error {
+	t.state.movements = append(t.state.movements, movement)
+	return nil
+}
+
+func (t *memBookTx) CreateEvent(ctx context.Context, event domain.BookEvent) error {
+	t.state.events = append(t.state.events, event)
+	return nil
+}
+
+func (t *memBookTx) LockBook(ctx context.Context, id uuid.UUID) error {
+	if _, ok := t.state.books[id]; !ok {
+		return repository.ErrNotFound
+	}
+	return nil
+}
+
+func (t *memBookTx) HasActiveLoan(ctx context.Context, id uuid.UUID) (bool, error) {
+	return t.repo.onLoan[id], nil
+}
+
+func (t *memBookTx) SoftDelete(ctx context.Context, id uuid.UUID, deletedBy *uuid.UUID) error {
+	book, ok := t.state.books[id]
+	if !ok || book.DeletedAt != nil {
+		return repository.ErrNotFound
+	}
+	now := time.Now()
+	book.DeletedAt, book.DeletedBy = &now, deletedBy
+	t.state.books[id] = book
+	return nil
+}
+
+func (t *memBookTx) Restore(ctx context.Context, id uuid.UUID) error {
+	book, ok := t.state.books[id]
+	if !ok || book.DeletedAt == nil {
+		return repository.ErrNotFound
+	}
+	book.DeletedAt, book.DeletedBy = nil, nil
+	t.state.books[id] = book
+	return nil
+}
+
+func (t *memBookTx) Purge(ctx context.Context, id uuid.UUID) error {
+	book, ok := t.state.books[id]
+	if !ok || book.DeletedAt == nil {
+		return repository.ErrNotFound
+	}
+	delete(t.state.books, id)
+	return nil
+}
+
+func TestBookServiceMove(t *testing.T) {
+	t.Parallel()
+
+	hall, shelf := uuid.New(), uuid.New()
+	atHall := domain.Book{ID: uuid.New(), Title: "Война и мир", LocationID: &hall}
+	atShelf := domain.Book{ID: uuid.New(), Title: "Анна Каренина", LocationID: &shelf}
+	repo := newMemBookRepo(atHall, atShelf)
+	svc := NewBookService(repo, nil, nil, nil, nil, nil)
+
+	actor := uuid.New()
+	reason := "инвентаризация"
+	err := svc.Move(context.Background(), MoveBooksRequest{
+		BookIDs:    []uuid.UUID{atHall.ID, atShelf.ID},
+		LocationID: &shelf,
+		Reason:     &reason,
+	}, &actor)
+	if err != nil {
+		t.Fatalf("Move() error = %v", err)
+	}
+
+	if got := repo.state.books[atHall.ID].LocationID; got == nil || *got != shelf {
+		t.Fatalf("location = %v, want %v", got, shelf)
+	}
+	if len(repo.state.movements) != 1 {
+		t.Fatalf("movements = %d, want 1 for the book that changed place", len(repo.state.movements))
+	}
+	m := repo.state.movements[0]
+	if m.BookID != atHall.ID || *m.FromLocationID != hall || *m.ToLocationID != shelf {
+		t.Fatalf("movement = %+v, want %v from %v to %v", m, atHall.ID, hall, shelf)
+	}
+	if m.ActorUserID == nil || *m.ActorUserID != actor || m.Reason == nil || *m.Reason != reason {
+		t.Fatalf("movement actor and reason = %v, %v, want %v, %q", m.ActorUserID, m.Reason, actor, reason)
+	}
+
+	history, err := svc.GetMovements(context.Background(), atHall.ID)
+	if err != nil {
+		t.Fatalf("GetMovements() error = %v", err)
+	}
+	if len(history) != 1 || history[0].ID != m.ID {
+		t.Fatalf("GetMovements() = %v, want the recorded movement", history)
+	}
+
+	if _, err := svc.GetMovements(context.Background(), atShelf.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetMovements() of a book that never moved error = %v, want %v", err, domain.ErrNotFound)
+	}
+}
+
+func TestBookServiceMoveToUnknownLocation(t *testing.T) {
+	t.Parallel()
+
+	hall := uuid.New()
+	book := domain.Book{ID: uuid.New(), Title: "Война и мир", LocationID: &hall}
+	repo := newMemBookRepo(book)
+	svc := NewBookService(repo, nil, nil, nil, nil, nil)
+
+	missing := uuid.New()
+	err := svc.Move(context.Background(), MoveBooksRequest{BookIDs: []uuid.UUID{book.ID}, LocationID: &missing}, nil)
+	if !errors.Is(err, domain.ErrLocationNotFound) {
+		t.Fatalf("Move() error = %v, want %v", err, domain.ErrLocationNotFound)
+	}
+	if got := repo.state.books[book.ID].LocationID; *got != hall {
+		t.Fatalf("location = %v, want the book to stay at %v", got, hall)
+	}
+	if len(repo.state.movements) != 0 {
+		t.Fatalf("movements = %d, want none", len(repo.state.movements))
+	}
+}
+
+func TestBookServiceMoveRollsBackOnUnknownBook(t *testing.T) {
+	t.Parallel()
+
+	hall, shelf := uuid.New(), uuid.New()
+	book := domain.Book{ID: uuid.New(), Title: "Война и мир", LocationID: &hall}
+	repo := newMemBookRepo(book)
+	repo.locations[shelf] = true
+	svc := NewBookService(repo, nil, nil, nil, nil, nil)
+
+	err := svc.Move(context.Background(), MoveBooksRequest{BookIDs: []uuid.UUID{book.ID, uuid.New()}, LocationID: &shelf}, nil)
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("Move() error = %v, want %v", err, domain.ErrNotFound)
+	}
+	if got := repo.state.books[book.ID].LocationID; *got != hall {
+		t.Fatalf("location = %v, want the whole move rolled back", got)
+	}
+	if len(repo.state.movements) != 0 {
+		t.Fatalf("movements = %d, want none", len(repo.state.movements))
+	}
+
+	if err := svc.Move(context.Background(), MoveBooksRequest{LocationID: &shelf}, nil); err == nil {
+		t.Fatal("Move() without books error = nil, want an error")
+	}
+}
+
+func TestBookServiceDeleteAndRestore(t *testing.T) {
+	t.Parallel()
+
+	book := domain.Book{ID: uuid.New(), Title: "Война и мир", Barcode: "2000000000015"}
+	repo := newMemBookRepo(book)
+	svc := NewBookService(repo, nil, nil, nil, nil, nil)
+	scan := NewScanService(repo, stubLocationRepo{}, NewBarcodeService(nil))
+	ctx := context.Background()
+
+	actor := uuid.New()
+	if err := svc.Delete(ctx, book.ID, &actor); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := svc.GetInternal(ctx, repository.BookFilter{}); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("GetInternal() after delete error = %v, want %v", err, domain.ErrNotFound)
+	}
+	if _, err := scan.Resolve(ctx, book.Barcode); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("Resolve() after delete error = %v, want %v", err, domain.ErrNotFound)
+	}
+	trash, err := svc.GetTrash(ctx, repository.BookFilter{})
+	if err != nil || len(trash) != 1 || trash[0].ID != book.ID {
+		t.Fatalf("GetTrash() = %v, %v, want the deleted book", trash, err)
+	}
+	if err := svc.Delete(ctx, book.ID, &actor); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("Delete() twice error = %v, want %v", err, domain.ErrNotFound)
+	}
+
+	if err := svc.Restore(ctx, book.ID, &actor); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+	if books, err := svc.GetInternal(ctx, repository.BookFilter{}); err != nil || len(books) != 1 {
+		t.Fatalf("GetInternal() after restore = %v, %v, want the book back", books, err)
+	}
+	if res, err := scan.Resolve(ctx, book.Barcode); err != nil || res.Book == nil || res.Book.ID != book.ID {
+		t.Fatalf("Resolve() after restore = %+v, %v, want the book", res, err)
+	}
+	if err := svc.Restore(ctx, book.ID, &actor); !errors.Is(err, domain.ErrBookNotDeleted) {
+		t.Fatalf("Restore() of a live book error = %v, want %v", err, domain.ErrBookNotDeleted)
+	}
+
+	types := make([]domain.BookEventType, 0, len(repo.state.events))
+	for _, e := range repo.state.events {
+		types = append(types, e.Type)
+	}
+	if len(types) != 2 || types[0] != domain.BookEventDeleted || types[1] != domain.BookEventRestored {
+		t.Fatalf("events = %v, want deleted and restored", types)
+	}
+}
+
+func TestBookServiceDeleteRejectsBookOnLoan(t *testing.T) {
+	t.Parallel()
+
+	book := domain.Book{ID: uuid.New(), Title: "Война и мир"}
+	repo := newMemBookRepo(book)
+	repo.onLoan[book.ID] = true
+	svc := NewBookService(repo, nil, nil, nil, nil, nil)
+
+	if err := svc.Delete(context.Background(), book.ID, nil); !errors.Is(err, domain.ErrBookOnLoan) {
+		t.Fatalf("Delete() error = %v, want %v", err, domain.ErrBookOnLoan)
+	}
+	if repo.state.books[book.ID].DeletedAt != nil {
+		t.Fatal("book on loan was moved to the trash")
+	}
+}
+
+func TestBookServicePurge(t *testing.T) {
+	t.Parallel()
+
+	live := domain.Book{ID: uuid.New(), Title: "Война и мир"}
+	deletedAt := time.Now()
+	trashed := domain.Book{ID: uuid.New(), Title: "Анна Каренина", DeletedAt: &deletedAt}
+	repo := newMemBookRepo(live, trashed)
+	svc := NewBookService(repo, nil, nil, nil, nil, nil)
+	ctx := context.Background()
+
+	if err := svc.Purge(ctx, live.ID); !errors.Is(err, domain.ErrBookNotDeleted) {
+		t.Fatalf("Purge() of a live book error = %v, want %v", err, domain.ErrBookNotDeleted)
+	}
+	if _, ok := repo.state.books[live.ID]; !ok {
+		t.Fatal("Purge() removed a live book")
+	}
+	if err := svc.Purge(ctx, uuid.New()); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("Purge() of an unknown book error = %v, want %v", err, domain.ErrNotFound)
+	}
+
+	if err := svc.Purge(ctx, trashed.ID); err != nil {
+		t.Fatalf("Purge() error = %v", err)
+	}
+	if _, ok := repo.state.books[trashed.ID]; ok {
+		t.Fatal("Purge() kept the trashed book")
+	}
+}
+
+func TestBookServiceRelabel(t *testing.T) {
+	t.Parallel()
+
+	book := domain.Book{ID: uuid.New(), Title: "Война и мир", Barcode: "2000000000015"}
+	repo := newMemBookRepo(book)
+	repo.state.reserved["9780000001238"] = false
+	svc := NewBookService(repo, nil, nil, nil, NewBarcodeService(nil), nil)
+	ctx := context.Background()
+
+	if _, err := svc.BarcodeHistory(ctx, book.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("BarcodeHistory() before relabel error = %v, want %v", err, domain.ErrNotFound)
+	}
+
+	actor := uuid.New()
+	reason := "этикетка повреждена"
+	got, err := svc.Relabel(ctx, book.ID, RelabelRequest{Barcode: "9780000001238", Reason: &reason}, &actor)
+	if err != nil {
+		t.Fatalf("Relabel() error = %v", err)
+	}
+	if got.Barcode != "9780000001238" || repo.state.books[book.ID].Barcode != "9780000001238" {
+		t.Fatalf("barcode = %q, want the reserved code", repo.state.books[book.ID].Barcode)
+	}
+	if !repo.state.reserved["9780000001238"] {
+		t.Fatal("reserved barcode was not bound")
+	}
+
+	history, err := svc.BarcodeHistory(ctx, book.ID)
+	if err != nil {
+		t.Fatalf("BarcodeHistory() error = %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("BarcodeHistory() = %d entries, want 1", len(history))
+	}
+	e := history[0]
+	if e.Barcode != book.Barcode || e.ReplacedBy != "9780000001238" || *e.Reason != reason || *e.CreatedBy != actor {
+		t.Fatalf("history entry = %+v, want %q replaced by the reserved code", e, book.Barcode)
+	}
+	if len(repo.state.events) != 1 || repo.state.events[0].Type != domain.BookEventUpdated {
+		t.Fatalf("events = %v, want one update", repo.state.events)
+	}
+}
+
+func TestBookServiceRelabelRejectsCodeInUse(t *testing.T) {
+	t.Parallel()
+
+	book := domain.Book{ID: uuid.New(), Title: "Война и мир", Barcode: "2000000000015"}
+	other := domain.Book{ID: uuid.New(), Title: "Анна Каренина", Barcode: "9780000001245"}
+	repo := newMemBookRepo(book, other)
+	repo.state.reserved["9780000001238"] = true
+	repo.state.reserved["9780000001245"] = false
+	svc := NewBookService(repo, nil, nil, nil, NewBarcodeService(nil), nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		barcode string
+		want    error
+	}{
+		{name: "bound", barcode: "9780000001238", want: domain.ErrBarcodeBound},
+		{name: "on another book", barcode: "9780000001245", want: domain.ErrBarcodeExists},
+		{name: "not reserved", barcode: "4600000000008", want: domain.ErrBarcodeNotReserved},
+		{name: "invalid", barcode: "2000000000016", want: domain.ErrInvalidBarcode},
+	}
+
+	for _, tt := range tests {
+		_, err := svc.Relabel(ctx, book.ID, RelabelRequest{Barcode: tt.barcode}, nil)
+		if !errors.Is(err, tt.want) {
+			t.Fatalf("%s: Relabel() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	if got := repo.state.books[book.ID].Barcode; got != book.Barcode {
+		t.Fatalf("barcode = %q, want %q kept", got, book.Barcode)
+	}
+	if len(repo.state.replaced) != 0 || len(repo.state.events) != 0 {
+		t.Fatalf("history = %v, events = %v, want none", repo.state.replaced, repo.state.events)
+	}
+}
+
+func TestBookServiceUpdateStandardNumbers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		book    domain.Book
+		updates UpdateBookRequest
+		isbn    *string
+		issn    *string
+	}{
+		{
+			name:    "new isbn barcode",
+			book:    domain.Book{FactoryBarcode: strPtr("9785170988853"), ISBN: strPtr("9785170988853")},
+			updates: UpdateBookRequest{FactoryBarcode: strPtr("978-0-8044-2957-3")},
+			isbn:    strPtr("9780804429573"),
+		},
+		{
+			name:    "serial barcode",
+			book:    domain.Book{FactoryBarcode: strPtr("9785170988853"), ISBN: strPtr("9785170988853")},
+			updates: UpdateBookRequest{FactoryBarcode: strPtr("9770317847001")},
+			issn:    strPtr("03178471"),
+		},
+		{
+			name:    "barcode without a number",
+			book:    domain.Book{FactoryBarcode: strPtr("9770317847001"), ISSN: strPtr("03178471")},
+			updates: UpdateBookRequest{FactoryBarcode: strPtr("4607001234562")},
+		},
+		{
+			name:    "isbn entered by hand",
+			book:    domain.Book{FactoryBarcode: strPtr("4607001234562"), ISBN: strPtr("9780804429573")},
+			updates: UpdateBookRequest{FactoryBarcode: strPtr("9785170988853")},
+			isbn:    strPtr("9780804429573"),
+		},
+		{
+			name:    "isbn sent with the barcode",
+			book:    domain.Book{FactoryBarcode: strPtr("9785170988853"), ISBN: strPtr("9785170988853")},
+			updates: UpdateBookRequest{FactoryBarcode: strPtr("4607001234562"), ISBN: strPtr("5-17-098885-0")},
+			isbn:    strPtr("9785170988853"),
+		},
+		{
+			name:    "isbn cleared",
+			book:    domain.Book{FactoryBarcode: strPtr("9785170988853"), ISBN: strPtr("9785170988853")},
+			updates: UpdateBookRequest{ISBN: strPtr("")},
+		},
+		{
+			name:    "other fields",
+			book:    domain.Book{FactoryBarcode: strPtr("9785170988853"), ISBN: strPtr("9785170988853")},
+			updates: UpdateBookRequest{Title: strPtr("Анна Каренина")},
+			isbn:    strPtr("9785170988853"),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			book := tt.book
+			book.ID, book.Title = uuid.New(), "Война и мир"
+			repo := newMemBookRepo(book)
+			svc := NewBookService(repo, nil, nil, nil, nil, nil)
+
+			if err := svc.Update(context.Background(), book.ID, tt.updates, nil); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			got := repo.state.books[book.ID]
+			if !equalStrPtr(got.ISBN, tt.isbn) || !equalStrPtr(got.ISSN, tt.issn) {
+				t.Fatalf("Update() ISBN, ISSN = %v, %v, want %v, %v", got.ISBN, got.ISSN, tt.isbn, tt.issn)
+			}
+		})
+	}
+}
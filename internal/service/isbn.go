@@ -0,0 +1,100 @@
+package service
+
+import (
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+	"strings"
+)
+
+// normalizeStandardNumbers checks the ISBN and ISSN of a book and stores them
+// in their compact form; a blank one is cleared. A missing number is taken
+// from the factory barcode when that is the EAN-13 of an ISBN or ISSN.
+func normalizeStandardNumbers(book *domain.Book) error {
+	isbn, err := normalizeStandardNumber(book.ISBN, domain.ParseISBN)
+	if err != nil {
+		return err
+	}
+	issn, err := normalizeStandardNumber(book.ISSN, domain.ParseISSN)
+	if err != nil {
+		return err
+	}
+	if book.ISBN == nil {
+		isbn = factoryISBN(book.FactoryBarcode)
+	}
+	if book.ISSN == nil {
+		issn = factoryISSN(book.FactoryBarcode)
+	}
+	book.ISBN, book.ISSN = isbn, issn
+	return nil
+}
+
+func normalizeStandardNumber(value *string, parse func(string) (string, error)) (*string, error) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil, nil
+	}
+	n, err := parse(*value)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// factoryISBN and factoryISSN read the number a factory barcode carries, as
+// migration 016 does: an ISBN-13 or ISBN-10, or the 977 EAN-13 of a serial.
+// A bare ISSN is not a barcode, so eight characters give no ISSN.
+func factoryISBN(barcode *string) *string {
+	if barcode == nil {
+		return nil
+	}
+	isbn, err := domain.ParseISBN(*barcode)
+	if err != nil {
+		return nil
+	}
+	return &isbn
+}
+
+func factoryISSN(barcode *string) *string {
+	if barcode == nil {
+		return nil
+	}
+	code := strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(*barcode))
+	if len(code) != 13 || !strings.HasPrefix(code, "977") {
+		return nil
+	}
+	issn, err := domain.ParseISSN(code)
+	if err != nil {
+		return nil
+	}
+	return &issn
+}
+
+func equalStrPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// withStandardNumbers lets a factory barcode or query that reads as an ISBN
+// or ISSN, in any spelling, also match the normalized numbers of the books.
+func withStandardNumbers(filter repository.BookFilter) repository.BookFilter {
+	code := filter.FactoryBarcode
+	if code == nil {
+		code = filter.Query
+	}
+	if code == nil {
+		return filter
+	}
+
+	if filter.ISBN == nil {
+		if isbn, err := domain.ParseISBN(*code); err == nil {
+			filter.ISBN = &isbn
+		}
+	}
+	if filter.ISSN == nil {
+		if issn, err := domain.ParseISSN(*code); err == nil {
+			filter.ISSN = &issn
+		}
+	}
+	return filter
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"elibrary/internal/domain"
+	"elibrary/internal/readmodel"
+	"elibrary/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+func TestNormalizeStandardNumbers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		book domain.Book
+		isbn *string
+		issn *string
+		err  error
+	}{
+		{name: "isbn-10", book: domain.Book{ISBN: strPtr("5-17-098885-0")}, isbn: strPtr("9785170988853")},
+		{name: "from factory barcode", book: domain.Book{FactoryBarcode: strPtr("9785170988853")}, isbn: strPtr("9785170988853")},
+		{name: "issn from factory barcode", book: domain.Book{FactoryBarcode: strPtr("9770317847001")}, issn: strPtr("03178471")},
+		{name: "other factory barcode", book: domain.Book{FactoryBarcode: strPtr("4607001234562")}},
+		{name: "isbn-10 factory barcode", book: domain.Book{FactoryBarcode: strPtr("5-17-098885-0")}, isbn: strPtr("9785170988853")},
+		{name: "issn is not a factory barcode", book: domain.Book{FactoryBarcode: strPtr("0317-8471")}},
+		{name: "blank", book: domain.Book{ISBN: strPtr(" "), ISSN: strPtr("")}},
+		{name: "blank overrides factory barcode", book: domain.Book{FactoryBarcode: strPtr("9785170988853"), ISBN: strPtr("")}},
+		{name: "bad isbn", book: domain.Book{ISBN: strPtr("978-5-17-098885-4")}, err: domain.ErrInvalidISBN},
+		{name: "bad issn", book: domain.Book{ISSN: strPtr("0317-8472")}, err: domain.ErrInvalidISSN},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			book := tt.book
+			err := normalizeStandardNumbers(&book)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("normalizeStandardNumbers() error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if !equalStrPtr(book.ISBN, tt.isbn) || !equalStrPtr(book.ISSN, tt.issn) {
+				t.Fatalf("normalizeStandardNumbers() = %v, %v, want %v, %v", book.ISBN, book.ISSN, tt.isbn, tt.issn)
+			}
+		})
+	}
+}
+
+func TestWithStandardNumbers(t *testing.T) {
+	t.Parallel()
+
+	got := withStandardNumbers(repository.BookFilter{Query: strPtr("ISBN 5-17-098885-0")})
+	if !equalStrPtr(got.ISBN, strPtr("9785170988853")) || got.ISSN != nil {
+		t.Fatalf("withStandardNumbers(query) = %v, %v, want the ISBN-13 only", got.ISBN, got.ISSN)
+	}
+
+	got = withStandardNumbers(repository.BookFilter{FactoryBarcode: strPtr("0317-8471"), Query: strPtr("9785170988853")})
+	if got.ISBN != nil || !equalStrPtr(got.ISSN, strPtr("03178471")) {
+		t.Fatalf("withStandardNumbers(factory barcode) = %v, %v, want the ISSN of the factory barcode", got.ISBN, got.ISSN)
+	}
+
+	got = withStandardNumbers(repository.BookFilter{Query: strPtr("война и мир")})
+	if got.ISBN != nil || got.ISSN != nil {
+		t.Fatalf("withStandardNumbers(text) = %v, %v, want nil", got.ISBN, got.ISSN)
+	}
+}
+
+type stubDuplicateBookRepo struct {
+	repository.BookRepository
+	books []*readmodel.BookPublic
+}
+
+func (s stubDuplicateBookRepo) GetPublic(ctx context.Context, filter repository.BookFilter) ([]*readmodel.BookPublic, error) {
+	var res []*readmodel.BookPublic
+	for _, b := range s.books {
+		if filter.ISBN != nil && b.ISBN != nil && domain.HyphenateISBN(*filter.ISBN) == *b.ISBN {
+			res = append(res, b)
+		}
+	}
+	if len(res) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return res, nil
+}
+
+func TestBookServiceFindDuplicates(t *testing.T) {
+	t.Parallel()
+
+	existing := &readmodel.BookPublic{ID: uuid.New(), Title: "Война и мир", ISBN: strPtr("978-5-17098885-3")}
+	svc := NewBookService(stubDuplicateBookRepo{books: []*readmodel.BookPublic{existing}}, nil, nil, nil, nil, nil)
+
+	got, err := svc.findDuplicates(context.Background(), domain.Book{ISBN: strPtr("9785170988853")})
+	if err != nil {
+		t.Fatalf("findDuplicates() error = %v", err)
+	}
+	if len(got) != 1 || got[0].ID != existing.ID {
+		t.Fatalf("findDuplicates() = %v, want the existing book", got)
+	}
+
+	got, err = svc.findDuplicates(context.Background(), domain.Book{ISBN: strPtr("9780804429573")})
+	if err != nil || got != nil {
+		t.Fatalf("findDuplicates() = %v, %v, want no duplicates", got, err)
+	}
+}
@@ -13,29 +13,43 @@ type JWTManager struct {
 	TTL    time.Duration
 }
 
-func (m *JWTManager) Generate(userID uuid.UUID) (string, error) {
-	claims := jwt.RegisteredClaims{
-		Subject:   userID.String(),
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.TTL)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
+// accessClaims carries the user's token version so that access tokens issued
+// before a session revocation are rejected even if they have not expired yet.
+type accessClaims struct {
+	jwt.RegisteredClaims
+	Version int `json:"ver"`
+}
+
+func (m *JWTManager) Generate(userID uuid.UUID, version int) (string, error) {
+	claims := accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID.String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.TTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+		Version: version,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(m.Secret)
 }
 
-func (m *JWTManager) Parse(tokenStr string) (uuid.UUID, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &jwt.RegisteredClaims{}, func(token *jwt.Token) (any, error) {
+func (m *JWTManager) Parse(tokenStr string) (uuid.UUID, int, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &accessClaims{}, func(token *jwt.Token) (any, error) {
 		return m.Secret, nil
-	})
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
 	if err != nil {
-		return uuid.Nil, err
+		return uuid.Nil, 0, err
 	}
 
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
+	claims, ok := token.Claims.(*accessClaims)
 	if !ok || !token.Valid {
-		return uuid.Nil, errors.New("invalid token")
+		return uuid.Nil, 0, errors.New("invalid token")
 	}
 
-	return uuid.Parse(claims.Subject)
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return uuid.Nil, 0, err
+	}
+	return userID, claims.Version, nil
 }
@@ -17,18 +17,21 @@ func TestJWTManagerGenerateAndParse(t *testing.T) {
 	}
 	userID := uuid.New()
 
-	token, err := manager.Generate(userID)
+	token, err := manager.Generate(userID, 3)
 	if err != nil {
 		t.Fatalf("Generate() error = %v", err)
 	}
 
-	gotUserID, err := manager.Parse(token)
+	gotUserID, gotVersion, err := manager.Parse(token)
 	if err != nil {
 		t.Fatalf("Parse() error = %v", err)
 	}
 	if gotUserID != userID {
 		t.Fatalf("Parse() userID = %v, want %v", gotUserID, userID)
 	}
+	if gotVersion != 3 {
+		t.Fatalf("Parse() version = %d, want %d", gotVersion, 3)
+	}
 }
 
 func TestJWTManagerParseRejectsDifferentSecret(t *testing.T) {
@@ -43,12 +46,12 @@ func TestJWTManagerParseRejectsDifferentSecret(t *testing.T) {
 		TTL:    time.Minute,
 	}
 
-	token, err := issuer.Generate(uuid.New())
+	token, err := issuer.Generate(uuid.New(), 0)
 	if err != nil {
 		t.Fatalf("Generate() error = %v", err)
 	}
 
-	if _, err := validator.Parse(token); err == nil {
+	if _, _, err := validator.Parse(token); err == nil {
 		t.Fatal("Parse() error = nil, want signature validation error")
 	}
 }
@@ -61,17 +64,24 @@ func TestJWTManagerParseRejectsTamperedToken(t *testing.T) {
 		TTL:    time.Minute,
 	}
 
-	token, err := manager.Generate(uuid.New())
+	token, err := manager.Generate(uuid.New(), 0)
 	if err != nil {
 		t.Fatalf("Generate() error = %v", err)
 	}
 
-	tampered := token[:len(token)-1] + "x"
+	// Replace the first signature character: it carries six full bits of the
+	// signature, whereas the last one may only carry padding bits.
+	sigStart := strings.LastIndex(token, ".") + 1
+	replacement := "A"
+	if token[sigStart] == 'A' {
+		replacement = "B"
+	}
+	tampered := token[:sigStart] + replacement + token[sigStart+1:]
 	if tampered == token {
 		t.Fatal("failed to tamper token")
 	}
 
-	if _, err := manager.Parse(tampered); err == nil {
+	if _, _, err := manager.Parse(tampered); err == nil {
 		t.Fatal("Parse() error = nil, want parse error")
 	}
 }
@@ -84,12 +94,12 @@ func TestJWTManagerParseRejectsExpiredToken(t *testing.T) {
 		TTL:    -time.Second,
 	}
 
-	token, err := manager.Generate(uuid.New())
+	token, err := manager.Generate(uuid.New(), 0)
 	if err != nil {
 		t.Fatalf("Generate() error = %v", err)
 	}
 
-	_, err = manager.Parse(token)
+	_, _, err = manager.Parse(token)
 	if err == nil {
 		t.Fatal("Parse() error = nil, want expiration error")
 	}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"elibrary/internal/readmodel"
+	"elibrary/internal/repository"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// MaxLabelBatch caps how many labels one request may produce.
+const MaxLabelBatch = 1000
+
+// labelLineMax is the longest label line in characters; it fits a 58 mm
+// label in the worker's default font.
+const labelLineMax = 40
+
+var (
+	ErrInvalidSelection = errors.New("exactly one of book_ids, location_id or filter is required")
+	ErrBatchTooLarge    = errors.New("too many labels in one batch")
+)
+
+type LabelService struct {
+	books     repository.BookRepository
+	locations repository.LocationRepository
+	printJobs *PrintJobService
+	barcodes  *BarcodeService
+}
+
+func NewLabelService(
+	books repository.BookRepository,
+	locations repository.LocationRepository,
+	printJobs *PrintJobService,
+	barcodes *BarcodeService,
+) *LabelService {
+	return &LabelService{
+		books:     books,
+		locations: locations,
+		printJobs: printJobs,
+		barcodes:  barcodes,
+	}
+}
+
+// BookSelection picks the books to label. Exactly one of BookIDs,
+// LocationID and Filter must be set. Symbology overrides the configured
+// symbol for books.
+type BookSelection struct {
+	BookIDs    []uuid.UUID      `json:"book_ids,omitempty"`
+	LocationID *uuid.UUID       `json:"location_id,omitempty"`
+	Filter     *BookFilterInput `json:"filter,omitempty"`
+	Symbology  domain.Symbology `json:"symbology,omitempty"`
+	// Translit overrides the configured transliteration scheme.
+	Translit domain.TranslitScheme `json:"translit,omitempty"`
+}
+
+type BookFilterInput struct {
+	Query       *string    `json:"q,omitempty"`
+	PublisherID *uuid.UUID `json:"publisher_id,omitempty"`
+	YearFrom    *int       `json:"year_from,omitempty"`
+	YearTo      *int       `json:"year_to,omitempty"`
+}
+
+// LocationSelection picks a location and everything below it. Types, when
+// set, keeps only locations of those types, e.g. just the shelves of a room.
+type LocationSelection struct {
+	LocationID uuid.UUID             `json:"location_id"`
+	Types      []domain.LocationType `json:"types,omitempty"`
+	Symbology  domain.Symbology      `json:"symbology,omitempty"`
+	Translit   domain.TranslitScheme `json:"translit,omitempty"`
+}
+
+type BatchPrintResult struct {
+	Count  int         `json:"count"`
+	JobIDs []uuid.UUID `json:"job_ids"`
+}
+
+func (s *LabelService) PrintBooks(ctx context.Context, sel BookSelection, requestedBy *uuid.UUID) (*BatchPrintResult, error) {
+	tasks, err := s.BookTasks(ctx, sel)
+	if err != nil {
+		return nil, err
+	}
+	return s.submit(ctx, tasks, requestedBy)
+}
+
+func (s *LabelService) PrintLocations(ctx context.Context, sel LocationSelection, requestedBy *uuid.UUID) (*BatchPrintResult, error) {
+	tasks, err := s.LocationTasks(ctx, sel)
+	if err != nil {
+		return nil, err
+	}
+	return s.submit(ctx, tasks, requestedBy)
+}
+
+// BookTasks builds one label per selected book.
+func (s *LabelService) BookTasks(ctx context.Context, sel BookSelection) ([]PrintTask, error) {
+	filter, err := sel.bookFilter()
+	if err != nil {
+		return nil, err
+	}
+	if sel.Translit != "" && !sel.Translit.Valid() {
+		return nil, domain.ErrInvalidTranslit
+	}
+
+	books, err := s.books.GetInternal(ctx, filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	if len(books) > MaxLabelBatch {
+		return nil, ErrBatchTooLarge
+	}
+
+	tasks := make([]PrintTask, 0, len(books))
+	for _, book := range books {
+		task := BookLabel(book)
+		task.Translit = sel.Translit
+		if err := s.barcodes.ApplySymbology(&task, domain.BarcodeTypeBook, book.ID, sel.Symbology); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// LocationTasks builds one label per location in the selected subtree.
+func (s *LabelService) LocationTasks(ctx context.Context, sel LocationSelection) ([]PrintTask, error) {
+	for _, t := range sel.Types {
+		if t.Level() < 0 {
+			return nil, domain.ErrInvalidLocationType
+		}
+	}
+	if sel.Translit != "" && !sel.Translit.Valid() {
+		return nil, domain.ErrInvalidTranslit
+	}
+
+	subtree, err := s.locations.GetSubtree(ctx, sel.LocationID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]*domain.Location, len(subtree))
+	for _, loc := range subtree {
+		byID[loc.ID] = loc
+	}
+	// The path on a label starts at the building, so the ancestors of the
+	// selected location are needed too.
+	for parentID := subtree[0].ParentID; parentID != nil; {
+		parent, err := s.locations.GetByID(ctx, *parentID)
+		if err != nil {
+			return nil, err
+		}
+		byID[parent.ID] = parent
+		parentID = parent.ParentID
+	}
+
+	tasks := make([]PrintTask, 0, len(subtree))
+	for _, loc := range subtree {
+		if len(sel.Types) > 0 && !containsLocationType(sel.Types, loc.Type) {
+			continue
+		}
+		task := LocationLabel(loc, byID)
+		task.Translit = sel.Translit
+		if err := s.barcodes.ApplySymbology(&task, domain.BarcodeTypeLocation, loc.ID, sel.Symbology); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	if len(tasks) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	if len(tasks) > MaxLabelBatch {
+		return nil, ErrBatchTooLarge
+	}
+	return tasks, nil
+}
+
+func (s *LabelService) submit(ctx context.Context, tasks []PrintTask, requestedBy *uuid.UUID) (*BatchPrintResult, error) {
+	ids, err := s.printJobs.SubmitBatch(ctx, tasks, requestedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &BatchPrintResult{Count: len(ids), JobIDs: ids}, nil
+}
+
+func (sel BookSelection) bookFilter() (repository.BookFilter, error) {
+	set := 0
+	if len(sel.BookIDs) > 0 {
+		set++
+	}
+	if sel.LocationID != nil {
+		set++
+	}
+	if sel.Filter != nil {
+		set++
+	}
+	if set != 1 {
+		return repository.BookFilter{}, ErrInvalidSelection
+	}
+
+	// One more than the cap, so that an oversized selection is reported
+	// instead of silently truncated.
+	limit := MaxLabelBatch + 1
+	filter := repository.BookFilter{
+		IDs:        sel.BookIDs,
+		LocationID: sel.LocationID,
+		Limit:      &limit,
+	}
+	if f := sel.Filter; f != nil {
+		filter.Query = f.Query
+		filter.PublisherID = f.PublisherID
+		filter.YearFrom = f.YearFrom
+		filter.YearTo = f.YearTo
+	}
+	return withStandardNumbers(filter), nil
+}
+
+// BookLabel puts the title on the first line and the authors and shelf on
+// the second.
+func BookLabel(book *readmodel.BookInternal) PrintTask {
+	var second []string
+	if authors := shortAuthors(book.Works); authors != "" {
+		second = append(second, authors)
+	}
+	if loc := book.Location; loc != nil {
+		second = append(second, loc.CabinetName+" / "+loc.ShelfName)
+	}
+
+	return PrintTask{
+		Str1:    truncateLabelLine(book.Title),
+		Str2:    truncateLabelLine(strings.Join(second, ", ")),
+		Barcode: book.Barcode,
+	}
+}
+
+// LocationLabel puts the location name on the first line and the path of
+// its ancestors on the second.
+func LocationLabel(loc *domain.Location, byID map[uuid.UUID]*domain.Location) PrintTask {
+	var path []string
+	for parentID := loc.ParentID; parentID != nil; {
+		parent, ok := byID[*parentID]
+		if !ok {
+			break
+		}
+		path = append([]string{parent.Name}, path...)
+		parentID = parent.ParentID
+	}
+
+	return PrintTask{
+		Str1:    truncateLabelLine(loc.Name),
+		Str2:    truncateLabelLine(strings.Join(path, " / ")),
+		Barcode: loc.Barcode,
+	}
+}
+
+// shortAuthors lists up to two distinct authors as "Last F. M.".
+func shortAuthors(works []*readmodel.WorkShort) string {
+	seen := make(map[uuid.UUID]bool)
+	var names []string
+	more := false
+	for _, w := range works {
+		for _, a := range w.Authors {
+			if seen[a.ID] {
+				continue
+			}
+			seen[a.ID] = true
+			if len(names) == 2 {
+				more = true
+				continue
+			}
+			names = append(names, shortAuthorName(a))
+		}
+	}
+
+	res := strings.Join(names, ", ")
+	if more {
+		res += " и др."
+	}
+	return res
+}
+
+func shortAuthorName(a readmodel.Author) string {
+	name := a.LastName
+	for _, part := range []*string{a.FirstName, a.MiddleName} {
+		if part == nil {
+			continue
+		}
+		if r := []rune(strings.TrimSpace(*part)); len(r) > 0 {
+			name += " " + string(r[0]) + "."
+		}
+	}
+	return name
+}
+
+func truncateLabelLine(s string) string {
+	r := []rune(strings.TrimSpace(s))
+	if len(r) <= labelLineMax {
+		return string(r)
+	}
+	return strings.TrimSpace(string(r[:labelLineMax-3])) + "..."
+}
+
+func containsLocationType(types []domain.LocationType, t domain.LocationType) bool {
+	for _, v := range types {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"elibrary/internal/domain"
+	"elibrary/internal/readmodel"
+	"elibrary/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type stubLocationRepo struct {
+	repository.LocationRepository
+	byID    map[uuid.UUID]*domain.Location
+	subtree []*domain.Location
+}
+
+func (s stubLocationRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Location, error) {
+	loc, ok := s.byID[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return loc, nil
+}
+func (s stubLocationRepo) GetSubtree(ctx context.Context, id uuid.UUID) ([]*domain.Location, error) {
+	if len(s.subtree) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return s.subtree, nil
+}
+func (s stubLocationRepo) GetByBarcode(ctx context.Context, barcode string) (*domain.Location, error) {
+	for _, loc := range s.byID {
+		if loc.Barcode == barcode {
+			return loc, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestBookLabel(t *testing.T) {
+	t.Parallel()
+
+	tolstoy := readmodel.Author{ID: uuid.New(), LastName: "Толстой", FirstName: strPtr("Лев"), MiddleName: strPtr("Николаевич")}
+	book := &readmodel.BookInternal{
+		Title:   "Война и мир",
+		Barcode: "2000000000015",
+		Works: []*readmodel.WorkShort{
+			{Authors: []readmodel.Author{tolstoy}},
+			{Authors: []readmodel.Author{tolstoy, {ID: uuid.New(), LastName: "Чехов"}, {ID: uuid.New(), LastName: "Пушкин"}}},
+		},
+		Location: &readmodel.Location{CabinetName: "Шкаф 2", ShelfName: "Полка 3"},
+	}
+
+	got := BookLabel(book)
+	want := PrintTask{Str1: "Война и мир", Str2: "Толстой Л. Н., Чехов и др., Шкаф 2 / Полка 3", Barcode: "2000000000015"}
+	want.Str2 = truncateLabelLine(want.Str2)
+	if got != want {
+		t.Fatalf("BookLabel() = %+v, want %+v", got, want)
+	}
+	if n := len([]rune(got.Str2)); n > labelLineMax || !strings.HasSuffix(got.Str2, "...") {
+		t.Fatalf("Str2 = %q (%d chars), want truncated to %d", got.Str2, n, labelLineMax)
+	}
+}
+
+func TestBookSelectionFilter(t *testing.T) {
+	t.Parallel()
+
+	shelf := uuid.New()
+	tests := []struct {
+		name    string
+		sel     BookSelection
+		wantErr error
+	}{
+		{name: "ids", sel: BookSelection{BookIDs: []uuid.UUID{uuid.New()}}},
+		{name: "location", sel: BookSelection{LocationID: &shelf}},
+		{name: "filter", sel: BookSelection{Filter: &BookFilterInput{Query: strPtr("history")}}},
+		{name: "empty", sel: BookSelection{}, wantErr: ErrInvalidSelection},
+		{name: "ambiguous", sel: BookSelection{LocationID: &shelf, Filter: &BookFilterInput{}}, wantErr: ErrInvalidSelection},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			filter, err := tt.sel.bookFilter()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("bookFilter() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && filter.LimitOr(0) != MaxLabelBatch+1 {
+				t.Fatalf("limit = %d, want %d", filter.LimitOr(0), MaxLabelBatch+1)
+			}
+		})
+	}
+}
+
+func TestLabelServiceLocationTasks(t *testing.T) {
+	t.Parallel()
+
+	building := &domain.Location{ID: uuid.New(), Type: domain.LocationTypeBuilding, Name: "Main", Barcode: "2100000000011"}
+	room := &domain.Location{ID: uuid.New(), ParentID: &building.ID, Type: domain.LocationTypeRoom, Name: "Room 1", Barcode: "2100000000028"}
+	cabinet := &domain.Location{ID: uuid.New(), ParentID: &room.ID, Type: domain.LocationTypeCabinet, Name: "Cabinet A", Barcode: "2100000000035"}
+	shelf := &domain.Location{ID: uuid.New(), ParentID: &cabinet.ID, Type: domain.LocationTypeShelf, Name: "Shelf 1", Barcode: "2100000000042"}
+
+	svc := NewLabelService(nil, stubLocationRepo{
+		byID:    map[uuid.UUID]*domain.Location{building.ID: building},
+		subtree: []*domain.Location{room, cabinet, shelf},
+	}, nil, NewBarcodeService(nil))
+
+	tasks, err := svc.LocationTasks(context.Background(), LocationSelection{LocationID: room.ID})
+	if err != nil {
+		t.Fatalf("LocationTasks() error = %v", err)
+	}
+	if len(tasks) != 3 {
+		t.Fatalf("LocationTasks() = %d tasks, want 3", len(tasks))
+	}
+	if got := tasks[2]; got.Str1 != "Shelf 1" || got.Str2 != "Main / Room 1 / Cabinet A" || got.Barcode != shelf.Barcode {
+		t.Fatalf("shelf label = %+v, want full path from the building", got)
+	}
+
+	tasks, err = svc.LocationTasks(context.Background(), LocationSelection{LocationID: room.ID, Types: []domain.LocationType{domain.LocationTypeShelf}})
+	if err != nil || len(tasks) != 1 || tasks[0].Barcode != shelf.Barcode {
+		t.Fatalf("LocationTasks(shelves) = %+v, %v, want only the shelf", tasks, err)
+	}
+
+	if _, err := svc.LocationTasks(context.Background(), LocationSelection{LocationID: room.ID, Types: []domain.LocationType{"attic"}}); !errors.Is(err, domain.ErrInvalidLocationType) {
+		t.Fatalf("LocationTasks() error = %v, want %v", err, domain.ErrInvalidLocationType)
+	}
+	if _, err := svc.LocationTasks(context.Background(), LocationSelection{LocationID: room.ID, Symbology: "pdf417"}); !errors.Is(err, domain.ErrInvalidSymbology) {
+		t.Fatalf("LocationTasks() error = %v, want %v", err, domain.ErrInvalidSymbology)
+	}
+}
+
+func TestBarcodeServiceApplySymbology(t *testing.T) {
+	t.Parallel()
+
+	svc := NewBarcodeService(nil)
+	svc.Symbologies = Symbologies{
+		Location:     domain.SymbologyQR,
+		LocationLink: "https://library.example/shelves/{id}?code={barcode}",
+	}
+	id := uuid.New()
+
+	task := PrintTask{Barcode: "2100000000042"}
+	if err := svc.ApplySymbology(&task, domain.BarcodeTypeLocation, id, ""); err != nil {
+		t.Fatalf("ApplySymbology() error = %v", err)
+	}
+	if task.Symbology != domain.SymbologyQR || task.SymbolData() != "https://library.example/shelves/"+id.String()+"?code=2100000000042" {
+		t.Fatalf("location task = %+v, want a QR code with the shelf link", task)
+	}
+
+	task = PrintTask{Barcode: "2000000000015"}
+	if err := svc.ApplySymbology(&task, domain.BarcodeTypeBook, id, ""); err != nil {
+		t.Fatalf("ApplySymbology() error = %v", err)
+	}
+	if task.Symbology != domain.SymbologyEAN13 || task.SymbolData() != "2000000000015" {
+		t.Fatalf("book task = %+v, want EAN-13 of the barcode", task)
+	}
+
+	task = PrintTask{Barcode: "2100000000042"}
+	if err := svc.ApplySymbology(&task, domain.BarcodeTypeLocation, id, domain.SymbologyCode128); err != nil {
+		t.Fatalf("ApplySymbology() error = %v", err)
+	}
+	if task.Symbology != domain.SymbologyCode128 || task.Data != "" {
+		t.Fatalf("overridden task = %+v, want Code128 of the barcode", task)
+	}
+}
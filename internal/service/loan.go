@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBorrowerNotFound = errors.New("borrower not found")
+	ErrInvalidDueDate   = errors.New("due date must be in the future")
+)
+
+type LoanService struct {
+	loanRepo repository.LoanRepository
+	userRepo repository.UserRepository
+}
+
+func NewLoanService(loanRepo repository.LoanRepository, userRepo repository.UserRepository) *LoanService {
+	return &LoanService{
+		loanRepo: loanRepo,
+		userRepo: userRepo,
+	}
+}
+
+type CheckoutRequest struct {
+	BookID uuid.UUID  `json:"book_id"`
+	UserID uuid.UUID  `json:"user_id"`
+	DueAt  *time.Time `json:"due_at,omitempty"`
+	Note   *string    `json:"note,omitempty"`
+}
+
+func (s *LoanService) Checkout(ctx context.Context, req CheckoutRequest, issuedBy *uuid.UUID) (*domain.Loan, error) {
+	if req.BookID == uuid.Nil || req.UserID == uuid.Nil {
+		return nil, domain.ErrInvalidInput
+	}
+
+	now := time.Now()
+	if req.DueAt != nil && !req.DueAt.After(now) {
+		return nil, ErrInvalidDueDate
+	}
+
+	borrower, err := s.userRepo.GetByID(ctx, req.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrBorrowerNotFound
+		}
+		return nil, err
+	}
+	if !borrower.IsActive {
+		return nil, ErrBorrowerNotFound
+	}
+
+	loan := domain.Loan{
+		ID:       uuid.New(),
+		BookID:   req.BookID,
+		UserID:   req.UserID,
+		IssuedAt: now,
+		DueAt:    req.DueAt,
+		IssuedBy: issuedBy,
+		Note:     req.Note,
+	}
+
+	if err := s.loanRepo.Create(ctx, loan); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &loan, nil
+}
+
+func (s *LoanService) Return(ctx context.Context, id uuid.UUID, returnedBy *uuid.UUID) (*domain.Loan, error) {
+	loan, err := s.loanRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	if !loan.IsActive() {
+		return nil, domain.ErrLoanReturned
+	}
+
+	now := time.Now()
+	if err := s.loanRepo.Return(ctx, id, now, returnedBy); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrLoanReturned
+		}
+		return nil, err
+	}
+
+	loan.ReturnedAt = &now
+	loan.ReturnedBy = returnedBy
+
+	return loan, nil
+}
+
+func (s *LoanService) GetActiveByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Loan, error) {
+	loans, err := s.loanRepo.GetActiveByUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return loans, nil
+}
+
+func (s *LoanService) GetActiveByBook(ctx context.Context, bookID uuid.UUID) ([]*domain.Loan, error) {
+	loans, err := s.loanRepo.GetActiveByBook(ctx, bookID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return loans, nil
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type stubLoanRepo struct {
+	create  func(ctx context.Context, loan domain.Loan) error
+	ret     func(ctx context.Context, id uuid.UUID, returnedAt time.Time, returnedBy *uuid.UUID) error
+	getByID func(ctx context.Context, id uuid.UUID) (*domain.Loan, error)
+}
+
+func (s stubLoanRepo) Create(ctx context.Context, loan domain.Loan) error {
+	return s.create(ctx, loan)
+}
+func (s stubLoanRepo) Return(ctx context.Context, id uuid.UUID, returnedAt time.Time, returnedBy *uuid.UUID) error {
+	return s.ret(ctx, id, returnedAt, returnedBy)
+}
+func (s stubLoanRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Loan, error) {
+	return s.getByID(ctx, id)
+}
+func (s stubLoanRepo) GetActiveByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Loan, error) {
+	return nil, repository.ErrNotFound
+}
+func (s stubLoanRepo) GetActiveByBook(ctx context.Context, bookID uuid.UUID) ([]*domain.Loan, error) {
+	return nil, repository.ErrNotFound
+}
+
+var _ repository.LoanRepository = stubLoanRepo{}
+
+func activeUserRepo() stubAuthUserRepo {
+	return stubAuthUserRepo{
+		getByID: func(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+			return &domain.User{ID: id, IsActive: true}, nil
+		},
+	}
+}
+
+func TestLoanServiceCheckout(t *testing.T) {
+	t.Parallel()
+
+	bookID, userID, staffID := uuid.New(), uuid.New(), uuid.New()
+	var created domain.Loan
+	svc := NewLoanService(stubLoanRepo{
+		create: func(ctx context.Context, loan domain.Loan) error {
+			created = loan
+			return nil
+		},
+	}, activeUserRepo())
+
+	loan, err := svc.Checkout(context.Background(), CheckoutRequest{BookID: bookID, UserID: userID}, &staffID)
+	if err != nil {
+		t.Fatalf("Checkout() error = %v", err)
+	}
+	if loan.ID == uuid.Nil || created.ID != loan.ID {
+		t.Fatalf("Checkout() loan id = %v, stored %v", loan.ID, created.ID)
+	}
+	if created.BookID != bookID || created.UserID != userID {
+		t.Fatalf("stored loan = %+v, want book %v user %v", created, bookID, userID)
+	}
+	if created.IssuedBy == nil || *created.IssuedBy != staffID {
+		t.Fatalf("IssuedBy = %v, want %v", created.IssuedBy, staffID)
+	}
+}
+
+func TestLoanServiceCheckoutRejectsInvalidRequests(t *testing.T) {
+	t.Parallel()
+
+	past := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name  string
+		req   CheckoutRequest
+		users stubAuthUserRepo
+		repo  stubLoanRepo
+		want  error
+	}{
+		{
+			name:  "missing ids",
+			req:   CheckoutRequest{},
+			users: activeUserRepo(),
+			want:  domain.ErrInvalidInput,
+		},
+		{
+			name:  "due date in the past",
+			req:   CheckoutRequest{BookID: uuid.New(), UserID: uuid.New(), DueAt: &past},
+			users: activeUserRepo(),
+			want:  ErrInvalidDueDate,
+		},
+		{
+			name: "inactive borrower",
+			req:  CheckoutRequest{BookID: uuid.New(), UserID: uuid.New()},
+			users: stubAuthUserRepo{
+				getByID: func(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+					return &domain.User{ID: id, IsActive: false}, nil
+				},
+			},
+			want: ErrBorrowerNotFound,
+		},
+		{
+			name:  "book already on loan",
+			req:   CheckoutRequest{BookID: uuid.New(), UserID: uuid.New()},
+			users: activeUserRepo(),
+			repo: stubLoanRepo{
+				create: func(ctx context.Context, loan domain.Loan) error {
+					return domain.ErrBookOnLoan
+				},
+			},
+			want: domain.ErrBookOnLoan,
+		},
+		{
+			name:  "unknown book",
+			req:   CheckoutRequest{BookID: uuid.New(), UserID: uuid.New()},
+			users: activeUserRepo(),
+			repo: stubLoanRepo{
+				create: func(ctx context.Context, loan domain.Loan) error {
+					return repository.ErrNotFound
+				},
+			},
+			want: domain.ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			svc := NewLoanService(tt.repo, tt.users)
+			_, err := svc.Checkout(context.Background(), tt.req, nil)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Checkout() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoanServiceReturn(t *testing.T) {
+	t.Parallel()
+
+	loanID, staffID := uuid.New(), uuid.New()
+	svc := NewLoanService(stubLoanRepo{
+		getByID: func(ctx context.Context, id uuid.UUID) (*domain.Loan, error) {
+			return &domain.Loan{ID: id}, nil
+		},
+		ret: func(ctx context.Context, id uuid.UUID, returnedAt time.Time, returnedBy *uuid.UUID) error {
+			if id != loanID {
+				t.Fatalf("Return() id = %v, want %v", id, loanID)
+			}
+			return nil
+		},
+	}, activeUserRepo())
+
+	loan, err := svc.Return(context.Background(), loanID, &staffID)
+	if err != nil {
+		t.Fatalf("Return() error = %v", err)
+	}
+	if loan.IsActive() {
+		t.Fatal("Return() loan is still active")
+	}
+	if loan.ReturnedBy == nil || *loan.ReturnedBy != staffID {
+		t.Fatalf("ReturnedBy = %v, want %v", loan.ReturnedBy, staffID)
+	}
+}
+
+func TestLoanServiceReturnRejectsReturnedLoan(t *testing.T) {
+	t.Parallel()
+
+	returnedAt := time.Now()
+	svc := NewLoanService(stubLoanRepo{
+		getByID: func(ctx context.Context, id uuid.UUID) (*domain.Loan, error) {
+			return &domain.Loan{ID: id, ReturnedAt: &returnedAt}, nil
+		},
+	}, activeUserRepo())
+
+	_, err := svc.Return(context.Background(), uuid.New(), nil)
+	if !errors.Is(err, domain.ErrLoanReturned) {
+		t.Fatalf("Return() error = %v, want %v", err, domain.ErrLoanReturned)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"errors"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -127,6 +128,15 @@ func (s *LocationService) GetByID(ctx context.Context, id uuid.UUID) (*domain.Lo
 	return location, nil
 }
 
+// BarcodeImage renders the barcode of a location.
+func (s *LocationService) BarcodeImage(ctx context.Context, id uuid.UUID, opts BarcodeImageOptions) (*BarcodeImage, error) {
+	location, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.BarcodeSvc.RenderBarcode(location.Barcode, opts)
+}
+
 func (s *LocationService) GetByType(ctx context.Context, locType domain.LocationType) ([]*domain.Location, error) {
 	locations, err := s.locRepo.GetByType(ctx, locType)
 	if err != nil {
@@ -179,6 +189,54 @@ func (s *LocationService) GetByBarcode(ctx context.Context, barcode string) (*do
 	return location, nil
 }
 
+type RelabelLocationRequest struct {
+	Reason *string `json:"reason,omitempty"`
+}
+
+// Relabel gives a location a new barcode. The old code goes to the barcode
+// history and keeps resolving to the location.
+func (s *LocationService) Relabel(ctx context.Context, id uuid.UUID, req RelabelLocationRequest, actorID *uuid.UUID) (*domain.Location, error) {
+	location, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ean13, err := s.BarcodeSvc.GenerateEAN13(ctx, domain.BarcodeTypeLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.locRepo.ReplaceBarcode(ctx, domain.ReplacedBarcode{
+		Barcode:    location.Barcode,
+		LocationID: &location.ID,
+		ReplacedBy: ean13,
+		Reason:     req.Reason,
+		CreatedBy:  actorID,
+		ReplacedAt: time.Now(),
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	location.Barcode = ean13
+	return location, nil
+}
+
+// BarcodeHistory lists the codes the location carried before, newest first.
+func (s *LocationService) BarcodeHistory(ctx context.Context, id uuid.UUID) ([]*domain.ReplacedBarcode, error) {
+	history, err := s.locRepo.GetReplacedBarcodes(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return history, nil
+}
+
 func (s *LocationService) Delete(ctx context.Context, id uuid.UUID) error {
 	hasChildren, err := s.locRepo.HasChildren(ctx, id)
 	if err != nil {
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// memLocationRepo keeps locations and their replaced barcodes in memory.
+// ReplaceBarcode follows the SQL: the old code must still be current and the
+// new one must not belong to another location.
+type memLocationRepo struct {
+	repository.LocationRepository
+	byID     map[uuid.UUID]*domain.Location
+	replaced []domain.ReplacedBarcode
+}
+
+func (r *memLocationRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.Location, error) {
+	loc, ok := r.byID[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *loc
+	return &copied, nil
+}
+
+func (r *memLocationRepo) ReplaceBarcode(ctx context.Context, entry domain.ReplacedBarcode) error {
+	for _, loc := range r.byID {
+		if loc.ID != *entry.LocationID && loc.Barcode == entry.ReplacedBy {
+			return domain.ErrBarcodeExists
+		}
+	}
+	loc, ok := r.byID[*entry.LocationID]
+	if !ok || loc.Barcode != entry.Barcode {
+		return repository.ErrNotFound
+	}
+	loc.Barcode = entry.ReplacedBy
+	r.replaced = append(r.replaced, entry)
+	return nil
+}
+
+func (r *memLocationRepo) GetReplacedBarcodes(ctx context.Context, id uuid.UUID) ([]*domain.ReplacedBarcode, error) {
+	var res []*domain.ReplacedBarcode
+	for i := len(r.replaced) - 1; i >= 0; i-- {
+		if e := r.replaced[i]; *e.LocationID == id {
+			res = append(res, &e)
+		}
+	}
+	if len(res) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return res, nil
+}
+
+// locationSequence hands out location codes 2100000000012, 2100000000029...
+func locationSequence(next *int64) stubSequenceRepo {
+	return stubSequenceRepo{
+		getNext: func(ctx context.Context, t domain.BarcodeType) (int64, int, error) {
+			*next++
+			return *next, 210, nil
+		},
+	}
+}
+
+func TestLocationServiceRelabel(t *testing.T) {
+	t.Parallel()
+
+	hall := &domain.Location{ID: uuid.New(), Name: "Зал", Barcode: "2000000000015"}
+	repo := &memLocationRepo{byID: map[uuid.UUID]*domain.Location{hall.ID: hall}}
+	var next int64
+	svc := NewLocationService(repo, NewBarcodeService(locationSequence(&next)))
+	ctx := context.Background()
+
+	if _, err := svc.BarcodeHistory(ctx, hall.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("BarcodeHistory() before relabel error = %v, want %v", err, domain.ErrNotFound)
+	}
+
+	actor := uuid.New()
+	reason := "этикетка повреждена"
+	got, err := svc.Relabel(ctx, hall.ID, RelabelLocationRequest{Reason: &reason}, &actor)
+	if err != nil {
+		t.Fatalf("Relabel() error = %v", err)
+	}
+	if got.Barcode != "2100000000012" || hall.Barcode != got.Barcode {
+		t.Fatalf("Relabel() barcode = %q, stored %q, want %q", got.Barcode, hall.Barcode, "2100000000012")
+	}
+
+	history, err := svc.BarcodeHistory(ctx, hall.ID)
+	if err != nil {
+		t.Fatalf("BarcodeHistory() error = %v", err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("BarcodeHistory() = %d entries, want 1", len(history))
+	}
+	e := history[0]
+	if e.Barcode != "2000000000015" || e.ReplacedBy != got.Barcode || *e.Reason != reason || *e.CreatedBy != actor {
+		t.Fatalf("history entry = %+v, want the old code replaced by %q", e, got.Barcode)
+	}
+
+	if _, err := svc.Relabel(ctx, uuid.New(), RelabelLocationRequest{}, nil); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("Relabel() of an unknown location error = %v, want %v", err, domain.ErrNotFound)
+	}
+}
+
+func TestLocationServiceRelabelRejectsCodeInUse(t *testing.T) {
+	t.Parallel()
+
+	hall := &domain.Location{ID: uuid.New(), Name: "Зал", Barcode: "2000000000015"}
+	shelf := &domain.Location{ID: uuid.New(), Name: "Полка", Barcode: "2100000000012"}
+	repo := &memLocationRepo{byID: map[uuid.UUID]*domain.Location{hall.ID: hall, shelf.ID: shelf}}
+	var next int64
+	svc := NewLocationService(repo, NewBarcodeService(locationSequence(&next)))
+
+	_, err := svc.Relabel(context.Background(), hall.ID, RelabelLocationRequest{}, nil)
+	if !errors.Is(err, domain.ErrBarcodeExists) {
+		t.Fatalf("Relabel() error = %v, want %v", err, domain.ErrBarcodeExists)
+	}
+	if hall.Barcode != "2000000000015" {
+		t.Fatalf("barcode = %q, want the old code kept", hall.Barcode)
+	}
+	if len(repo.replaced) != 0 {
+		t.Fatalf("history = %v, want none", repo.replaced)
+	}
+}
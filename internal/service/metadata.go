@@ -0,0 +1,440 @@
+package service
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"elibrary/internal/readmodel"
+	"elibrary/internal/repository"
+	"errors"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrMetadataUnavailable = errors.New("metadata providers unavailable")
+	ErrMetadataDisabled    = errors.New("metadata lookup is disabled")
+)
+
+// BookMetadata is what an external catalog knows about an edition.
+type BookMetadata struct {
+	Title       string
+	Subtitle    string
+	Authors     []string
+	Publisher   string
+	Year        *int
+	Description string
+	Pages       *int
+	Language    string
+	CoverURL    string
+}
+
+// MetadataProvider looks up an edition by ISBN in an external catalog.
+type MetadataProvider interface {
+	// Name identifies the provider in drafts and logs.
+	Name() string
+	// LookupISBN returns domain.ErrNotFound when the catalog does not know
+	// the ISBN.
+	LookupISBN(ctx context.Context, isbn13 string) (*BookMetadata, error)
+}
+
+// BookDraft is a prefilled book for the cataloging form. Its fields follow
+// POST /admin/books; authors and the publisher come with the existing
+// records they were matched to.
+type BookDraft struct {
+	Sources []string `json:"sources"`
+
+	Title          string         `json:"title"`
+	ISBN           string         `json:"isbn"`
+	FactoryBarcode string         `json:"factory_barcode"`
+	PublisherID    *uuid.UUID     `json:"publisher_id,omitempty"`
+	Year           *int           `json:"year,omitempty"`
+	Description    *string        `json:"description,omitempty"`
+	Extra          map[string]any `json:"extra,omitempty"`
+
+	Authors   []AuthorMatch   `json:"authors,omitempty"`
+	Publisher *PublisherMatch `json:"publisher,omitempty"`
+
+	// Duplicates are the books already catalogued with this ISBN.
+	Duplicates []*readmodel.BookPublic `json:"duplicates,omitempty"`
+}
+
+// AuthorMatch is an author name as given by the provider, split for a new
+// author record. Author is set when exactly one existing author fits;
+// Candidates when several do.
+type AuthorMatch struct {
+	Name       string             `json:"name"`
+	LastName   string             `json:"last_name"`
+	FirstName  *string            `json:"first_name,omitempty"`
+	MiddleName *string            `json:"middle_name,omitempty"`
+	Author     *readmodel.Author  `json:"author,omitempty"`
+	Candidates []readmodel.Author `json:"candidates,omitempty"`
+}
+
+// PublisherMatch is a publisher name as given by the provider and the
+// existing publisher of that name, if any.
+type PublisherMatch struct {
+	Name      string               `json:"name"`
+	Publisher *readmodel.Publisher `json:"publisher,omitempty"`
+}
+
+// MetadataService prefills new books from external catalogs.
+type MetadataService struct {
+	providers  []MetadataProvider
+	books      *BookService
+	authors    repository.AuthorRepository
+	publishers repository.PublisherRepository
+}
+
+func NewMetadataService(
+	providers []MetadataProvider,
+	books *BookService,
+	authors repository.AuthorRepository,
+	publishers repository.PublisherRepository,
+) *MetadataService {
+	return &MetadataService{
+		providers:  providers,
+		books:      books,
+		authors:    authors,
+		publishers: publishers,
+	}
+}
+
+// Lookup asks the providers in order. Later providers only fill in what the
+// earlier ones did not know, so the first configured provider wins. Without
+// providers it returns ErrMetadataDisabled.
+func (s *MetadataService) Lookup(ctx context.Context, rawISBN string) (*BookDraft, error) {
+	if len(s.providers) == 0 {
+		return nil, ErrMetadataDisabled
+	}
+	isbn, err := domain.ParseISBN(rawISBN)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		meta    BookMetadata
+		sources []string
+		failed  int
+	)
+	for _, p := range s.providers {
+		found, err := p.LookupISBN(ctx, isbn)
+		if err != nil {
+			if !errors.Is(err, domain.ErrNotFound) {
+				log.Printf("metadata provider %s failed for %s: %v", p.Name(), isbn, err)
+				failed++
+			}
+			continue
+		}
+		mergeMetadata(&meta, found)
+		sources = append(sources, p.Name())
+		if metadataComplete(meta) {
+			break
+		}
+	}
+	if len(sources) == 0 {
+		if failed > 0 {
+			return nil, ErrMetadataUnavailable
+		}
+		return nil, domain.ErrNotFound
+	}
+
+	return s.draft(ctx, isbn, meta, sources)
+}
+
+func (s *MetadataService) draft(ctx context.Context, isbn string, meta BookMetadata, sources []string) (*BookDraft, error) {
+	draft := &BookDraft{
+		Sources:        sources,
+		Title:          meta.Title,
+		ISBN:           isbn,
+		FactoryBarcode: isbn,
+		Year:           meta.Year,
+		Extra:          make(map[string]any),
+	}
+	if meta.Subtitle != "" {
+		draft.Title += ": " + meta.Subtitle
+	}
+	if meta.Description != "" {
+		draft.Description = &meta.Description
+	}
+	if meta.Pages != nil {
+		draft.Extra["pages"] = *meta.Pages
+	}
+	if meta.Language != "" {
+		draft.Extra["language"] = meta.Language
+	}
+	if meta.CoverURL != "" {
+		draft.Extra["cover_url"] = meta.CoverURL
+	}
+
+	for _, name := range meta.Authors {
+		match, err := s.matchAuthor(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		draft.Authors = append(draft.Authors, *match)
+	}
+
+	if meta.Publisher != "" {
+		match, err := s.matchPublisher(ctx, meta.Publisher)
+		if err != nil {
+			return nil, err
+		}
+		draft.Publisher = match
+		if match.Publisher != nil {
+			draft.PublisherID = &match.Publisher.ID
+		}
+	}
+
+	duplicates, err := s.books.findDuplicates(ctx, domain.Book{ISBN: &isbn})
+	if err != nil {
+		return nil, err
+	}
+	draft.Duplicates = duplicates
+
+	return draft, nil
+}
+
+// matchAuthor looks the name up by each of its words as a last name, since
+// catalogs write both "Лев Толстой" and "Толстой, Лев Николаевич". An
+// existing author fits when its last name is one of the words and its first
+// and middle names, where set, agree with the others in full or as initials.
+func (s *MetadataService) matchAuthor(ctx context.Context, name string) (*AuthorMatch, error) {
+	last, first, middle := splitAuthorName(name)
+	match := &AuthorMatch{Name: name, LastName: last}
+	if first != "" {
+		match.FirstName = &first
+	}
+	if middle != "" {
+		match.MiddleName = &middle
+	}
+
+	tokens := nameTokens(name)
+	var words []string
+	for _, t := range tokens {
+		if !isInitial(t) {
+			words = append(words, t)
+		}
+	}
+	if len(words) == 0 {
+		return match, nil
+	}
+	candidates, err := s.authors.FindByLastNames(ctx, words)
+	if err != nil {
+		return nil, err
+	}
+
+	best := 0
+	for _, c := range candidates {
+		score := authorScore(c, tokens)
+		switch {
+		case score > best:
+			best = score
+			match.Candidates = []readmodel.Author{c}
+		case score == best && score > 0:
+			match.Candidates = append(match.Candidates, c)
+		}
+	}
+	if len(match.Candidates) == 1 {
+		match.Author = &match.Candidates[0]
+		match.Candidates = nil
+	}
+	return match, nil
+}
+
+// authorScore is zero when the author does not fit the name, otherwise one
+// plus the number of first and middle names that agree.
+func authorScore(a readmodel.Author, tokens []string) int {
+	rest := make([]string, 0, len(tokens))
+	found := false
+	for _, w := range tokens {
+		if !found && strings.EqualFold(w, a.LastName) {
+			found = true
+			continue
+		}
+		rest = append(rest, w)
+	}
+	if !found {
+		return 0
+	}
+
+	score := 1
+	for _, part := range []*string{a.FirstName, a.MiddleName} {
+		if part == nil || *part == "" {
+			continue
+		}
+		matched := false
+		for i, w := range rest {
+			if nameAgrees(*part, w) {
+				rest = append(rest[:i], rest[i+1:]...)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			if len(rest) > 0 {
+				return 0
+			}
+			continue
+		}
+		score++
+	}
+	return score
+}
+
+// nameAgrees compares a stored name with a word that may be an initial.
+func nameAgrees(name, word string) bool {
+	if strings.EqualFold(name, word) {
+		return true
+	}
+	if !isInitial(word) {
+		return false
+	}
+	w, n := []rune(word), []rune(name)
+	return strings.EqualFold(string(w[0]), string(n[0]))
+}
+
+func (s *MetadataService) matchPublisher(ctx context.Context, name string) (*PublisherMatch, error) {
+	match := &PublisherMatch{Name: name}
+
+	found, err := s.publishers.FindByNames(ctx, publisherNameVariants(name))
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 1 {
+		match.Publisher = &found[0]
+	}
+	return match, nil
+}
+
+// publisherLegalForms are dropped when matching publisher names: providers
+// write "ООО «Издательство АСТ»" where the catalogue has "АСТ".
+var publisherLegalForms = []string{"ооо", "оао", "зао", "ао", "пао", "ип", "издательство", "изд-во", "издательский дом"}
+
+func publisherNameVariants(name string) []string {
+	plain := strings.Join(strings.Fields(strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`«»"“”„'`, r) {
+			return ' '
+		}
+		return r
+	}, name)), " ")
+
+	variants := []string{strings.TrimSpace(name), plain}
+	short := plain
+	for changed := true; changed; {
+		changed = false
+		for _, form := range publisherLegalForms {
+			if len(short) > len(form) && strings.EqualFold(short[:len(form)], form) && short[len(form)] == ' ' {
+				short = strings.TrimSpace(short[len(form):])
+				changed = true
+			}
+		}
+	}
+	if short != plain && short != "" {
+		variants = append(variants, short)
+	}
+	return variants
+}
+
+// splitAuthorName reads "Last, First Middle", "First Middle Last" and the
+// Russian "Last First Patronymic".
+func splitAuthorName(name string) (last, first, middle string) {
+	if before, after, ok := strings.Cut(name, ","); ok {
+		last = strings.TrimSpace(before)
+		rest := strings.Fields(after)
+		if len(rest) > 0 {
+			first = rest[0]
+			middle = strings.Join(rest[1:], " ")
+		}
+		return last, first, middle
+	}
+
+	words := strings.Fields(name)
+	switch {
+	case len(words) == 0:
+		return "", "", ""
+	case len(words) == 1:
+		return words[0], "", ""
+	case len(words) == 3 && isPatronymic(words[2]):
+		return words[0], words[1], words[2]
+	}
+	return words[len(words)-1], words[0], strings.Join(words[1:len(words)-1], " ")
+}
+
+func isPatronymic(word string) bool {
+	w := strings.ToLower(word)
+	for _, suffix := range []string{"ич", "вна", "чна"} {
+		if strings.HasSuffix(w, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// nameTokens splits a name into words, splitting run-together initials
+// such as "Л.Н." as well.
+func nameTokens(name string) []string {
+	var tokens []string
+	for _, w := range strings.FieldsFunc(name, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	}) {
+		for _, part := range strings.SplitAfter(w, ".") {
+			if part != "" {
+				tokens = append(tokens, part)
+			}
+		}
+	}
+	return tokens
+}
+
+func isInitial(word string) bool {
+	return len([]rune(strings.TrimSuffix(word, "."))) == 1
+}
+
+func mergeMetadata(dst *BookMetadata, src *BookMetadata) {
+	if dst.Title == "" {
+		dst.Title, dst.Subtitle = src.Title, src.Subtitle
+	}
+	if len(dst.Authors) == 0 {
+		dst.Authors = src.Authors
+	}
+	if dst.Publisher == "" {
+		dst.Publisher = src.Publisher
+	}
+	if dst.Year == nil {
+		dst.Year = src.Year
+	}
+	if dst.Description == "" {
+		dst.Description = src.Description
+	}
+	if dst.Pages == nil {
+		dst.Pages = src.Pages
+	}
+	if dst.Language == "" {
+		dst.Language = src.Language
+	}
+	if dst.CoverURL == "" {
+		dst.CoverURL = src.CoverURL
+	}
+}
+
+func metadataComplete(m BookMetadata) bool {
+	return m.Title != "" && len(m.Authors) > 0 && m.Publisher != "" && m.Year != nil && m.Description != ""
+}
+
+var yearPattern = regexp.MustCompile(`\b(1[5-9]|20)\d\d\b`)
+
+// parseYear finds the year in dates such as "2016", "2016-05-01" or
+// "May 2016".
+func parseYear(date string) *int {
+	m := yearPattern.FindString(date)
+	if m == "" {
+		return nil
+	}
+	year, _ := strconv.Atoi(m)
+	return &year
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxMetadataResponse bounds what is read from a provider.
+const maxMetadataResponse = 2 << 20
+
+// OpenLibraryProvider uses the Books API of Open Library,
+// {base}/api/books?bibkeys=ISBN:...&jscmd=data.
+type OpenLibraryProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewOpenLibraryProvider(baseURL string, client *http.Client) *OpenLibraryProvider {
+	return &OpenLibraryProvider{baseURL: strings.TrimRight(baseURL, "/"), client: client}
+}
+
+func (p *OpenLibraryProvider) Name() string {
+	return "openlibrary"
+}
+
+type openLibraryBook struct {
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle"`
+	Authors  []struct {
+		Name string `json:"name"`
+	} `json:"authors"`
+	Publishers []struct {
+		Name string `json:"name"`
+	} `json:"publishers"`
+	PublishDate   string `json:"publish_date"`
+	NumberOfPages *int   `json:"number_of_pages"`
+	Cover         struct {
+		Medium string `json:"medium"`
+		Large  string `json:"large"`
+	} `json:"cover"`
+}
+
+func (p *OpenLibraryProvider) LookupISBN(ctx context.Context, isbn13 string) (*BookMetadata, error) {
+	q := url.Values{}
+	q.Set("bibkeys", "ISBN:"+isbn13)
+	q.Set("format", "json")
+	q.Set("jscmd", "data")
+
+	var res map[string]openLibraryBook
+	if err := getMetadataJSON(ctx, p.client, p.baseURL+"/api/books?"+q.Encode(), &res); err != nil {
+		return nil, fmt.Errorf("open library: %w", err)
+	}
+	book, ok := res["ISBN:"+isbn13]
+	if !ok || strings.TrimSpace(book.Title) == "" {
+		return nil, domain.ErrNotFound
+	}
+
+	meta := &BookMetadata{
+		Title:    strings.TrimSpace(book.Title),
+		Subtitle: strings.TrimSpace(book.Subtitle),
+		Year:     parseYear(book.PublishDate),
+		Pages:    book.NumberOfPages,
+		CoverURL: book.Cover.Large,
+	}
+	if meta.CoverURL == "" {
+		meta.CoverURL = book.Cover.Medium
+	}
+	for _, a := range book.Authors {
+		if name := strings.TrimSpace(a.Name); name != "" {
+			meta.Authors = append(meta.Authors, name)
+		}
+	}
+	if len(book.Publishers) > 0 {
+		meta.Publisher = strings.TrimSpace(book.Publishers[0].Name)
+	}
+	return meta, nil
+}
+
+// GoogleBooksProvider uses the volumes search of the Google Books API,
+// {base}/volumes?q=isbn:...
+type GoogleBooksProvider struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+func NewGoogleBooksProvider(baseURL, apiKey string, client *http.Client) *GoogleBooksProvider {
+	return &GoogleBooksProvider{baseURL: strings.TrimRight(baseURL, "/"), apiKey: apiKey, client: client}
+}
+
+func (p *GoogleBooksProvider) Name() string {
+	return "googlebooks"
+}
+
+type googleBooksVolume struct {
+	VolumeInfo struct {
+		Title               string   `json:"title"`
+		Subtitle            string   `json:"subtitle"`
+		Authors             []string `json:"authors"`
+		Publisher           string   `json:"publisher"`
+		PublishedDate       string   `json:"publishedDate"`
+		Description         string   `json:"description"`
+		PageCount           *int     `json:"pageCount"`
+		Language            string   `json:"language"`
+		IndustryIdentifiers []struct {
+			Type       string `json:"type"`
+			Identifier string `json:"identifier"`
+		} `json:"industryIdentifiers"`
+		ImageLinks struct {
+			Thumbnail string `json:"thumbnail"`
+		} `json:"imageLinks"`
+	} `json:"volumeInfo"`
+}
+
+func (p *GoogleBooksProvider) LookupISBN(ctx context.Context, isbn13 string) (*BookMetadata, error) {
+	q := url.Values{}
+	q.Set("q", "isbn:"+isbn13)
+	if p.apiKey != "" {
+		q.Set("key", p.apiKey)
+	}
+
+	var res struct {
+		Items []googleBooksVolume `json:"items"`
+	}
+	if err := getMetadataJSON(ctx, p.client, p.baseURL+"/volumes?"+q.Encode(), &res); err != nil {
+		return nil, fmt.Errorf("google books: %w", err)
+	}
+
+	// The search is full text, so take the first volume that really carries
+	// the ISBN.
+	for _, v := range res.Items {
+		info := v.VolumeInfo
+		matches := false
+		for _, id := range info.IndustryIdentifiers {
+			if n, err := domain.ParseISBN(id.Identifier); err == nil && n == isbn13 {
+				matches = true
+				break
+			}
+		}
+		if !matches || strings.TrimSpace(info.Title) == "" {
+			continue
+		}
+
+		meta := &BookMetadata{
+			Title:       strings.TrimSpace(info.Title),
+			Subtitle:    strings.TrimSpace(info.Subtitle),
+			Publisher:   strings.TrimSpace(info.Publisher),
+			Year:        parseYear(info.PublishedDate),
+			Description: strings.TrimSpace(info.Description),
+			Pages:       info.PageCount,
+			Language:    info.Language,
+			CoverURL:    info.ImageLinks.Thumbnail,
+		}
+		for _, name := range info.Authors {
+			if name = strings.TrimSpace(name); name != "" {
+				meta.Authors = append(meta.Authors, name)
+			}
+		}
+		return meta, nil
+	}
+	return nil, domain.ErrNotFound
+}
+
+func getMetadataJSON(ctx context.Context, client *http.Client, rawURL string, dst any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return domain.ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, maxMetadataResponse)).Decode(dst)
+}
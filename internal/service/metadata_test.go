@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"elibrary/internal/domain"
+	"elibrary/internal/readmodel"
+	"elibrary/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+const (
+	openLibraryStub = `{"ISBN:9785170988853": {
+		"title": "Война и мир",
+		"authors": [{"name": "Лев Толстой"}],
+		"publishers": [{"name": "ООО «Издательство АСТ»"}],
+		"publish_date": "2016",
+		"number_of_pages": 1408,
+		"cover": {"medium": "https://covers.example/m.jpg"}
+	}}`
+	googleBooksStub = `{"totalItems": 2, "items": [
+		{"volumeInfo": {"title": "Другая книга", "industryIdentifiers": [{"type": "ISBN_13", "identifier": "9780804429573"}]}},
+		{"volumeInfo": {
+			"title": "Война и мир",
+			"subtitle": "роман-эпопея",
+			"authors": ["Толстой Лев Николаевич"],
+			"publisher": "АСТ",
+			"publishedDate": "2016-05-01",
+			"description": "Роман о войне 1812 года.",
+			"language": "ru",
+			"industryIdentifiers": [{"type": "ISBN_10", "identifier": "5170988850"}]
+		}}
+	]}`
+)
+
+func newMetadataStub(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/books" && r.URL.Query().Get("bibkeys") == "ISBN:9785170988853":
+			w.Write([]byte(openLibraryStub))
+		case r.URL.Path == "/api/books":
+			w.Write([]byte(`{}`))
+		case r.URL.Path == "/volumes" && r.URL.Query().Get("q") == "isbn:9785170988853":
+			w.Write([]byte(googleBooksStub))
+		case r.URL.Path == "/volumes":
+			w.Write([]byte(`{"totalItems": 0}`))
+		default:
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOpenLibraryProvider(t *testing.T) {
+	t.Parallel()
+
+	srv := newMetadataStub(t)
+	p := NewOpenLibraryProvider(srv.URL+"/", srv.Client())
+
+	got, err := p.LookupISBN(context.Background(), "9785170988853")
+	if err != nil {
+		t.Fatalf("LookupISBN() error = %v", err)
+	}
+	if got.Title != "Война и мир" || got.Publisher != "ООО «Издательство АСТ»" || got.Year == nil || *got.Year != 2016 {
+		t.Fatalf("LookupISBN() = %+v, want title, publisher and year from the stub", got)
+	}
+	if !reflect.DeepEqual(got.Authors, []string{"Лев Толстой"}) || got.Pages == nil || *got.Pages != 1408 {
+		t.Fatalf("LookupISBN() authors = %v, pages = %v", got.Authors, got.Pages)
+	}
+
+	if _, err := p.LookupISBN(context.Background(), "9780804429573"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("LookupISBN(unknown) error = %v, want %v", err, domain.ErrNotFound)
+	}
+}
+
+func TestGoogleBooksProviderSkipsOtherVolumes(t *testing.T) {
+	t.Parallel()
+
+	srv := newMetadataStub(t)
+	p := NewGoogleBooksProvider(srv.URL, "", srv.Client())
+
+	got, err := p.LookupISBN(context.Background(), "9785170988853")
+	if err != nil {
+		t.Fatalf("LookupISBN() error = %v", err)
+	}
+	if got.Title != "Война и мир" || got.Subtitle != "роман-эпопея" || got.Description == "" || got.Language != "ru" {
+		t.Fatalf("LookupISBN() = %+v, want the volume carrying the ISBN", got)
+	}
+
+	if _, err := p.LookupISBN(context.Background(), "9780804429573"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("LookupISBN(unknown) error = %v, want %v", err, domain.ErrNotFound)
+	}
+}
+
+type stubMetadataAuthorRepo struct {
+	repository.AuthorRepository
+	authors []readmodel.Author
+}
+
+func (s stubMetadataAuthorRepo) FindByLastNames(ctx context.Context, lastNames []string) ([]readmodel.Author, error) {
+	var res []readmodel.Author
+	for _, a := range s.authors {
+		for _, name := range lastNames {
+			if strings.EqualFold(a.LastName, name) {
+				res = append(res, a)
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+type stubMetadataPublisherRepo struct {
+	repository.PublisherRepository
+	publishers []readmodel.Publisher
+}
+
+func (s stubMetadataPublisherRepo) FindByNames(ctx context.Context, names []string) ([]readmodel.Publisher, error) {
+	var res []readmodel.Publisher
+	for _, p := range s.publishers {
+		for _, name := range names {
+			if strings.EqualFold(p.Name, name) {
+				res = append(res, p)
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+type failingMetadataProvider struct{}
+
+func (failingMetadataProvider) Name() string { return "broken" }
+
+func (failingMetadataProvider) LookupISBN(ctx context.Context, isbn13 string) (*BookMetadata, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestMetadataServiceLookup(t *testing.T) {
+	t.Parallel()
+
+	srv := newMetadataStub(t)
+	tolstoy := readmodel.Author{ID: uuid.New(), LastName: "Толстой", FirstName: strPtr("Лев"), MiddleName: strPtr("Николаевич")}
+	alexey := readmodel.Author{ID: uuid.New(), LastName: "Толстой", FirstName: strPtr("Алексей")}
+	ast := readmodel.Publisher{ID: uuid.New(), Name: "АСТ"}
+
+	svc := NewMetadataService(
+		[]MetadataProvider{
+			failingMetadataProvider{},
+			NewOpenLibraryProvider(srv.URL, srv.Client()),
+			NewGoogleBooksProvider(srv.URL, "", srv.Client()),
+		},
+		NewBookService(stubDuplicateBookRepo{}, nil, nil, nil, nil, nil),
+		stubMetadataAuthorRepo{authors: []readmodel.Author{alexey, tolstoy}},
+		stubMetadataPublisherRepo{publishers: []readmodel.Publisher{ast}},
+	)
+
+	draft, err := svc.Lookup(context.Background(), "5-17-098885-0")
+	if err != nil {
+		t.Fatalf("Lookup() error = %v", err)
+	}
+	if !reflect.DeepEqual(draft.Sources, []string{"openlibrary", "googlebooks"}) {
+		t.Fatalf("Lookup() sources = %v, want openlibrary and googlebooks", draft.Sources)
+	}
+	if draft.Title != "Война и мир" || draft.ISBN != "9785170988853" || draft.FactoryBarcode != "9785170988853" {
+		t.Fatalf("Lookup() = %q/%q/%q, want the Open Library title and the ISBN-13", draft.Title, draft.ISBN, draft.FactoryBarcode)
+	}
+	if draft.Description == nil || draft.Extra["pages"] != 1408 || draft.Extra["language"] != "ru" {
+		t.Fatalf("Lookup() did not fill in the missing fields from Google Books: %+v", draft)
+	}
+	if len(draft.Authors) != 1 || draft.Authors[0].Author == nil || draft.Authors[0].Author.ID != tolstoy.ID {
+		t.Fatalf("Lookup() authors = %+v, want the existing Лев Толстой", draft.Authors)
+	}
+	if draft.PublisherID == nil || *draft.PublisherID != ast.ID {
+		t.Fatalf("Lookup() publisher_id = %v, want %s", draft.PublisherID, ast.ID)
+	}
+
+	if _, err := svc.Lookup(context.Background(), "978-5-17-098885-4"); !errors.Is(err, domain.ErrInvalidISBN) {
+		t.Fatalf("Lookup(bad check digit) error = %v, want %v", err, domain.ErrInvalidISBN)
+	}
+	if _, err := svc.Lookup(context.Background(), "9780804429573"); !errors.Is(err, ErrMetadataUnavailable) {
+		t.Fatalf("Lookup(unknown, one provider down) error = %v, want %v", err, ErrMetadataUnavailable)
+	}
+
+	healthy := NewMetadataService([]MetadataProvider{NewOpenLibraryProvider(srv.URL, srv.Client())}, nil, nil, nil)
+	if _, err := healthy.Lookup(context.Background(), "9780804429573"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("Lookup(unknown) error = %v, want %v", err, domain.ErrNotFound)
+	}
+
+	disabled := NewMetadataService(nil, nil, nil, nil)
+	if _, err := disabled.Lookup(context.Background(), "9785170988853"); !errors.Is(err, ErrMetadataDisabled) {
+		t.Fatalf("Lookup(no providers) error = %v, want %v", err, ErrMetadataDisabled)
+	}
+}
+
+func TestSplitAuthorName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in                  string
+		last, first, middle string
+	}{
+		{"Толстой, Лев Николаевич", "Толстой", "Лев", "Николаевич"},
+		{"Толстой Лев Николаевич", "Толстой", "Лев", "Николаевич"},
+		{"Лев Николаевич Толстой", "Толстой", "Лев", "Николаевич"},
+		{"J. R. R. Tolkien", "Tolkien", "J.", "R. R."},
+		{"Homer", "Homer", "", ""},
+	}
+
+	for _, tt := range tests {
+		last, first, middle := splitAuthorName(tt.in)
+		if last != tt.last || first != tt.first || middle != tt.middle {
+			t.Fatalf("splitAuthorName(%q) = %q, %q, %q, want %q, %q, %q", tt.in, last, first, middle, tt.last, tt.first, tt.middle)
+		}
+	}
+}
+
+func TestAuthorScore(t *testing.T) {
+	t.Parallel()
+
+	tolstoy := readmodel.Author{LastName: "Толстой", FirstName: strPtr("Лев"), MiddleName: strPtr("Николаевич")}
+
+	tests := []struct {
+		name string
+		want int
+	}{
+		{"Лев Толстой", 2},
+		{"Л.Н. Толстой", 3},
+		{"Толстой", 1},
+		{"Алексей Толстой", 0},
+		{"Лев Гумилёв", 0},
+	}
+
+	for _, tt := range tests {
+		if got := authorScore(tolstoy, nameTokens(tt.name)); got != tt.want {
+			t.Fatalf("authorScore(%q) = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPublisherNameVariants(t *testing.T) {
+	t.Parallel()
+
+	got := publisherNameVariants("ООО «Издательство АСТ»")
+	want := []string{"ООО «Издательство АСТ»", "ООО Издательство АСТ", "АСТ"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("publisherNameVariants() = %q, want %q", got, want)
+	}
+}
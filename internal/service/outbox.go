@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+	"log"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+const (
+	outboxMaxRetryDelay = 5 * time.Minute
+
+	// outboxPublishTimeout bounds the publishing of one batch, so a slow
+	// broker cannot hold claimed messages past outboxClaimLease.
+	outboxPublishTimeout = time.Minute
+	outboxClaimLease     = 2 * outboxPublishTimeout
+)
+
+type outboxPublisher interface {
+	Publish(ctx context.Context, queue string, msg amqp.Publishing) error
+}
+
+// OutboxRelay moves messages from the Postgres outbox to the broker. A
+// message is deleted only after the broker confirms it, so delivery is at
+// least once; consumers must tolerate duplicates. Messages are claimed with
+// a lease rather than row locks, so the outbox stays writable while the
+// relay waits for the broker.
+//
+// The only messages written to the outbox today are print tasks. Domain
+// events such as book changes are recorded in book_events and are not
+// published to the broker.
+type OutboxRelay struct {
+	repo      repository.OutboxRepository
+	publisher outboxPublisher
+	batchSize int
+	interval  time.Duration
+}
+
+func NewOutboxRelay(repo repository.OutboxRepository, publisher outboxPublisher, batchSize int, interval time.Duration) *OutboxRelay {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	return &OutboxRelay{
+		repo:      repo,
+		publisher: publisher,
+		batchSize: batchSize,
+		interval:  interval,
+	}
+}
+
+// Run relays messages until ctx is cancelled. It polls every interval and
+// drains full batches without waiting.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	for {
+		sent, err := r.RelayOnce(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("outbox relay: %v", err)
+		}
+		if err == nil && sent == r.batchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.interval):
+		}
+	}
+}
+
+// RelayOnce publishes one batch of due messages and returns how many were
+// sent. The first publish failure ends the batch: the broker is most likely
+// unavailable and the rest would fail the same way, so they are released
+// for the next pass.
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	msgs, err := r.repo.ClaimPending(ctx, r.batchSize, outboxClaimLease)
+	if err != nil {
+		return 0, err
+	}
+
+	pubCtx, cancel := context.WithTimeout(ctx, outboxPublishTimeout)
+	defer cancel()
+
+	for i, msg := range msgs {
+		if pubErr := r.publisher.Publish(pubCtx, msg.Queue, outboxPublishing(msg)); pubErr != nil {
+			retryAt := time.Now().Add(outboxRetryDelay(msg.Attempts))
+			if err := r.repo.MarkFailed(ctx, msg.ID, pubErr.Error(), retryAt); err != nil {
+				return i, err
+			}
+			log.Printf("outbox message %d to %s failed, retrying at %s: %v", msg.ID, msg.Queue, retryAt.Format(time.RFC3339), pubErr)
+			return i, r.repo.Release(ctx, outboxIDs(msgs[i+1:]))
+		}
+		if err := r.repo.MarkSent(ctx, msg.ID); err != nil {
+			return i, err
+		}
+	}
+	return len(msgs), nil
+}
+
+func outboxIDs(msgs []*domain.OutboxMessage) []int64 {
+	ids := make([]int64, 0, len(msgs))
+	for _, msg := range msgs {
+		ids = append(ids, msg.ID)
+	}
+	return ids
+}
+
+func outboxPublishing(msg *domain.OutboxMessage) amqp.Publishing {
+	return amqp.Publishing{
+		ContentType:   "application/json",
+		CorrelationId: msg.CorrelationID,
+		ReplyTo:       msg.ReplyTo,
+		Body:          msg.Payload,
+	}
+}
+
+// outboxRetryDelay doubles from one second per failed attempt up to
+// outboxMaxRetryDelay.
+func outboxRetryDelay(attempts int) time.Duration {
+	delay := time.Second
+	for i := 0; i < attempts && delay < outboxMaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > outboxMaxRetryDelay {
+		delay = outboxMaxRetryDelay
+	}
+	return delay
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type memOutbox struct {
+	pending  []*domain.OutboxMessage
+	lease    time.Duration
+	sent     []int64
+	failed   map[int64]time.Time
+	released []int64
+}
+
+func (o *memOutbox) ClaimPending(ctx context.Context, limit int, lease time.Duration) ([]*domain.OutboxMessage, error) {
+	o.lease = lease
+	if len(o.pending) < limit {
+		limit = len(o.pending)
+	}
+	return o.pending[:limit], nil
+}
+func (o *memOutbox) MarkSent(ctx context.Context, id int64) error {
+	o.sent = append(o.sent, id)
+	return nil
+}
+func (o *memOutbox) MarkFailed(ctx context.Context, id int64, errMsg string, availableAt time.Time) error {
+	o.failed[id] = availableAt
+	return nil
+}
+func (o *memOutbox) Release(ctx context.Context, ids []int64) error {
+	o.released = append(o.released, ids...)
+	return nil
+}
+
+var _ repository.OutboxRepository = (*memOutbox)(nil)
+
+type stubPublisher struct {
+	failOn    string
+	published []amqp.Publishing
+}
+
+func (p *stubPublisher) Publish(ctx context.Context, queue string, msg amqp.Publishing) error {
+	if msg.CorrelationId == p.failOn {
+		return ErrBrokerUnavailable
+	}
+	p.published = append(p.published, msg)
+	return nil
+}
+
+func TestOutboxRelayOnce(t *testing.T) {
+	t.Parallel()
+
+	outbox := &memOutbox{
+		failed: map[int64]time.Time{},
+		pending: []*domain.OutboxMessage{
+			{ID: 1, Queue: "print_queue", CorrelationID: "a", ReplyTo: "print_status", Payload: []byte(`{}`)},
+			{ID: 2, Queue: "print_queue", CorrelationID: "b", Payload: []byte(`{}`), Attempts: 3},
+			{ID: 3, Queue: "print_queue", CorrelationID: "c", Payload: []byte(`{}`)},
+		},
+	}
+	publisher := &stubPublisher{failOn: "b"}
+	relay := NewOutboxRelay(outbox, publisher, 10, time.Second)
+
+	sent, err := relay.RelayOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RelayOnce() error = %v", err)
+	}
+	if sent != 1 || len(outbox.sent) != 1 || outbox.sent[0] != 1 {
+		t.Fatalf("sent = %d %v, want only the message before the failure", sent, outbox.sent)
+	}
+	if got := publisher.published[0]; got.ReplyTo != "print_status" || got.CorrelationId != "a" {
+		t.Fatalf("published = %+v, want reply queue and correlation id", got)
+	}
+
+	retryAt, ok := outbox.failed[2]
+	if !ok {
+		t.Fatal("failed message was not rescheduled")
+	}
+	if d := time.Until(retryAt); d < 7*time.Second || d > 8*time.Second {
+		t.Fatalf("retry in %v, want 8s after three attempts", d)
+	}
+	if _, ok := outbox.failed[3]; ok {
+		t.Fatal("message after the failure was attempted")
+	}
+	if len(outbox.released) != 1 || outbox.released[0] != 3 {
+		t.Fatalf("released = %v, want the message after the failure", outbox.released)
+	}
+	if outbox.lease < outboxPublishTimeout {
+		t.Fatalf("claim lease = %v, want at least the publish timeout %v", outbox.lease, outboxPublishTimeout)
+	}
+}
+
+// blockingPublisher waits like a broker that never confirms.
+type blockingPublisher struct{}
+
+func (blockingPublisher) Publish(ctx context.Context, queue string, msg amqp.Publishing) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestOutboxRelayOnceBoundsPublish(t *testing.T) {
+	t.Parallel()
+
+	outbox := &memOutbox{
+		failed:  map[int64]time.Time{},
+		pending: []*domain.OutboxMessage{{ID: 1, Queue: "print_queue", Payload: []byte(`{}`)}},
+	}
+	relay := NewOutboxRelay(outbox, blockingPublisher{}, 10, time.Second)
+
+	// The caller's deadline stands in for outboxPublishTimeout.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	sent, err := relay.RelayOnce(ctx)
+	if err != nil || sent != 0 {
+		t.Fatalf("RelayOnce() = %d, %v, want 0, nil", sent, err)
+	}
+	if _, ok := outbox.failed[1]; !ok {
+		t.Fatal("timed out message was not rescheduled")
+	}
+}
+
+func TestOutboxRetryDelay(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 0, want: time.Second},
+		{attempts: 1, want: 2 * time.Second},
+		{attempts: 5, want: 32 * time.Second},
+		{attempts: 50, want: outboxMaxRetryDelay},
+	}
+
+	for _, tt := range tests {
+		if got := outboxRetryDelay(tt.attempts); got != tt.want {
+			t.Fatalf("outboxRetryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
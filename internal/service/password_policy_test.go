@@ -0,0 +1,63 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	t.Parallel()
+
+	policy := PasswordPolicy{
+		MinLength:     8,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		wantErr  bool
+		mentions []string
+	}{
+		{name: "strong", password: "Abcdef1!"},
+		{name: "cyrillic", password: "Пароль12#"},
+		{name: "empty", password: "   ", wantErr: true, mentions: []string{"empty"}},
+		{name: "short", password: "Ab1!", wantErr: true, mentions: []string{"8 characters"}},
+		{name: "no classes", password: "abcdefgh", wantErr: true, mentions: []string{"uppercase", "digit", "special"}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := policy.Validate(tt.password)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				return
+			}
+			if !errors.Is(err, ErrWeakPassword) {
+				t.Fatalf("Validate() error = %v, want %v", err, ErrWeakPassword)
+			}
+			for _, m := range tt.mentions {
+				if !strings.Contains(err.Error(), m) {
+					t.Fatalf("Validate() error = %q, want mention of %q", err.Error(), m)
+				}
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyZeroValue(t *testing.T) {
+	t.Parallel()
+
+	if err := (PasswordPolicy{}).Validate("x"); err != nil {
+		t.Fatalf("Validate() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrPrintJobNotFailed = errors.New("only failed print jobs can be retried")
+	ErrInvalidPrintJob   = errors.New("invalid print job status")
+)
+
+type PrintJobService struct {
+	repo      repository.PrintJobRepository
+	transport PrintTransport
+
+	// Translit is stamped on tasks that do not pick a transliteration
+	// scheme themselves.
+	Translit domain.TranslitScheme
+}
+
+func NewPrintJobService(repo repository.PrintJobRepository, transport PrintTransport) *PrintJobService {
+	return &PrintJobService{
+		repo:      repo,
+		transport: transport,
+	}
+}
+
+// Submit records the task as a queued job and hands it to the transport in
+// the same transaction.
+func (s *PrintJobService) Submit(ctx context.Context, task PrintTask, requestedBy *uuid.UUID) (*domain.PrintJob, error) {
+	var job *domain.PrintJob
+	err := s.repo.WithTx(ctx, func(tx repository.PrintJobTx) error {
+		var err error
+		job, err = s.schedule(ctx, tx, task, requestedBy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.notify()
+
+	return s.GetByID(ctx, job.ID)
+}
+
+// SubmitBatch records all tasks in one transaction: either every job is
+// queued or none is.
+func (s *PrintJobService) SubmitBatch(ctx context.Context, tasks []PrintTask, requestedBy *uuid.UUID) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(tasks))
+	err := s.repo.WithTx(ctx, func(tx repository.PrintJobTx) error {
+		for _, task := range tasks {
+			job, err := s.schedule(ctx, tx, task, requestedBy)
+			if err != nil {
+				return err
+			}
+			ids = append(ids, job.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.notify()
+
+	return ids, nil
+}
+
+func (s *PrintJobService) GetByID(ctx context.Context, id uuid.UUID) (*domain.PrintJob, error) {
+	job, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+func (s *PrintJobService) List(ctx context.Context, filter repository.PrintJobFilter) ([]*domain.PrintJob, error) {
+	if filter.Status != nil && !filter.Status.Valid() {
+		return nil, ErrInvalidPrintJob
+	}
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	jobs, err := s.repo.List(ctx, filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return jobs, nil
+}
+
+// Retry puts a failed job back on the queue with its original payload.
+func (s *PrintJobService) Retry(ctx context.Context, id uuid.UUID) (*domain.PrintJob, error) {
+	job, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != domain.PrintJobFailed {
+		return nil, ErrPrintJobNotFailed
+	}
+
+	var task PrintTask
+	if err := json.Unmarshal(job.Payload, &task); err != nil {
+		return nil, err
+	}
+	task.JobID = job.ID
+
+	err = s.repo.WithTx(ctx, func(tx repository.PrintJobTx) error {
+		if err := tx.RequeuePrintJob(ctx, id); err != nil {
+			return err
+		}
+		return s.transport.Enqueue(ctx, tx, task)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			// Someone else retried the job in the meantime.
+			return nil, ErrPrintJobNotFailed
+		}
+		return nil, err
+	}
+	s.notify()
+
+	return s.GetByID(ctx, id)
+}
+
+// ApplyStatus stores a status report from the print worker. Reports for
+// unknown jobs are ignored: they were sent before job tracking existed or
+// their job has been removed.
+func (s *PrintJobService) ApplyStatus(ctx context.Context, status PrintStatus) error {
+	if status.JobID == uuid.Nil || !status.Status.Valid() {
+		return nil
+	}
+
+	var errMsg *string
+	if status.Error != "" {
+		errMsg = &status.Error
+	}
+
+	err := s.repo.UpdateStatus(ctx, status.JobID, status.Status, errMsg)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// schedule stores a queued job in tx and enqueues it on the transport.
+// Callers must call notify once tx has committed.
+func (s *PrintJobService) schedule(ctx context.Context, tx repository.PrintJobTx, task PrintTask, requestedBy *uuid.UUID) (*domain.PrintJob, error) {
+	task.JobID = uuid.New()
+	if task.Translit == "" {
+		task.Translit = s.Translit
+	}
+
+	payload, err := json.Marshal(task)
+	if err != nil {
+		return nil, err
+	}
+
+	job := domain.PrintJob{
+		ID:          task.JobID,
+		RequestedBy: requestedBy,
+		Payload:     payload,
+		Status:      domain.PrintJobQueued,
+	}
+	if err := tx.CreatePrintJob(ctx, job); err != nil {
+		return nil, err
+	}
+	if err := s.transport.Enqueue(ctx, tx, task); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (s *PrintJobService) notify() {
+	if n, ok := s.transport.(PrintNotifier); ok {
+		n.Notify()
+	}
+}
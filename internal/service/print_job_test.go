@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"elibrary/internal/domain"
+	"elibrary/internal/repository"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type memPrintJobRepo struct {
+	jobs     map[uuid.UUID]*domain.PrintJob
+	messages []domain.OutboxMessage
+}
+
+func newMemPrintJobRepo() *memPrintJobRepo {
+	return &memPrintJobRepo{jobs: map[uuid.UUID]*domain.PrintJob{}}
+}
+
+func (r *memPrintJobRepo) GetByID(ctx context.Context, id uuid.UUID) (*domain.PrintJob, error) {
+	job, ok := r.jobs[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	cp := *job
+	return &cp, nil
+}
+func (r *memPrintJobRepo) List(ctx context.Context, filter repository.PrintJobFilter) ([]*domain.PrintJob, error) {
+	return nil, repository.ErrNotFound
+}
+func (r *memPrintJobRepo) UpdateStatus(ctx context.Context, id uuid.UUID, status domain.PrintJobStatus, errMsg *string) error {
+	job, ok := r.jobs[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	job.Status, job.Error = status, errMsg
+	return nil
+}
+func (r *memPrintJobRepo) ClaimNext(ctx context.Context) (*domain.PrintJob, error) {
+	return nil, repository.ErrNotFound
+}
+func (r *memPrintJobRepo) StaleClaims(ctx context.Context, claimedBefore time.Time) ([]*domain.PrintJob, error) {
+	return nil, repository.ErrNotFound
+}
+func (r *memPrintJobRepo) FinishClaim(ctx context.Context, id uuid.UUID, claimedAt time.Time, status domain.PrintJobStatus, errMsg *string) error {
+	return repository.ErrNotFound
+}
+func (r *memPrintJobRepo) RequeueClaim(ctx context.Context, id uuid.UUID, claimedAt time.Time, errMsg *string, retryAt time.Time) error {
+	return repository.ErrNotFound
+}
+func (r *memPrintJobRepo) WithTx(ctx context.Context, fn func(tx repository.PrintJobTx) error) error {
+	return fn(r)
+}
+func (r *memPrintJobRepo) CreatePrintJob(ctx context.Context, job domain.PrintJob) error {
+	job.Attempts = 1
+	r.jobs[job.ID] = &job
+	return nil
+}
+func (r *memPrintJobRepo) RequeuePrintJob(ctx context.Context, id uuid.UUID) error {
+	job, ok := r.jobs[id]
+	if !ok || job.Status != domain.PrintJobFailed {
+		return repository.ErrNotFound
+	}
+	job.Status, job.Error = domain.PrintJobQueued, nil
+	job.Attempts++
+	return nil
+}
+func (r *memPrintJobRepo) EnqueueMessage(ctx context.Context, msg domain.OutboxMessage) error {
+	r.messages = append(r.messages, msg)
+	return nil
+}
+
+var _ repository.PrintJobRepository = (*memPrintJobRepo)(nil)
+
+func newTestPrintJobService(repo *memPrintJobRepo) *PrintJobService {
+	return NewPrintJobService(repo, NewAMQPTransport(NewPrintQueue("", "print_queue", "print_status"), nil))
+}
+
+type notifyingTransport struct {
+	TableTransport
+	notified int
+}
+
+func (t *notifyingTransport) Notify() {
+	t.notified++
+}
+
+func TestPrintJobServiceSubmit(t *testing.T) {
+	t.Parallel()
+
+	repo := newMemPrintJobRepo()
+	svc := newTestPrintJobService(repo)
+	requester := uuid.New()
+
+	job, err := svc.Submit(context.Background(), PrintTask{Str1: "Title", Barcode: "2000000000015"}, &requester)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if job.Status != domain.PrintJobQueued || *job.RequestedBy != requester {
+		t.Fatalf("Submit() job = %+v, want queued job for %v", job, requester)
+	}
+
+	if len(repo.messages) != 1 {
+		t.Fatalf("outbox messages = %d, want 1", len(repo.messages))
+	}
+	msg := repo.messages[0]
+	if msg.Queue != "print_queue" || msg.ReplyTo != "print_status" || msg.CorrelationID != job.ID.String() {
+		t.Fatalf("outbox message = %+v, want print_queue with reply queue and job id", msg)
+	}
+
+	var sent PrintTask
+	if err := json.Unmarshal(msg.Payload, &sent); err != nil || sent.JobID != job.ID || sent.Barcode != "2000000000015" {
+		t.Fatalf("message payload = %s, want task with job id", msg.Payload)
+	}
+}
+
+func TestPrintJobServiceStampsTranslit(t *testing.T) {
+	t.Parallel()
+
+	repo := newMemPrintJobRepo()
+	svc := newTestPrintJobService(repo)
+	svc.Translit = domain.TranslitGOST
+
+	tasks := []PrintTask{
+		{Str1: "Книга", Barcode: "2000000000015"},
+		{Str1: "Книга", Barcode: "2000000000022", Translit: domain.TranslitNone},
+	}
+	if _, err := svc.SubmitBatch(context.Background(), tasks, nil); err != nil {
+		t.Fatalf("SubmitBatch() error = %v", err)
+	}
+
+	want := []domain.TranslitScheme{domain.TranslitGOST, domain.TranslitNone}
+	for i, msg := range repo.messages {
+		var sent PrintTask
+		if err := json.Unmarshal(msg.Payload, &sent); err != nil {
+			t.Fatalf("message payload = %s: %v", msg.Payload, err)
+		}
+		if sent.Translit != want[i] || sent.Str1 != "Книга" {
+			t.Fatalf("task %d = %+v, want untouched text with scheme %q", i, sent, want[i])
+		}
+	}
+}
+
+func TestPrintJobServiceRetry(t *testing.T) {
+	t.Parallel()
+
+	repo := newMemPrintJobRepo()
+	svc := newTestPrintJobService(repo)
+
+	job, _ := svc.Submit(context.Background(), PrintTask{Barcode: "2000000000015"}, nil)
+	if _, err := svc.Retry(context.Background(), job.ID); !errors.Is(err, ErrPrintJobNotFailed) {
+		t.Fatalf("Retry() error = %v, want %v", err, ErrPrintJobNotFailed)
+	}
+
+	_ = svc.ApplyStatus(context.Background(), PrintStatus{JobID: job.ID, Status: domain.PrintJobFailed, Error: "paper jam"})
+
+	retried, err := svc.Retry(context.Background(), job.ID)
+	if err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+	if retried.Status != domain.PrintJobQueued || retried.Attempts != 2 || retried.Error != nil {
+		t.Fatalf("Retry() job = %+v, want queued on second attempt", retried)
+	}
+	if len(repo.messages) != 2 || repo.messages[1].CorrelationID != job.ID.String() {
+		t.Fatalf("outbox messages = %+v, want resent task", repo.messages)
+	}
+
+	if _, err := svc.Retry(context.Background(), uuid.New()); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("Retry() error = %v, want %v", err, domain.ErrNotFound)
+	}
+}
+
+func TestPrintJobServiceApplyStatus(t *testing.T) {
+	t.Parallel()
+
+	repo := newMemPrintJobRepo()
+	svc := newTestPrintJobService(repo)
+	job, _ := svc.Submit(context.Background(), PrintTask{Barcode: "2000000000015"}, nil)
+
+	if err := svc.ApplyStatus(context.Background(), PrintStatus{JobID: job.ID, Status: domain.PrintJobFailed, Error: "invalid barcode"}); err != nil {
+		t.Fatalf("ApplyStatus() error = %v", err)
+	}
+	if got := repo.jobs[job.ID]; got.Status != domain.PrintJobFailed || got.Error == nil || *got.Error != "invalid barcode" {
+		t.Fatalf("job = %+v, want failed with worker error", got)
+	}
+
+	if err := svc.ApplyStatus(context.Background(), PrintStatus{JobID: uuid.New(), Status: domain.PrintJobDone}); err != nil {
+		t.Fatalf("ApplyStatus() for unknown job error = %v, want nil", err)
+	}
+	if err := svc.ApplyStatus(context.Background(), PrintStatus{JobID: job.ID, Status: "lost"}); err != nil {
+		t.Fatalf("ApplyStatus() for invalid status error = %v, want nil", err)
+	}
+	if got := repo.jobs[job.ID]; got.Status != domain.PrintJobFailed {
+		t.Fatalf("status = %q, want invalid report ignored", got.Status)
+	}
+}
+
+func TestPrintJobServiceNotifiesTransportAfterCommit(t *testing.T) {
+	t.Parallel()
+
+	repo := newMemPrintJobRepo()
+	transport := &notifyingTransport{}
+	svc := NewPrintJobService(repo, transport)
+
+	if _, err := svc.Submit(context.Background(), PrintTask{Barcode: "2000000000015"}, nil); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	if transport.notified != 1 {
+		t.Fatalf("notified = %d, want 1", transport.notified)
+	}
+	if len(repo.messages) != 0 {
+		t.Fatalf("outbox messages = %d, want none for table transport", len(repo.messages))
+	}
+}
+
+type stubAcknowledger struct {
+	acked   bool
+	nacked  bool
+	requeue bool
+}
+
+func (a *stubAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.acked = true
+	return nil
+}
+func (a *stubAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	a.nacked, a.requeue = true, requeue
+	return nil
+}
+func (a *stubAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+func TestPrintQueueHandleStatusRequeuesAfterDelay(t *testing.T) {
+	t.Parallel()
+
+	queue := NewPrintQueue("", "print_queue", "print_status")
+	queue.statusRetryDelay = 50 * time.Millisecond
+
+	ack := &stubAcknowledger{}
+	body := []byte(`{"job_id":"` + uuid.New().String() + `","status":"done"}`)
+	start := time.Now()
+	queue.handleStatus(context.Background(), amqp.Delivery{Acknowledger: ack, Body: body}, func(context.Context, PrintStatus) error {
+		return errors.New("database is down")
+	})
+
+	if !ack.nacked || !ack.requeue {
+		t.Fatalf("nack = %v requeue = %v, want a requeue", ack.nacked, ack.requeue)
+	}
+	if elapsed := time.Since(start); elapsed < queue.statusRetryDelay {
+		t.Fatalf("requeued after %v, want at least %v", elapsed, queue.statusRetryDelay)
+	}
+
+	ack = &stubAcknowledger{}
+	queue.handleStatus(context.Background(), amqp.Delivery{Acknowledger: ack, Body: []byte("{")}, func(context.Context, PrintStatus) error {
+		t.Fatal("apply called for a malformed status")
+		return nil
+	})
+	if !ack.nacked || ack.requeue {
+		t.Fatalf("nack = %v requeue = %v, want a drop", ack.nacked, ack.requeue)
+	}
+}
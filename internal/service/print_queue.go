@@ -2,27 +2,92 @@ package service
 
 import (
 	"context"
+	"elibrary/internal/domain"
 	"encoding/json"
+	"errors"
+	"log"
+	"time"
 
+	"github.com/google/uuid"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 type PrintTask struct {
-	Str1    string `json:"str1"`
-	Str2    string `json:"str2"`
-	Barcode string `json:"barcode"`
+	JobID   uuid.UUID `json:"job_id,omitempty"`
+	Str1    string    `json:"str1"`
+	Str2    string    `json:"str2"`
+	Barcode string    `json:"barcode"`
+	// Symbology is how the worker draws the symbol; empty means EAN-13.
+	Symbology domain.Symbology `json:"symbology,omitempty"`
+	// Data is what the symbol encodes when it differs from Barcode, such as
+	// a link in a QR code.
+	Data string `json:"data,omitempty"`
+	// Translit is how the worker spells Cyrillic text; empty means
+	// domain.TranslitSimple.
+	Translit domain.TranslitScheme `json:"translit,omitempty"`
 }
 
+// SymbolData returns the content of the symbol on the label.
+func (t PrintTask) SymbolData() string {
+	if t.Data != "" {
+		return t.Data
+	}
+	return t.Barcode
+}
+
+// PrintStatus is published by the print worker to the reply queue of a task
+// whenever the state of its job changes.
+type PrintStatus struct {
+	JobID  uuid.UUID             `json:"job_id"`
+	Status domain.PrintJobStatus `json:"status"`
+	Error  string                `json:"error,omitempty"`
+}
+
+// PrintQueue describes the AMQP side of printing: where tasks go and where
+// the worker reports their status. Tasks themselves reach the broker through
+// the outbox relay.
 type PrintQueue struct {
-	url   string
-	queue string
+	url         string
+	queue       string
+	statusQueue string
+	// statusRetryDelay is waited before a status report that could not be
+	// stored is requeued, so that a database outage does not turn into a
+	// hot redelivery loop.
+	statusRetryDelay time.Duration
+}
+
+const defaultStatusRetryDelay = 5 * time.Second
+
+func NewPrintQueue(url, queue, statusQueue string) *PrintQueue {
+	return &PrintQueue{
+		url:              url,
+		queue:            queue,
+		statusQueue:      statusQueue,
+		statusRetryDelay: defaultStatusRetryDelay,
+	}
 }
 
-func NewPrintQueue(url, queue string) *PrintQueue {
-	return &PrintQueue{url: url, queue: queue}
+// Message builds the outbox message that carries task to the print queue.
+func (p *PrintQueue) Message(task PrintTask) (domain.OutboxMessage, error) {
+	body, err := json.Marshal(task)
+	if err != nil {
+		return domain.OutboxMessage{}, err
+	}
+
+	msg := domain.OutboxMessage{
+		Queue:   p.queue,
+		ReplyTo: p.statusQueue,
+		Payload: body,
+	}
+	if task.JobID != uuid.Nil {
+		msg.CorrelationID = task.JobID.String()
+	}
+	return msg, nil
 }
 
-func (p *PrintQueue) Send(ctx context.Context, task PrintTask) error {
+// ListenStatus consumes worker status reports until ctx is cancelled or the
+// connection is lost. Callers reconnect by calling ListenStatus again.
+func (p *PrintQueue) ListenStatus(ctx context.Context, apply func(context.Context, PrintStatus) error) error {
 	conn, err := amqp.Dial(p.url)
 	if err != nil {
 		return err
@@ -36,7 +101,7 @@ func (p *PrintQueue) Send(ctx context.Context, task PrintTask) error {
 	defer ch.Close()
 
 	q, err := ch.QueueDeclare(
-		p.queue,
+		p.statusQueue,
 		true,
 		false,
 		false,
@@ -47,20 +112,41 @@ func (p *PrintQueue) Send(ctx context.Context, task PrintTask) error {
 		return err
 	}
 
-	body, err := json.Marshal(task)
+	deliveries, err := ch.Consume(q.Name, "", false, false, false, false, nil)
 	if err != nil {
 		return err
 	}
 
-	return ch.PublishWithContext(
-		ctx,
-		"",
-		q.Name,
-		false,
-		false,
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        body,
-		},
-	)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case d, ok := <-deliveries:
+			if !ok {
+				return errors.New("status delivery channel closed")
+			}
+			p.handleStatus(ctx, d, apply)
+		}
+	}
+}
+
+// handleStatus applies one status report. Malformed reports are dropped;
+// reports that fail to apply are requeued after statusRetryDelay.
+func (p *PrintQueue) handleStatus(ctx context.Context, d amqp.Delivery, apply func(context.Context, PrintStatus) error) {
+	var status PrintStatus
+	if err := json.Unmarshal(d.Body, &status); err != nil {
+		log.Printf("dropping malformed print status: %v", err)
+		_ = d.Nack(false, false)
+		return
+	}
+	if err := apply(ctx, status); err != nil {
+		log.Printf("failed to apply print status for job %s, requeueing: %v", status.JobID, err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(p.statusRetryDelay):
+		}
+		_ = d.Nack(false, true)
+		return
+	}
+	_ = d.Ack(false)
 }
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"elibrary/internal/repository"
+	"log"
+	"time"
+)
+
+// PrintTransport carries print jobs from the backend to a print worker and
+// brings their status back.
+type PrintTransport interface {
+	// Enqueue is called inside the transaction that stores the job, so the
+	// task is handed over only if the job is committed.
+	Enqueue(ctx context.Context, tx repository.PrintJobTx, task PrintTask) error
+	// Run delivers tasks and applies status reports until ctx is cancelled.
+	Run(ctx context.Context, apply func(context.Context, PrintStatus) error)
+}
+
+// PrintNotifier is implemented by transports that want to hear about new
+// jobs right after their transaction commits instead of polling for them.
+type PrintNotifier interface {
+	Notify()
+}
+
+// AMQPTransport publishes tasks to RabbitMQ through the outbox and reads
+// worker status reports from the reply queue.
+type AMQPTransport struct {
+	queue *PrintQueue
+	relay *OutboxRelay
+}
+
+func NewAMQPTransport(queue *PrintQueue, relay *OutboxRelay) *AMQPTransport {
+	return &AMQPTransport{queue: queue, relay: relay}
+}
+
+func (t *AMQPTransport) Enqueue(ctx context.Context, tx repository.PrintJobTx, task PrintTask) error {
+	msg, err := t.queue.Message(task)
+	if err != nil {
+		return err
+	}
+	return tx.EnqueueMessage(ctx, msg)
+}
+
+// Run relays the outbox and listens for status reports, reconnecting to
+// RabbitMQ with backoff.
+func (t *AMQPTransport) Run(ctx context.Context, apply func(context.Context, PrintStatus) error) {
+	go t.relay.Run(ctx)
+
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := t.queue.ListenStatus(ctx, apply)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > time.Minute {
+			backoff = time.Second
+		}
+		log.Printf("print status listener disconnected: %v; reconnecting in %s", err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if backoff < 30*time.Second {
+			backoff *= 2
+		}
+	}
+}
+
+// TableTransport uses the print_jobs table itself as the queue: a print
+// worker running with PRINT_TRANSPORT=postgres claims queued jobs and
+// writes their status back directly.
+type TableTransport struct{}
+
+func (TableTransport) Enqueue(ctx context.Context, tx repository.PrintJobTx, task PrintTask) error {
+	return nil
+}
+
+func (TableTransport) Run(ctx context.Context, apply func(context.Context, PrintStatus) error) {
+	<-ctx.Done()
+}
@@ -6,9 +6,26 @@ import (
 	"elibrary/internal/readmodel"
 	"elibrary/internal/repository"
 	"errors"
+	"fmt"
 	"strings"
+
+	"github.com/google/uuid"
 )
 
+var ErrLastRoleHolder = errors.New("cannot revoke role from its last active holder")
+
+// criticalRolePermissions lists permissions that built-in roles must keep so
+// that nobody can lock themselves out of role and user administration.
+var criticalRolePermissions = map[string][]string{
+	"admin": {
+		"roles.view",
+		"roles.manage",
+		"roles.assign",
+		"users.view",
+		"users.update",
+	},
+}
+
 type RoleService struct {
 	roleRepo repository.RoleRepository
 }
@@ -39,15 +56,180 @@ func (s *RoleService) GetAllPermissions(ctx context.Context) ([]*readmodel.Permi
 	return perms, nil
 }
 
+func (s *RoleService) GetByID(ctx context.Context, id int) (*readmodel.RoleWithPermissions, error) {
+	role, err := s.roleRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
 func (s *RoleService) Create(ctx context.Context, code, name string, permissionCodes []string) error {
 	code = strings.TrimSpace(code)
 	name = strings.TrimSpace(name)
 	if code == "" || name == "" {
 		return errors.New("code and name are required")
 	}
-	for i := range permissionCodes {
-		permissionCodes[i] = strings.TrimSpace(permissionCodes[i])
+	return s.roleRepo.Create(ctx, code, name, normalizePermissionCodes(permissionCodes))
+}
+
+type UpdateRoleRequest struct {
+	Name            *string   `json:"name"`
+	PermissionCodes *[]string `json:"permission_codes"`
+}
+
+func (s *RoleService) Update(ctx context.Context, id int, updates UpdateRoleRequest) error {
+	role, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	name := role.Name
+	if updates.Name != nil {
+		name = strings.TrimSpace(*updates.Name)
+		if name == "" {
+			return fmt.Errorf("%w: role name is required", domain.ErrInvalidInput)
+		}
+	}
+
+	codes := make([]string, 0, len(role.Permissions))
+	for _, p := range role.Permissions {
+		codes = append(codes, p.Code)
+	}
+	if updates.PermissionCodes != nil {
+		codes = normalizePermissionCodes(*updates.PermissionCodes)
+	}
+
+	if role.IsSystem {
+		for _, critical := range criticalRolePermissions[role.Code] {
+			if !containsString(codes, critical) {
+				return domain.ErrRoleProtected
+			}
+		}
 	}
-	return s.roleRepo.Create(ctx, code, name, permissionCodes)
+
+	if err := s.roleRepo.Update(ctx, id, name, codes); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+	return nil
 }
 
+func (s *RoleService) Delete(ctx context.Context, id int) error {
+	role, err := s.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if role.IsSystem {
+		return domain.ErrRoleProtected
+	}
+
+	if err := s.roleRepo.Delete(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// AssignToUser grants the role with the given code to the user. Assigning a
+// role the user already has is a no-op.
+func (s *RoleService) AssignToUser(ctx context.Context, userID uuid.UUID, roleCode string) error {
+	role, err := s.getByCode(ctx, roleCode)
+	if err != nil {
+		return err
+	}
+
+	if err := s.roleRepo.AssignToUser(ctx, userID, role.ID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// RevokeFromUser removes the role from the user. Built-in roles with critical
+// permissions cannot be revoked from their last active holder.
+func (s *RoleService) RevokeFromUser(ctx context.Context, userID uuid.UUID, roleCode string) error {
+	role, err := s.getByCode(ctx, roleCode)
+	if err != nil {
+		return err
+	}
+
+	holds, err := s.roleRepo.UserHasRole(ctx, userID, role.ID)
+	if err != nil {
+		return err
+	}
+	if !holds {
+		return domain.ErrNotFound
+	}
+
+	if role.IsSystem {
+		if err := ensureOtherRoleHolder(ctx, s.roleRepo, role.Code, role.ID, userID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.roleRepo.RevokeFromUser(ctx, userID, role.ID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// ensureOtherRoleHolder returns ErrLastRoleHolder when the role carries
+// critical permissions and no active user other than userID holds it.
+func ensureOtherRoleHolder(ctx context.Context, repo repository.RoleRepository, roleCode string, roleID int, userID uuid.UUID) error {
+	if len(criticalRolePermissions[roleCode]) == 0 {
+		return nil
+	}
+	others, err := repo.CountOtherActiveHolders(ctx, roleID, userID)
+	if err != nil {
+		return err
+	}
+	if others == 0 {
+		return ErrLastRoleHolder
+	}
+	return nil
+}
+
+func (s *RoleService) getByCode(ctx context.Context, code string) (*readmodel.RoleWithPermissions, error) {
+	role, err := s.roleRepo.GetByCode(ctx, strings.TrimSpace(code))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+func normalizePermissionCodes(codes []string) []string {
+	result := make([]string, 0, len(codes))
+	for _, code := range codes {
+		code = strings.TrimSpace(code)
+		if code == "" || containsString(result, code) {
+			continue
+		}
+		result = append(result, code)
+	}
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"elibrary/internal/domain"
+	"elibrary/internal/readmodel"
+	"elibrary/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+type stubRoleRepo struct {
+	role         *readmodel.RoleWithPermissions
+	otherHolders int
+	holds        bool
+
+	updated  []string
+	deleted  bool
+	revoked  bool
+	assigned bool
+}
+
+func (s *stubRoleRepo) GetAllWithPermissions(ctx context.Context) ([]*readmodel.RoleWithPermissions, error) {
+	return nil, repository.ErrNotFound
+}
+func (s *stubRoleRepo) GetAllPermissions(ctx context.Context) ([]*readmodel.Permission, error) {
+	return nil, repository.ErrNotFound
+}
+func (s *stubRoleRepo) GetByID(ctx context.Context, id int) (*readmodel.RoleWithPermissions, error) {
+	if s.role == nil || s.role.ID != id {
+		return nil, repository.ErrNotFound
+	}
+	return s.role, nil
+}
+func (s *stubRoleRepo) GetByCode(ctx context.Context, code string) (*readmodel.RoleWithPermissions, error) {
+	if s.role == nil || s.role.Code != code {
+		return nil, repository.ErrNotFound
+	}
+	return s.role, nil
+}
+func (s *stubRoleRepo) Create(ctx context.Context, code, name string, permissionCodes []string) error {
+	return nil
+}
+func (s *stubRoleRepo) Update(ctx context.Context, id int, name string, permissionCodes []string) error {
+	s.updated = permissionCodes
+	return nil
+}
+func (s *stubRoleRepo) Delete(ctx context.Context, id int) error {
+	s.deleted = true
+	return nil
+}
+func (s *stubRoleRepo) AssignToUser(ctx context.Context, userID uuid.UUID, roleID int) error {
+	s.assigned = true
+	return nil
+}
+func (s *stubRoleRepo) RevokeFromUser(ctx context.Context, userID uuid.UUID, roleID int) error {
+	s.revoked = true
+	return nil
+}
+func (s *stubRoleRepo) UserHasRole(ctx context.Context, userID uuid.UUID, roleID int) (bool, error) {
+	return s.holds, nil
+}
+func (s *stubRoleRepo) CountOtherActiveHolders(ctx context.Context, roleID int, userID uuid.UUID) (int, error) {
+	return s.otherHolders, nil
+}
+
+var _ repository.RoleRepository = (*stubRoleRepo)(nil)
+
+func adminRole() *readmodel.RoleWithPermissions {
+	role := &readmodel.RoleWithPermissions{ID: 1, Code: "admin", Name: "Администратор", IsSystem: true}
+	for _, code := range append(criticalRolePermissions["admin"], "books.purge") {
+		role.Permissions = append(role.Permissions, readmodel.Permission{Code: code})
+	}
+	return role
+}
+
+func TestRoleServiceDeleteProtectsBuiltInRoles(t *testing.T) {
+	t.Parallel()
+
+	repo := &stubRoleRepo{role: adminRole()}
+	err := NewRoleService(repo).Delete(context.Background(), 1)
+	if !errors.Is(err, domain.ErrRoleProtected) {
+		t.Fatalf("Delete() error = %v, want %v", err, domain.ErrRoleProtected)
+	}
+	if repo.deleted {
+		t.Fatal("Delete() removed a built-in role")
+	}
+
+	custom := &stubRoleRepo{role: &readmodel.RoleWithPermissions{ID: 7, Code: "intern"}}
+	if err := NewRoleService(custom).Delete(context.Background(), 7); err != nil {
+		t.Fatalf("Delete() error = %v, want nil", err)
+	}
+	if !custom.deleted {
+		t.Fatal("Delete() did not remove a custom role")
+	}
+}
+
+func TestRoleServiceUpdateKeepsCriticalPermissions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		codes   []string
+		wantErr error
+	}{
+		{name: "drops critical", codes: []string{"roles.view", "books.purge"}, wantErr: domain.ErrRoleProtected},
+		{name: "drops optional", codes: append([]string{" books.view "}, criticalRolePermissions["admin"]...)},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := &stubRoleRepo{role: adminRole()}
+			codes := tt.codes
+			err := NewRoleService(repo).Update(context.Background(), 1, UpdateRoleRequest{PermissionCodes: &codes})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Update() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && repo.updated[0] != "books.view" {
+				t.Fatalf("Update() permission codes = %v, want trimmed codes", repo.updated)
+			}
+		})
+	}
+}
+
+func TestRoleServiceUpdateRenameKeepsPermissions(t *testing.T) {
+	t.Parallel()
+
+	repo := &stubRoleRepo{role: adminRole()}
+	name := "Суперпользователь"
+	if err := NewRoleService(repo).Update(context.Background(), 1, UpdateRoleRequest{Name: &name}); err != nil {
+		t.Fatalf("Update() error = %v, want nil", err)
+	}
+	if len(repo.updated) != len(adminRole().Permissions) {
+		t.Fatalf("Update() permission codes = %v, want existing set", repo.updated)
+	}
+}
+
+func TestRoleServiceUpdateRejectsBlankName(t *testing.T) {
+	t.Parallel()
+
+	repo := &stubRoleRepo{role: adminRole()}
+	name := "  "
+	err := NewRoleService(repo).Update(context.Background(), 1, UpdateRoleRequest{Name: &name})
+	if !errors.Is(err, domain.ErrInvalidInput) {
+		t.Fatalf("Update() error = %v, want %v", err, domain.ErrInvalidInput)
+	}
+	if repo.updated != nil {
+		t.Fatal("Update() stored a role with a blank name")
+	}
+}
+
+func TestRoleServiceRevokeFromLastHolder(t *testing.T) {
+	t.Parallel()
+
+	repo := &stubRoleRepo{role: adminRole(), holds: true}
+	err := NewRoleService(repo).RevokeFromUser(context.Background(), uuid.New(), "admin")
+	if !errors.Is(err, ErrLastRoleHolder) {
+		t.Fatalf("RevokeFromUser() error = %v, want %v", err, ErrLastRoleHolder)
+	}
+
+	repo = &stubRoleRepo{role: adminRole(), holds: true, otherHolders: 1}
+	if err := NewRoleService(repo).RevokeFromUser(context.Background(), uuid.New(), "admin"); err != nil {
+		t.Fatalf("RevokeFromUser() error = %v, want nil", err)
+	}
+	if !repo.revoked {
+		t.Fatal("RevokeFromUser() did not revoke the role")
+	}
+}
+
+func TestRoleServiceRevokeRoleNotHeld(t *testing.T) {
+	t.Parallel()
+
+	repo := &stubRoleRepo{role: adminRole()}
+	err := NewRoleService(repo).RevokeFromUser(context.Background(), uuid.New(), "admin")
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("RevokeFromUser() error = %v, want %v", err, domain.ErrNotFound)
+	}
+	if repo.revoked {
+		t.Fatal("RevokeFromUser() revoked a role the user does not hold")
+	}
+}
+
+func TestRoleServiceAssignUnknownRole(t *testing.T) {
+	t.Parallel()
+
+	repo := &stubRoleRepo{role: adminRole()}
+	err := NewRoleService(repo).AssignToUser(context.Background(), uuid.New(), "ghost")
+	if !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("AssignToUser() error = %v, want %v", err, domain.ErrNotFound)
+	}
+	if repo.assigned {
+		t.Fatal("AssignToUser() assigned an unknown role")
+	}
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"elibrary/internal/domain"
+	"elibrary/internal/readmodel"
+	"elibrary/internal/repository"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// maxScanCodeLength bounds what a scanner may send; longer input is not a
+// barcode we could have printed or a publisher could have.
+const maxScanCodeLength = 64
+
+// maxScanMatches caps the candidates of an ambiguous scan.
+const maxScanMatches = 20
+
+type ScanKind string
+
+const (
+	ScanKindBook      ScanKind = "book"
+	ScanKindLocation  ScanKind = "location"
+	ScanKindAmbiguous ScanKind = "ambiguous"
+)
+
+// ScanMatch says which field a scanned code was found in.
+type ScanMatch string
+
+const (
+	ScanMatchBarcode        ScanMatch = "barcode"
+	ScanMatchFactoryBarcode ScanMatch = "factory_barcode"
+	ScanMatchISBN           ScanMatch = "isbn"
+)
+
+// ScanResult is a book, a location or, when several books share a factory
+// barcode, the list of candidates.
+type ScanResult struct {
+	Kind      ScanKind                  `json:"kind"`
+	Code      string                    `json:"code"`
+	MatchedBy ScanMatch                 `json:"matched_by"`
+	Book      *readmodel.BookInternal   `json:"book,omitempty"`
+	Location  *domain.Location          `json:"location,omitempty"`
+	Books     []*readmodel.BookInternal `json:"books,omitempty"`
+	// Replaced is set when the code is an old one taken off the book or
+	// location by relabeling.
+	Replaced bool `json:"replaced,omitempty"`
+}
+
+type ScanService struct {
+	books     repository.BookRepository
+	locations repository.LocationRepository
+	barcodes  *BarcodeService
+}
+
+func NewScanService(
+	books repository.BookRepository,
+	locations repository.LocationRepository,
+	barcodes *BarcodeService,
+) *ScanService {
+	return &ScanService{
+		books:     books,
+		locations: locations,
+		barcodes:  barcodes,
+	}
+}
+
+// Resolve finds what a scanned code belongs to. In-house EAN-13 codes are
+// dispatched by their prefix range; anything else is looked up among the
+// factory barcodes of books and, when it reads as one, their ISBN or ISSN.
+func (s *ScanService) Resolve(ctx context.Context, raw string) (*ScanResult, error) {
+	code := strings.TrimSpace(raw)
+	if !validScanCode(code) {
+		return nil, domain.ErrInvalidBarcode
+	}
+
+	if isDigits(code) && len(code) == 13 {
+		// A bad check digit is a misread, not an unknown code.
+		if !s.barcodes.ValidateEAN13(code) {
+			return nil, domain.ErrInvalidBarcode
+		}
+		prefix, _ := strconv.Atoi(code[:3])
+		if t, ok := domain.BarcodeTypeOfPrefix(prefix); ok {
+			res, err := s.resolveOwn(ctx, code, t)
+			if !errors.Is(err, domain.ErrNotFound) {
+				return res, err
+			}
+		}
+	}
+
+	return s.resolveFactory(ctx, code)
+}
+
+func (s *ScanService) resolveOwn(ctx context.Context, code string, t domain.BarcodeType) (*ScanResult, error) {
+	res := &ScanResult{Code: code, MatchedBy: ScanMatchBarcode}
+
+	switch t {
+	case domain.BarcodeTypeLocation:
+		loc, err := s.locations.GetByBarcode(ctx, code)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, domain.ErrNotFound
+			}
+			return nil, err
+		}
+		res.Kind, res.Location = ScanKindLocation, loc
+		res.Replaced = loc.Barcode != code
+	default:
+		books, err := s.books.GetInternal(ctx, repository.BookFilter{Barcode: &code})
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, domain.ErrNotFound
+			}
+			return nil, err
+		}
+		res.Kind, res.Book = ScanKindBook, books[0]
+		res.Replaced = books[0].Barcode != code
+	}
+	return res, nil
+}
+
+func (s *ScanService) resolveFactory(ctx context.Context, code string) (*ScanResult, error) {
+	res := &ScanResult{Code: code, MatchedBy: ScanMatchISBN}
+	seen := make(map[uuid.UUID]bool)
+
+	candidates := []string{code}
+	if compact := compactCode(code); compact != code {
+		candidates = append(candidates, compact)
+	}
+	for _, candidate := range candidates {
+		limit := maxScanMatches
+		filter := withStandardNumbers(repository.BookFilter{FactoryBarcode: &candidate, Limit: &limit})
+		books, err := s.books.GetInternal(ctx, filter)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		for _, b := range books {
+			if b.FactoryBarcode != nil && *b.FactoryBarcode == candidate {
+				res.MatchedBy = ScanMatchFactoryBarcode
+			}
+			if !seen[b.ID] && len(res.Books) < maxScanMatches {
+				seen[b.ID] = true
+				res.Books = append(res.Books, b)
+			}
+		}
+	}
+
+	switch len(res.Books) {
+	case 0:
+		return nil, domain.ErrNotFound
+	case 1:
+		res.Kind, res.Book, res.Books = ScanKindBook, res.Books[0], nil
+	default:
+		res.Kind = ScanKindAmbiguous
+	}
+	return res, nil
+}
+
+func validScanCode(code string) bool {
+	if code == "" || len(code) > maxScanCodeLength {
+		return false
+	}
+	for i := 0; i < len(code); i++ {
+		if code[i] < 0x20 || code[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// compactCode drops the hyphens and spaces a factory barcode is often
+// typed with. Other spellings of an ISBN or ISSN are matched through the
+// normalized numbers of the books.
+func compactCode(code string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"elibrary/internal/domain"
+	"elibrary/internal/readmodel"
+	"elibrary/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// stubScanBookRepo finds books by exact barcode, replaced barcode, factory
+// barcode or normalized ISBN.
+type stubScanBookRepo struct {
+	repository.BookRepository
+	books    []*readmodel.BookInternal
+	replaced map[string]uuid.UUID
+}
+
+func (s stubScanBookRepo) GetInternal(ctx context.Context, filter repository.BookFilter) ([]*readmodel.BookInternal, error) {
+	var res []*readmodel.BookInternal
+	for _, b := range s.books {
+		switch {
+		case filter.Barcode != nil && b.Barcode == *filter.Barcode:
+			res = append(res, b)
+		case filter.Barcode != nil && s.replaced[*filter.Barcode] == b.ID:
+			res = append(res, b)
+		case filter.FactoryBarcode != nil && b.FactoryBarcode != nil && *b.FactoryBarcode == *filter.FactoryBarcode:
+			res = append(res, b)
+		case filter.ISBN != nil && b.ISBN != nil && strings.ReplaceAll(*b.ISBN, "-", "") == *filter.ISBN:
+			res = append(res, b)
+		}
+	}
+	if len(res) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	return res, nil
+}
+
+func TestScanServiceResolve(t *testing.T) {
+	t.Parallel()
+
+	own := &readmodel.BookInternal{ID: uuid.New(), Barcode: "2000000000015"}
+	isbn13 := &readmodel.BookInternal{ID: uuid.New(), Barcode: "2000000000022", FactoryBarcode: strPtr("9785170988853"), ISBN: strPtr("978-5-17-098885-3")}
+	typed := &readmodel.BookInternal{ID: uuid.New(), Barcode: "2000000000053", ISBN: strPtr("978-5-389-01006-2")}
+	copyA := &readmodel.BookInternal{ID: uuid.New(), Barcode: "2000000000039", FactoryBarcode: strPtr("4607001234562")}
+	copyB := &readmodel.BookInternal{ID: uuid.New(), Barcode: "2000000000046", FactoryBarcode: strPtr("4607001234562")}
+	shelf := &domain.Location{ID: uuid.New(), Type: domain.LocationTypeShelf, Barcode: "3000000000014"}
+
+	svc := NewScanService(
+		stubScanBookRepo{books: []*readmodel.BookInternal{own, isbn13, typed, copyA, copyB}},
+		stubLocationRepo{byID: map[uuid.UUID]*domain.Location{shelf.ID: shelf}},
+		NewBarcodeService(nil),
+	)
+
+	tests := []struct {
+		name    string
+		code    string
+		kind    ScanKind
+		match   ScanMatch
+		wantErr error
+	}{
+		{name: "own book", code: "2000000000015", kind: ScanKindBook, match: ScanMatchBarcode},
+		{name: "location", code: " 3000000000014 ", kind: ScanKindLocation, match: ScanMatchBarcode},
+		{name: "factory barcode", code: "9785170988853", kind: ScanKindBook, match: ScanMatchFactoryBarcode},
+		{name: "hyphenated isbn-10", code: "5-17-098885-0", kind: ScanKindBook, match: ScanMatchISBN},
+		{name: "isbn without factory barcode", code: "9785389010062", kind: ScanKindBook, match: ScanMatchISBN},
+		{name: "several copies", code: "4607001234562", kind: ScanKindAmbiguous, match: ScanMatchFactoryBarcode},
+		{name: "bad check digit", code: "2000000000016", wantErr: domain.ErrInvalidBarcode},
+		{name: "unknown location", code: "3000000000021", wantErr: domain.ErrNotFound},
+		{name: "empty", code: " ", wantErr: domain.ErrInvalidBarcode},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			res, err := svc.Resolve(context.Background(), tt.code)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Resolve() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if res.Kind != tt.kind || res.MatchedBy != tt.match {
+				t.Fatalf("Resolve() = %s by %s, want %s by %s", res.Kind, res.MatchedBy, tt.kind, tt.match)
+			}
+			switch res.Kind {
+			case ScanKindBook:
+				if res.Book == nil || res.Books != nil {
+					t.Fatalf("Resolve() = %+v, want a single book", res)
+				}
+			case ScanKindLocation:
+				if res.Location != shelf {
+					t.Fatalf("Resolve() location = %+v, want %+v", res.Location, shelf)
+				}
+			case ScanKindAmbiguous:
+				if len(res.Books) != 2 {
+					t.Fatalf("Resolve() candidates = %d, want 2", len(res.Books))
+				}
+			}
+		})
+	}
+}
+
+func TestScanServiceResolveReplacedBarcode(t *testing.T) {
+	t.Parallel()
+
+	book := &readmodel.BookInternal{ID: uuid.New(), Barcode: "2000000000022"}
+	svc := NewScanService(
+		stubScanBookRepo{
+			books:    []*readmodel.BookInternal{book},
+			replaced: map[string]uuid.UUID{"2000000000015": book.ID},
+		},
+		stubLocationRepo{},
+		NewBarcodeService(nil),
+	)
+
+	res, err := svc.Resolve(context.Background(), "2000000000015")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if res.Book != book || !res.Replaced {
+		t.Fatalf("Resolve() = %+v, want the book flagged as replaced", res)
+	}
+
+	res, err = svc.Resolve(context.Background(), "2000000000022")
+	if err != nil {
+		t.Fatalf("Resolve() error = %v", err)
+	}
+	if res.Replaced {
+		t.Fatal("Resolve() replaced = true for the current barcode, want false")
+	}
+}
+
+func TestCompactCode(t *testing.T) {
+	t.Parallel()
+
+	if got := compactCode("978-5-17 098885-3"); got != "9785170988853" {
+		t.Fatalf("compactCode() = %q, want %q", got, "9785170988853")
+	}
+	if got := compactCode("0317-847x"); got != "0317847X" {
+		t.Fatalf("compactCode(ISSN) = %q, want %q", got, "0317847X")
+	}
+}
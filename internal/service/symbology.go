@@ -0,0 +1,124 @@
+package service
+
+import (
+	"bytes"
+	"elibrary/internal/domain"
+	"fmt"
+	"image/png"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/datamatrix"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/qr"
+	"github.com/google/uuid"
+)
+
+// Symbologies choose how labels draw the barcode of each type. Empty fields
+// mean EAN-13.
+type Symbologies struct {
+	Book     domain.Symbology
+	Location domain.Symbology
+	// LocationLink is the URL that 2D symbols on location labels encode, so
+	// that a phone camera opens the shelf contents. "{id}" and "{barcode}"
+	// are replaced with the location ID and barcode. When empty, 2D symbols
+	// encode the barcode itself.
+	LocationLink string
+}
+
+func (s Symbologies) For(t domain.BarcodeType) domain.Symbology {
+	var sym domain.Symbology
+	switch t {
+	case domain.BarcodeTypeBook:
+		sym = s.Book
+	case domain.BarcodeTypeLocation:
+		sym = s.Location
+	}
+	if sym == "" {
+		return domain.SymbologyEAN13
+	}
+	return sym
+}
+
+// EncodeSymbol encodes data in the given symbology; an empty symbology
+// means EAN-13.
+func EncodeSymbol(sym domain.Symbology, data string) (barcode.Barcode, error) {
+	if data == "" {
+		return nil, fmt.Errorf("%w: nothing to encode", domain.ErrInvalidBarcode)
+	}
+
+	var (
+		code barcode.Barcode
+		err  error
+	)
+	switch sym {
+	case "", domain.SymbologyEAN13:
+		if len(data) != 13 {
+			return nil, fmt.Errorf("%w: EAN-13 needs 13 digits, got %q", domain.ErrInvalidBarcode, data)
+		}
+		code, err = ean.Encode(data)
+	case domain.SymbologyCode128:
+		code, err = code128.Encode(data)
+	case domain.SymbologyQR:
+		code, err = qr.Encode(data, qr.M, qr.Auto)
+	case domain.SymbologyDataMatrix:
+		code, err = datamatrix.Encode(data)
+	default:
+		return nil, domain.ErrInvalidSymbology
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidBarcode, err)
+	}
+	return code, nil
+}
+
+// GenerateSymbolImage renders a PNG for label printers: 300x150 for bars, as
+// GenerateBarcodeImage does, and about 150x150 for 2D symbols.
+func (s *BarcodeService) GenerateSymbolImage(sym domain.Symbology, data string) ([]byte, error) {
+	if sym == "" || sym == domain.SymbologyEAN13 {
+		return s.GenerateBarcodeImage(data)
+	}
+
+	code, err := EncodeSymbol(sym, data)
+	if err != nil {
+		return nil, err
+	}
+
+	width, height := 300, 150
+	if sym.TwoDimensional() {
+		// Whole pixels per module keep the matrix cells square.
+		cell := max(1, 150/code.Bounds().Dx())
+		width, height = code.Bounds().Dx()*cell, code.Bounds().Dy()*cell
+	}
+	scaled, err := barcode.Scale(code, width, height)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scale barcode: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, scaled); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ApplySymbology sets how the label of an object of type t is drawn:
+// override, when set, wins over the configured default, and 2D symbols on
+// location labels carry the deep link.
+func (s *BarcodeService) ApplySymbology(task *PrintTask, t domain.BarcodeType, id uuid.UUID, override domain.Symbology) error {
+	sym := s.Symbologies.For(t)
+	if override != "" {
+		if !override.Valid() {
+			return domain.ErrInvalidSymbology
+		}
+		sym = override
+	}
+
+	task.Symbology = sym
+	if sym.TwoDimensional() && t == domain.BarcodeTypeLocation && s.Symbologies.LocationLink != "" {
+		task.Data = strings.NewReplacer("{id}", id.String(), "{barcode}", task.Barcode).
+			Replace(s.Symbologies.LocationLink)
+	}
+	return nil
+}
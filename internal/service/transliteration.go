@@ -1,8 +1,33 @@
 package service
 
-import "strings"
+import (
+	"elibrary/internal/domain"
+	"strings"
+	"unicode"
+)
 
-var ruTranslit = map[rune]string{
+// translitTable maps lower-case Cyrillic letters, Russian plus the extra
+// Ukrainian, Belarusian and Kazakh ones, to Latin. Letters shared by several
+// languages use their Russian reading.
+type translitTable struct {
+	letters map[rune]string
+	// context, when set, may replace a letter depending on its lower-case
+	// neighbours; zero runes stand for the start and end of the text.
+	context func(r, prev, next rune) (string, bool)
+	// upperWords spells capitals in full inside upper-case words, so that
+	// "ЩИ" becomes "SHCHI" rather than "ShchI".
+	upperWords bool
+}
+
+var translitTables = map[domain.TranslitScheme]translitTable{
+	domain.TranslitSimple: {letters: simpleTranslit},
+	domain.TranslitGOST:   {letters: gostTranslit, context: gostContext, upperWords: true},
+	domain.TranslitISO9:   {letters: iso9Translit, upperWords: true},
+	domain.TranslitBGN:    {letters: bgnTranslit, context: bgnContext, upperWords: true},
+	domain.TranslitICAO:   {letters: icaoTranslit, upperWords: true},
+}
+
+var simpleTranslit = map[rune]string{
 	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d",
 	'е': "e", 'ё': "yo", 'ж': "zh", 'з': "z", 'и': "i",
 	'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n",
@@ -10,27 +35,152 @@ var ruTranslit = map[rune]string{
 	'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch",
 	'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "",
 	'э': "e", 'ю': "yu", 'я': "ya",
-	'А': "A", 'Б': "B", 'В': "V", 'Г': "G", 'Д': "D",
-	'Е': "E", 'Ё': "Yo", 'Ж': "Zh", 'З': "Z", 'И': "I",
-	'Й': "Y", 'К': "K", 'Л': "L", 'М': "M", 'Н': "N",
-	'О': "O", 'П': "P", 'Р': "R", 'С': "S", 'Т': "T",
-	'У': "U", 'Ф': "F", 'Х': "Kh", 'Ц': "Ts", 'Ч': "Ch",
-	'Ш': "Sh", 'Щ': "Shch", 'Ъ': "", 'Ы': "Y", 'Ь': "",
-	'Э': "E", 'Ю': "Yu", 'Я': "Ya",
+	'ґ': "g", 'є': "ye", 'і': "i", 'ї': "yi", 'ў': "u",
+	'ә': "a", 'ғ': "gh", 'қ': "q", 'ң': "ng", 'ө': "o",
+	'ұ': "u", 'ү': "u", 'һ': "h",
 }
 
-func TransliterateRuToEn(s string) string {
-	if s == "" {
+// gostTranslit is GOST 7.79-2000, System B.
+var gostTranslit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d",
+	'е': "e", 'ё': "yo", 'ж': "zh", 'з': "z", 'и': "i",
+	'й': "j", 'к': "k", 'л': "l", 'м': "m", 'н': "n",
+	'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t",
+	'у': "u", 'ф': "f", 'х': "x", 'ц': "c", 'ч': "ch",
+	'ш': "sh", 'щ': "shh", 'ъ': "``", 'ы': "y'", 'ь': "`",
+	'э': "e`", 'ю': "yu", 'я': "ya",
+	'ґ': "g`", 'є': "ye", 'і': "i", 'ї': "yi", 'ў': "u`",
+	'ә': "a`", 'ғ': "g`", 'қ': "k`", 'ң': "n`", 'ө': "o`",
+	'ұ': "u`", 'ү': "u`", 'һ': "h`",
+}
+
+// gostContext writes ц as "cz" before letters spelled with i, e, y or j.
+func gostContext(r, prev, next rune) (string, bool) {
+	if r != 'ц' {
+		return "", false
+	}
+	if v := gostTranslit[next]; v != "" && strings.ContainsRune("eijy", rune(v[0])) {
+		return "cz", true
+	}
+	return "c", true
+}
+
+// iso9Translit is ISO 9:1995.
+var iso9Translit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d",
+	'е': "e", 'ё': "ë", 'ж': "ž", 'з': "z", 'и': "i",
+	'й': "j", 'к': "k", 'л': "l", 'м': "m", 'н': "n",
+	'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t",
+	'у': "u", 'ф': "f", 'х': "h", 'ц': "c", 'ч': "č",
+	'ш': "š", 'щ': "ŝ", 'ъ': "ʺ", 'ы': "y", 'ь': "ʹ",
+	'э': "è", 'ю': "û", 'я': "â",
+	'ґ': "g̀", 'є': "ê", 'і': "ì", 'ї': "ï", 'ў': "ŭ",
+	'ә': "a̋", 'ғ': "ġ", 'қ': "ķ", 'ң': "ṇ", 'ө': "ô",
+	'ұ': "u̇", 'ү': "ù", 'һ': "ḥ",
+}
+
+// bgnTranslit is BGN/PCGN romanization with ASCII apostrophes for the
+// hard and soft signs.
+var bgnTranslit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d",
+	'е': "e", 'ё': "ë", 'ж': "zh", 'з': "z", 'и': "i",
+	'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n",
+	'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t",
+	'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch",
+	'ш': "sh", 'щ': "shch", 'ъ': "\"", 'ы': "y", 'ь': "'",
+	'э': "e", 'ю': "yu", 'я': "ya",
+	'ґ': "g", 'є': "ye", 'і': "i", 'ї': "yi", 'ў': "w",
+	'ә': "ä", 'ғ': "gh", 'қ': "q", 'ң': "ng", 'ө': "ö",
+	'ұ': "ū", 'ү': "ü", 'һ': "h",
+}
+
+// bgnContext writes е and ё as "ye" and "yë" at the start of a word and
+// after vowels, й, ъ and ь.
+func bgnContext(r, prev, next rune) (string, bool) {
+	if r != 'е' && r != 'ё' {
+		return "", false
+	}
+	if prev != 0 && unicode.IsLetter(prev) && !strings.ContainsRune("аеёиоуыэюяєіїәөұүйъь", prev) {
+		return "", false
+	}
+	return "y" + bgnTranslit[r], true
+}
+
+// icaoTranslit is the ICAO Doc 9303 table used in machine-readable
+// passports.
+var icaoTranslit = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d",
+	'е': "e", 'ё': "e", 'ж': "zh", 'з': "z", 'и': "i",
+	'й': "i", 'к': "k", 'л': "l", 'м': "m", 'н': "n",
+	'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t",
+	'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch",
+	'ш': "sh", 'щ': "shch", 'ъ': "ie", 'ы': "y", 'ь': "",
+	'э': "e", 'ю': "iu", 'я': "ia",
+	'ґ': "g", 'є': "ie", 'і': "i", 'ї': "i", 'ў': "u",
+	'ә': "a", 'ғ': "g", 'қ': "k", 'ң': "n", 'ө': "o",
+	'ұ': "u", 'ү': "u", 'һ': "h",
+}
+
+// Transliterate spells Cyrillic letters of s in Latin according to scheme.
+// Other characters are kept. TranslitNone returns s unchanged; an empty or
+// unknown scheme falls back to TranslitSimple.
+func Transliterate(s string, scheme domain.TranslitScheme) string {
+	if s == "" || scheme == domain.TranslitNone {
 		return s
 	}
+	table, ok := translitTables[scheme]
+	if !ok {
+		table = translitTables[domain.TranslitSimple]
+	}
+
+	runes := []rune(s)
 	var b strings.Builder
 	b.Grow(len(s) * 2)
-	for _, r := range s {
-		if v, ok := ruTranslit[r]; ok {
-			b.WriteString(v)
+	for i, r := range runes {
+		lower := unicode.ToLower(r)
+		v, ok := table.letters[lower]
+		if !ok {
+			b.WriteRune(r)
 			continue
 		}
-		b.WriteRune(r)
+
+		if table.context != nil {
+			var prev, next rune
+			if i > 0 {
+				prev = unicode.ToLower(runes[i-1])
+			}
+			if i+1 < len(runes) {
+				next = unicode.ToLower(runes[i+1])
+			}
+			if c, ok := table.context(lower, prev, next); ok {
+				v = c
+			}
+		}
+
+		if r != lower && v != "" {
+			if table.upperWords && inUpperWord(runes, i) {
+				v = strings.ToUpper(v)
+			} else {
+				first := []rune(v)
+				first[0] = unicode.ToUpper(first[0])
+				v = string(first)
+			}
+		}
+		b.WriteString(v)
 	}
 	return b.String()
 }
+
+// TransliterateRuToEn applies the default TranslitSimple scheme.
+func TransliterateRuToEn(s string) string {
+	return Transliterate(s, domain.TranslitSimple)
+}
+
+// inUpperWord reports whether the capital at i has an upper-case letter
+// right next to it.
+func inUpperWord(runes []rune, i int) bool {
+	if i > 0 && unicode.IsUpper(runes[i-1]) {
+		return true
+	}
+	return i+1 < len(runes) && unicode.IsUpper(runes[i+1])
+}
@@ -1,6 +1,10 @@
 package service
 
-import "testing"
+import (
+	"testing"
+
+	"elibrary/internal/domain"
+)
 
 func TestTransliterateRuToEn(t *testing.T) {
 	t.Parallel()
@@ -43,3 +47,39 @@ func TestTransliterateRuToEn(t *testing.T) {
 		})
 	}
 }
+
+func TestTransliterateSchemes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scheme domain.TranslitScheme
+		in     string
+		want   string
+	}{
+		{scheme: domain.TranslitGOST, in: "Щука и цирк", want: "Shhuka i czirk"},
+		{scheme: domain.TranslitGOST, in: "Цапля, объём", want: "Caplya, ob``yom"},
+		{scheme: domain.TranslitGOST, in: "Ґанок", want: "G`anok"},
+		{scheme: domain.TranslitISO9, in: "Щука Жук", want: "Ŝuka Žuk"},
+		{scheme: domain.TranslitISO9, in: "ЩУКА", want: "ŜUKA"},
+		{scheme: domain.TranslitBGN, in: "Ёлка ест еду", want: "Yëlka yest yedu"},
+		{scheme: domain.TranslitBGN, in: "Белая, съезд", want: "Belaya, s\"yezd"},
+		{scheme: domain.TranslitBGN, in: "Қазақстан", want: "Qazaqstan"},
+		{scheme: domain.TranslitICAO, in: "Юлия Щербакова", want: "Iuliia Shcherbakova"},
+		{scheme: domain.TranslitICAO, in: "ЁЖИК", want: "EZHIK"},
+		{scheme: domain.TranslitICAO, in: "Қазақстан", want: "Kazakstan"},
+		{scheme: domain.TranslitSimple, in: "Київ", want: "Kiyiv"},
+		{scheme: domain.TranslitNone, in: "Привет", want: "Привет"},
+		{scheme: "", in: "Привет", want: "Privet"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(string(tt.scheme)+" "+tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			if got := Transliterate(tt.in, tt.scheme); got != tt.want {
+				t.Fatalf("Transliterate(%q, %q) = %q, want %q", tt.in, tt.scheme, got, tt.want)
+			}
+		})
+	}
+}
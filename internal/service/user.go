@@ -12,11 +12,17 @@ import (
 )
 
 type UserService struct {
-	userRepo repository.UserRepository
+	userRepo  repository.UserRepository
+	roleRepo  repository.RoleRepository
+	tokenRepo repository.RefreshTokenRepository
 }
 
-func NewUserService(userRepo repository.UserRepository) *UserService {
-	return &UserService{userRepo: userRepo}
+func NewUserService(
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	tokenRepo repository.RefreshTokenRepository,
+) *UserService {
+	return &UserService{userRepo: userRepo, roleRepo: roleRepo, tokenRepo: tokenRepo}
 }
 
 func (s *UserService) Create(ctx context.Context, user domain.User) (*domain.User, error) {
@@ -60,6 +66,9 @@ type UpdateUserRequest struct {
 	Roles      *[]domain.Role `json:"roles"`
 }
 
+// Update applies the changes to the user. Deactivating the user or changing
+// their password revokes all of their sessions. Like RoleService.RevokeFromUser,
+// it refuses to take a critical built-in role from its last active holder.
 func (s *UserService) Update(ctx context.Context, id uuid.UUID, updates UpdateUserRequest) error {
 	user, err := s.userRepo.GetByIDWithRoles(ctx, id)
 	if err != nil {
@@ -68,6 +77,9 @@ func (s *UserService) Update(ctx context.Context, id uuid.UUID, updates UpdateUs
 		}
 		return err
 	}
+	heldRoles, wasActive := user.Roles, user.IsActive
+
+	revokeSessions := false
 
 	if updates.Login != nil {
 		login := strings.TrimSpace(*updates.Login)
@@ -110,19 +122,57 @@ func (s *UserService) Update(ctx context.Context, id uuid.UUID, updates UpdateUs
 			return err
 		}
 		user.PasswordHash = string(hash)
+		revokeSessions = true
 	}
 	if updates.IsActive != nil {
+		if user.IsActive && !*updates.IsActive {
+			revokeSessions = true
+		}
 		user.IsActive = *updates.IsActive
 	}
 	if updates.Roles != nil {
 		user.Roles = *updates.Roles
 	}
 
-	return s.userRepo.Update(ctx, *user)
+	for _, role := range heldRoles {
+		if !wasActive || user.IsActive && hasRoleCode(user.Roles, role.Code) {
+			continue
+		}
+		if err := ensureOtherRoleHolder(ctx, s.roleRepo, role.Code, role.ID, user.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.userRepo.Update(ctx, *user); err != nil {
+		return err
+	}
+
+	if revokeSessions {
+		return s.tokenRepo.RevokeAllForUser(ctx, user.ID)
+	}
+	return nil
 }
 
+// Delete removes the user. Like Update, it refuses to remove the last
+// active holder of a critical built-in role.
 func (s *UserService) Delete(ctx context.Context, id uuid.UUID) error {
-	err := s.userRepo.Delete(ctx, id)
+	user, err := s.userRepo.GetByIDWithRoles(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return domain.ErrNotFound
+		}
+		return err
+	}
+
+	if user.IsActive {
+		for _, role := range user.Roles {
+			if err := ensureOtherRoleHolder(ctx, s.roleRepo, role.Code, role.ID, user.ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	err = s.userRepo.Delete(ctx, id)
 	if err != nil {
 		if errors.Is(err, repository.ErrNotFound) {
 			return domain.ErrNotFound
@@ -153,3 +203,12 @@ func (s *UserService) GetAllWithRoles(ctx context.Context) ([]*domain.User, erro
 	}
 	return users, nil
 }
+
+func hasRoleCode(roles []domain.Role, code string) bool {
+	for _, r := range roles {
+		if r.Code == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"elibrary/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+func TestUserServiceUpdateRevokesSessions(t *testing.T) {
+	t.Parallel()
+
+	password := "new-secret"
+	inactive := false
+	active := true
+	firstName := "Иван"
+
+	tests := []struct {
+		name       string
+		wasActive  bool
+		updates    UpdateUserRequest
+		wantRevoke bool
+	}{
+		{name: "password change", wasActive: true, updates: UpdateUserRequest{Password: &password}, wantRevoke: true},
+		{name: "deactivation", wasActive: true, updates: UpdateUserRequest{IsActive: &inactive}, wantRevoke: true},
+		{name: "activation", wasActive: false, updates: UpdateUserRequest{IsActive: &active}},
+		{name: "profile change", wasActive: true, updates: UpdateUserRequest{FirstName: &firstName}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			userID := uuid.New()
+			tokens := newStubRefreshTokenRepo()
+			svc := NewUserService(stubAuthUserRepo{
+				getByIDWithRoles: func(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+					return &domain.User{ID: id, FirstName: "Петр", IsActive: tt.wasActive}, nil
+				},
+			}, &stubRoleRepo{}, tokens)
+
+			if err := svc.Update(context.Background(), userID, tt.updates); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			if revoked := len(tokens.revokedAll) > 0; revoked != tt.wantRevoke {
+				t.Fatalf("sessions revoked = %v, want %v", revoked, tt.wantRevoke)
+			}
+		})
+	}
+}
+
+func TestUserServiceUpdateKeepsLastAdmin(t *testing.T) {
+	t.Parallel()
+
+	reader := []domain.Role{{ID: 2, Code: "reader"}}
+	inactive := false
+
+	tests := []struct {
+		name         string
+		updates      UpdateUserRequest
+		otherHolders int
+		want         error
+	}{
+		{name: "roles replaced", updates: UpdateUserRequest{Roles: &reader}, want: ErrLastRoleHolder},
+		{name: "deactivated", updates: UpdateUserRequest{IsActive: &inactive}, want: ErrLastRoleHolder},
+		{name: "another admin left", updates: UpdateUserRequest{Roles: &reader}, otherHolders: 1},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			updated := false
+			svc := NewUserService(stubAuthUserRepo{
+				getByIDWithRoles: func(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+					return &domain.User{ID: id, FirstName: "Петр", IsActive: true, Roles: []domain.Role{{ID: 1, Code: "admin"}}}, nil
+				},
+				update: func(ctx context.Context, user domain.User) error {
+					updated = true
+					return nil
+				},
+			}, &stubRoleRepo{otherHolders: tt.otherHolders}, newStubRefreshTokenRepo())
+
+			err := svc.Update(context.Background(), uuid.New(), tt.updates)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Update() error = %v, want %v", err, tt.want)
+			}
+			if updated != (tt.want == nil) {
+				t.Fatalf("user updated = %v, want %v", updated, tt.want == nil)
+			}
+		})
+	}
+}
+
+func TestUserServiceDeleteKeepsLastAdmin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		active       bool
+		otherHolders int
+		want         error
+	}{
+		{name: "last admin", active: true, want: ErrLastRoleHolder},
+		{name: "another admin left", active: true, otherHolders: 1},
+		{name: "inactive admin", active: false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			deleted := false
+			svc := NewUserService(stubAuthUserRepo{
+				getByIDWithRoles: func(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+					return &domain.User{ID: id, FirstName: "Петр", IsActive: tt.active, Roles: []domain.Role{{ID: 1, Code: "admin"}}}, nil
+				},
+				delete: func(ctx context.Context, id uuid.UUID) error {
+					deleted = true
+					return nil
+				},
+			}, &stubRoleRepo{otherHolders: tt.otherHolders}, newStubRefreshTokenRepo())
+
+			err := svc.Delete(context.Background(), uuid.New())
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Delete() error = %v, want %v", err, tt.want)
+			}
+			if deleted != (tt.want == nil) {
+				t.Fatalf("user deleted = %v, want %v", deleted, tt.want == nil)
+			}
+		})
+	}
+}
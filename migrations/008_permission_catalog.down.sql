@@ -0,0 +1,17 @@
+BEGIN;
+
+DELETE FROM permissions
+WHERE code IN (
+               'books.restore', 'books.purge', 'books.history',
+               'loans.view', 'loans.manage',
+               'works.create', 'works.update', 'works.delete',
+               'authors.create', 'authors.update', 'authors.delete',
+               'publishers.create', 'publishers.update', 'publishers.delete',
+               'locations.create', 'locations.update', 'locations.delete',
+               'images.upload',
+               'print.send',
+               'users.view', 'users.create', 'users.update', 'users.delete',
+               'roles.view', 'roles.manage'
+    );
+
+COMMIT;
@@ -0,0 +1,60 @@
+BEGIN;
+
+INSERT INTO permissions (code, name) VALUES
+                                         ('books.view',         'Просмотр детальной информации о книге'),
+                                         ('books.create',       'Добавление книг'),
+                                         ('books.update',       'Редактирование книг'),
+                                         ('books.delete',       'Удаление книг'),
+                                         ('books.restore',      'Восстановление книг из корзины'),
+                                         ('books.purge',        'Окончательное удаление книг'),
+                                         ('books.history',      'Просмотр истории изменений книг'),
+                                         ('loans.view',         'Просмотр выдач'),
+                                         ('loans.manage',       'Выдача и прием книг'),
+                                         ('works.create',       'Добавление произведений'),
+                                         ('works.update',       'Редактирование произведений'),
+                                         ('works.delete',       'Удаление произведений'),
+                                         ('authors.create',     'Добавление авторов'),
+                                         ('authors.update',     'Редактирование авторов'),
+                                         ('authors.delete',     'Удаление авторов'),
+                                         ('publishers.create',  'Добавление издательств'),
+                                         ('publishers.update',  'Редактирование издательств'),
+                                         ('publishers.delete',  'Удаление издательств'),
+                                         ('locations.create',   'Добавление локаций'),
+                                         ('locations.update',   'Редактирование локаций'),
+                                         ('locations.delete',   'Удаление локаций'),
+                                         ('images.upload',      'Загрузка изображений'),
+                                         ('print.send',         'Отправка заданий на печать'),
+                                         ('users.view',         'Просмотр пользователей'),
+                                         ('users.create',       'Добавление пользователей'),
+                                         ('users.update',       'Редактирование пользователей'),
+                                         ('users.delete',       'Удаление пользователей'),
+                                         ('roles.view',         'Просмотр ролей и прав'),
+                                         ('roles.manage',       'Управление ролями')
+    ON CONFLICT (code) DO UPDATE SET name = EXCLUDED.name;
+
+-- Администратор — все права
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         CROSS JOIN permissions p
+WHERE r.code = 'admin'
+    ON CONFLICT DO NOTHING;
+
+-- Библиотекарь — каталог, выдача и печать без окончательного удаления и администрирования
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.code IN (
+                                          'books.view', 'books.create', 'books.update', 'books.history',
+                                          'loans.view', 'loans.manage',
+                                          'works.create', 'works.update',
+                                          'authors.create', 'authors.update',
+                                          'publishers.create', 'publishers.update',
+                                          'locations.create', 'locations.update',
+                                          'images.upload',
+                                          'print.send'
+    )
+WHERE r.code = 'librarian'
+    ON CONFLICT DO NOTHING;
+
+COMMIT;
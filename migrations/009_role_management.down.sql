@@ -0,0 +1,9 @@
+BEGIN;
+
+DELETE FROM permissions
+WHERE code = 'roles.assign';
+
+ALTER TABLE roles
+    DROP COLUMN IF EXISTS is_system;
+
+COMMIT;
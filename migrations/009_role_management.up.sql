@@ -0,0 +1,22 @@
+BEGIN;
+
+ALTER TABLE roles
+    ADD COLUMN is_system BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Встроенные роли нельзя удалить через API
+UPDATE roles
+SET is_system = TRUE
+WHERE code IN ('admin', 'librarian', 'reader');
+
+INSERT INTO permissions (code, name) VALUES
+    ('roles.assign', 'Назначение и снятие ролей пользователей')
+    ON CONFLICT (code) DO UPDATE SET name = EXCLUDED.name;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.code = 'roles.assign'
+WHERE r.code = 'admin'
+    ON CONFLICT DO NOTHING;
+
+COMMIT;
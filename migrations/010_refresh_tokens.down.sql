@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS refresh_tokens;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS token_version;
+
+COMMIT;
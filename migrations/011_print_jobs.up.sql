@@ -0,0 +1,18 @@
+BEGIN;
+
+CREATE TABLE print_jobs (
+                            id           UUID PRIMARY KEY,
+                            requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+                            payload      JSONB       NOT NULL,
+                            status       TEXT        NOT NULL DEFAULT 'queued'
+                                CHECK (status IN ('queued', 'printing', 'done', 'failed')),
+                            error        TEXT,
+                            attempts     INT         NOT NULL DEFAULT 1,
+                            created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                            updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX print_jobs_status_idx ON print_jobs (status, created_at DESC);
+CREATE INDEX print_jobs_requested_by_idx ON print_jobs (requested_by, created_at DESC);
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+CREATE TABLE outbox (
+                        id             BIGSERIAL PRIMARY KEY,
+                        queue          TEXT        NOT NULL,
+                        correlation_id TEXT,
+                        reply_to       TEXT,
+                        payload        JSONB       NOT NULL,
+                        attempts       INT         NOT NULL DEFAULT 0,
+                        last_error     TEXT,
+                        available_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                        created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX outbox_available_at_idx ON outbox (available_at, id);
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+DELETE FROM permissions WHERE code = 'barcodes.manage';
+
+-- Only the active prefix of each type survives the rollback.
+DELETE FROM barcode_sequences WHERE NOT active;
+
+DROP INDEX IF EXISTS barcode_sequences_active_type_idx;
+ALTER TABLE barcode_sequences DROP COLUMN active;
+
+ALTER TABLE barcode_sequences DROP CONSTRAINT barcode_sequences_pkey;
+ALTER TABLE barcode_sequences ADD PRIMARY KEY (type);
+ALTER TABLE barcode_sequences ADD CONSTRAINT barcode_sequences_prefix_key UNIQUE (prefix);
+
+COMMIT;
@@ -0,0 +1,25 @@
+BEGIN;
+
+-- Several prefixes per type (e.g. one range per branch); exactly one of
+-- them is active and used for new barcodes.
+ALTER TABLE barcode_sequences DROP CONSTRAINT barcode_sequences_pkey;
+ALTER TABLE barcode_sequences DROP CONSTRAINT barcode_sequences_prefix_key;
+ALTER TABLE barcode_sequences ADD PRIMARY KEY (prefix);
+
+ALTER TABLE barcode_sequences ADD COLUMN active boolean NOT NULL DEFAULT false;
+UPDATE barcode_sequences SET active = true;
+
+CREATE UNIQUE INDEX barcode_sequences_active_type_idx ON barcode_sequences (type) WHERE active;
+
+INSERT INTO permissions (code, name) VALUES
+    ('barcodes.manage', 'Управление диапазонами штрих-кодов')
+    ON CONFLICT (code) DO UPDATE SET name = EXCLUDED.name;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r
+         JOIN permissions p ON p.code = 'barcodes.manage'
+WHERE r.code = 'admin'
+    ON CONFLICT DO NOTHING;
+
+COMMIT;
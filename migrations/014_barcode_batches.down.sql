@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS reserved_barcodes;
+DROP TABLE IF EXISTS barcode_batches;
+
+COMMIT;
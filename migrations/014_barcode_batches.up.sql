@@ -0,0 +1,25 @@
+BEGIN;
+
+-- Blocks of book barcodes taken from the sequence ahead of cataloging, so
+-- that labels can be printed before the books are entered.
+CREATE TABLE barcode_batches (
+                                 id         UUID PRIMARY KEY,
+                                 count      INT         NOT NULL CHECK (count > 0),
+                                 note       TEXT,
+                                 created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+                                 created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- A reserved code is bound once; bound_at stays set even if the book is
+-- purged later, so the printed label is never handed out twice.
+CREATE TABLE reserved_barcodes (
+                                   barcode  TEXT PRIMARY KEY,
+                                   batch_id UUID NOT NULL REFERENCES barcode_batches(id) ON DELETE CASCADE,
+                                   book_id  UUID REFERENCES books(id) ON DELETE SET NULL,
+                                   bound_at TIMESTAMPTZ
+);
+
+CREATE INDEX reserved_barcodes_batch_id_idx ON reserved_barcodes (batch_id);
+CREATE INDEX reserved_barcodes_book_id_idx ON reserved_barcodes (book_id) WHERE book_id IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,20 @@
+BEGIN;
+
+-- Codes that were taken off a book or a location when its label was
+-- reissued. They keep resolving to the same item and are never handed out
+-- again.
+CREATE TABLE barcode_history (
+                                 barcode     TEXT PRIMARY KEY,
+                                 book_id     UUID REFERENCES books(id) ON DELETE CASCADE,
+                                 location_id UUID REFERENCES locations(id) ON DELETE CASCADE,
+                                 replaced_by TEXT        NOT NULL,
+                                 reason      TEXT,
+                                 created_by  UUID REFERENCES users(id) ON DELETE SET NULL,
+                                 replaced_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                                 CHECK ((book_id IS NULL) <> (location_id IS NULL))
+);
+
+CREATE INDEX barcode_history_book_id_idx ON barcode_history (book_id, replaced_at DESC) WHERE book_id IS NOT NULL;
+CREATE INDEX barcode_history_location_id_idx ON barcode_history (location_id, replaced_at DESC) WHERE location_id IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP INDEX IF EXISTS books_issn_idx;
+DROP INDEX IF EXISTS books_isbn_idx;
+
+ALTER TABLE books
+    DROP COLUMN IF EXISTS issn,
+    DROP COLUMN IF EXISTS isbn;
+
+COMMIT;
@@ -0,0 +1,52 @@
+BEGIN;
+
+-- Normalized standard numbers of a book: the 13 digits of the ISBN-13 and
+-- the eight characters of the ISSN. They are not unique, since a library
+-- holds several copies of the same edition.
+ALTER TABLE books
+    ADD COLUMN isbn TEXT,
+    ADD COLUMN issn TEXT;
+
+CREATE INDEX books_isbn_idx ON books (isbn) WHERE isbn IS NOT NULL;
+CREATE INDEX books_issn_idx ON books (issn) WHERE issn IS NOT NULL;
+
+-- Backfill from factory barcodes that are an ISBN-13, an ISBN-10 or the
+-- EAN-13 of a serial, with hyphens and spaces dropped.
+CREATE FUNCTION pg_temp.ean_check(first12 TEXT) RETURNS TEXT
+    LANGUAGE SQL IMMUTABLE AS $$
+SELECT ((10 - SUM(substr(first12, i, 1)::int * CASE WHEN i % 2 = 0 THEN 3 ELSE 1 END) % 10) % 10)::text
+FROM generate_series(1, 12) AS i
+$$;
+
+CREATE FUNCTION pg_temp.mod11_check(digits TEXT) RETURNS TEXT
+    LANGUAGE SQL IMMUTABLE AS $$
+SELECT CASE c WHEN 10 THEN 'X' ELSE c::text END
+FROM (
+    SELECT (11 - SUM(substr(digits, i, 1)::int * (length(digits) + 2 - i)) % 11) % 11 AS c
+    FROM generate_series(1, length(digits)) AS i
+) s
+$$;
+
+WITH codes AS (
+    SELECT id, upper(replace(replace(factory_barcode, '-', ''), ' ', '')) AS code
+    FROM books
+    WHERE factory_barcode IS NOT NULL
+)
+UPDATE books b
+SET isbn = CASE
+               WHEN c.code ~ '^97[89][0-9]{10}$'
+                   AND right(c.code, 1) = pg_temp.ean_check(left(c.code, 12))
+                   THEN c.code
+               WHEN c.code ~ '^[0-9]{9}[0-9X]$'
+                   AND right(c.code, 1) = pg_temp.mod11_check(left(c.code, 9))
+                   THEN '978' || left(c.code, 9) || pg_temp.ean_check('978' || left(c.code, 9))
+           END,
+    issn = CASE
+               WHEN c.code ~ '^977[0-9]{10}$'
+                   AND right(c.code, 1) = pg_temp.ean_check(left(c.code, 12))
+                   THEN substr(c.code, 4, 7) || pg_temp.mod11_check(substr(c.code, 4, 7))
+           END
+FROM codes c
+WHERE c.id = b.id;
+
+COMMIT;
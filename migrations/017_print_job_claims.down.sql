@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS print_jobs_claimed_at_idx;
+ALTER TABLE print_jobs DROP COLUMN IF EXISTS claimed_at;
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE print_jobs ADD COLUMN claimed_at TIMESTAMPTZ;
+
+-- Jobs left in printing by a worker that stopped before this migration are
+-- treated as claimed at their last update.
+UPDATE print_jobs SET claimed_at = updated_at WHERE status = 'printing';
+
+CREATE INDEX print_jobs_claimed_at_idx ON print_jobs (claimed_at) WHERE status = 'printing';
+
+COMMIT;
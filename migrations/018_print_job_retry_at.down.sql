@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE print_jobs DROP COLUMN IF EXISTS retry_at;
+
+COMMIT;
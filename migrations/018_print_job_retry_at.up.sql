@@ -0,0 +1,7 @@
+BEGIN;
+
+-- A job put back after a transient failure is not claimed again before
+-- retry_at, so an offline printer does not hold up the jobs behind it.
+ALTER TABLE print_jobs ADD COLUMN retry_at TIMESTAMPTZ;
+
+COMMIT;